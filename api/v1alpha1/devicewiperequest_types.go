@@ -0,0 +1,106 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DeviceWipePhase is the progress of a DeviceWipeRequest
+type DeviceWipePhase string
+
+const (
+	// DeviceWipeApproved means the operator checked the LUN is unused, the node clears it next
+	DeviceWipeApproved DeviceWipePhase = "Approved"
+	// DeviceWipeRejected means the LUN cannot be wiped, the message tells why
+	DeviceWipeRejected DeviceWipePhase = "Rejected"
+	// DeviceWipeCompleted means the signatures of the LUN were cleared
+	DeviceWipeCompleted DeviceWipePhase = "Completed"
+	// DeviceWipeFailed means the node could not clear the signatures of the LUN
+	DeviceWipeFailed DeviceWipePhase = "Failed"
+)
+
+// DeviceWipeRequestSpec defines the LUN to wipe
+// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="spec is immutable"
+// +kubebuilder:validation:XValidation:rule="self.confirmWWN == self.WWN",message="confirmWWN must match the WWN"
+type DeviceWipeRequestSpec struct {
+	// WWN of the LUN to wipe, as reported in the LocalVolumeDiscoveryResults
+	// +kubebuilder:validation:MinLength=1
+	WWN string `json:"WWN"`
+	// NodeName is the node that clears the signatures of the LUN
+	// +kubebuilder:validation:MinLength=1
+	NodeName string `json:"nodeName"`
+	// ConfirmWWN must repeat the WWN, to confirm that the data on the LUN is to be destroyed
+	ConfirmWWN string `json:"confirmWWN"`
+}
+
+// DeviceWipeRequestStatus records the checks and the outcome of the wipe
+type DeviceWipeRequestStatus struct {
+	// Phase is the progress of the request
+	// +optional
+	// +kubebuilder:validation:Enum=Approved;Rejected;Completed;Failed
+	Phase DeviceWipePhase `json:"phase,omitempty"`
+	// Message explains the phase
+	// +optional
+	Message string `json:"message,omitempty"`
+	// DevicePath is the device of the LUN on the node, for eg. /dev/dm-3
+	// +optional
+	DevicePath string `json:"devicePath,omitempty"`
+	// PreviousSignature is what the header of the LUN held before the wipe, like xfs or gpfs
+	// +optional
+	PreviousSignature string `json:"previousSignature,omitempty"`
+	// WipedBytes is the number of bytes that were zeroed
+	// +optional
+	WipedBytes int64 `json:"wipedBytes,omitempty"`
+	// ApprovalTime is when the operator checked the LUN is unused
+	// +optional
+	ApprovalTime *metav1.Time `json:"approvalTime,omitempty"`
+	// CompletionTime is when the node completed or failed the wipe
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:path=devicewiperequests,scope=Cluster
+//+kubebuilder:printcolumn:name="WWN",type=string,JSONPath=`.spec.WWN`
+//+kubebuilder:printcolumn:name="Node",type=string,JSONPath=`.spec.nodeName`
+//+kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// DeviceWipeRequest asks for the signatures of an unused LUN to be cleared, so that it can be used
+// for a new LocalDisk
+type DeviceWipeRequest struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   DeviceWipeRequestSpec   `json:"spec,omitempty"`
+	Status DeviceWipeRequestStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// DeviceWipeRequestList contains a list of DeviceWipeRequest
+type DeviceWipeRequestList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []DeviceWipeRequest `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&DeviceWipeRequest{}, &DeviceWipeRequestList{})
+}
@@ -17,7 +17,13 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
 // NOTE(bandini): If you change anything in the following two lines you need to update
@@ -39,10 +45,165 @@ type FusionAccessSpec struct {
 	// +operator-sdk:csv:customresourcedefinitions:type=spec,order=4,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:hidden"}
 	// +kubebuilder:validation:Format=uri
 	ExternalManifestURL string `json:"externalManifestURL,omitempty"`
+	// KernelModule configures how KMM builds, signs and stores the IBM Storage Scale kernel module.
+	// It replaces the deprecated kmm-image-config ConfigMap, which is migrated here when this is not set
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,order=5,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:hidden"}
+	// +optional
+	KernelModule *KernelModuleConfig `json:"kernelModule,omitempty"`
 }
 type StorageDeviceDiscovery struct {
 	// +kubebuilder:default:=true
 	Create bool `json:"create,omitempty"`
+	// DeviceFilter selects the devices reported by the device discovery. It is copied to the
+	// LocalVolumeDiscovery, so the discovery can be tuned without rebuilding the device finder image
+	// +optional
+	DeviceFilter *DeviceFilterPolicy `json:"deviceFilter,omitempty"`
+}
+
+// KernelModuleConfig configures the kernel module images built by KMM
+type KernelModuleConfig struct {
+	// Registry is the host, with an optional port, of the registry the kernel module images are pushed to.
+	// Defaults to the OpenShift internal image registry
+	// +kubebuilder:validation:Pattern=`^[a-zA-Z0-9]([a-zA-Z0-9.-]*[a-zA-Z0-9])?(:[0-9]+)?$`
+	// +optional
+	Registry string `json:"registry,omitempty"`
+	// Repository is the repository of the kernel module images in the registry.
+	// Defaults to <namespace>/gpfs_compat_kmod
+	// +optional
+	Repository string `json:"repository,omitempty"`
+	// TLS configures the connections to the registry
+	// +optional
+	TLS KernelModuleRegistryTLS `json:"tls,omitempty"`
+	// RegistrySecret is the pull secret with the credentials to push to and pull from the registry.
+	// Defaults to the dockercfg secret of the builder service account
+	// +optional
+	RegistrySecret *corev1.LocalObjectReference `json:"registrySecret,omitempty"`
+	// Signing signs the kernel modules for Secure Boot with the given secrets, which then have to exist or
+	// be generated. When not set, the modules are only signed if the secureboot-signing-key and
+	// secureboot-signing-key-pub secrets exist
+	// +optional
+	Signing *KernelModuleSigning `json:"signing,omitempty"`
+	// BaseImages overrides the images the kernel module images are built from, like mirrored copies
+	// on disconnected clusters
+	// +optional
+	BaseImages *KernelModuleBaseImages `json:"baseImages,omitempty"`
+	// UpgradePreflight builds and pushes the kernel module images for the kernel of an OpenShift release
+	// before the cluster is upgraded to it, so that the nodes do not wait for a build when they reboot.
+	// The KernelModuleUpgradeReady condition reports when the images are ready
+	// +optional
+	UpgradePreflight *KernelModuleUpgradePreflight `json:"upgradePreflight,omitempty"`
+}
+
+// KernelModuleRegistryTLS configures the connections to the registry of the kernel module images
+type KernelModuleRegistryTLS struct {
+	// Insecure allows plain HTTP connections to the registry
+	// +optional
+	Insecure bool `json:"insecure,omitempty"`
+	// InsecureSkipVerify skips the verification of the certificate of the registry
+	// +optional
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
+}
+
+// KernelModuleSigning names the secrets the kernel modules are signed with
+type KernelModuleSigning struct {
+	// KeySecret is the secret holding the private signing key in its "key" entry
+	// +kubebuilder:default:=secureboot-signing-key
+	// +optional
+	KeySecret string `json:"keySecret,omitempty"`
+	// CertSecret is the secret holding the public certificate in its "cert" entry
+	// +kubebuilder:default:=secureboot-signing-key-pub
+	// +optional
+	CertSecret string `json:"certSecret,omitempty"`
+	// Generate creates a signing key and a self-signed certificate in KeySecret and CertSecret when neither
+	// exists. The certificate has to be enrolled in the MOK database of the Secure Boot nodes before
+	// the signed modules can be loaded there
+	// +optional
+	Generate bool `json:"generate,omitempty"`
+}
+
+// KernelModuleBaseImages are the images the kernel module images are built from
+type KernelModuleBaseImages struct {
+	// Builder is the image the kernel module is compiled in. Defaults to the Driver Toolkit image
+	// matching the kernel of the node
+	// +optional
+	Builder string `json:"builder,omitempty"`
+	// Runtime is the image the compiled kernel module is copied to. Defaults to registry.redhat.io/ubi9/ubi-minimal
+	// +optional
+	Runtime string `json:"runtime,omitempty"`
+}
+
+// KernelModuleUpgradePreflight is the target OpenShift release the kernel module images are built for ahead
+// of an upgrade
+type KernelModuleUpgradePreflight struct {
+	// KernelVersion is the kernel of the target release, as reported by its Driver Toolkit image,
+	// like 5.14.0-570.12.1.el9_6.x86_64
+	// +kubebuilder:validation:MinLength=1
+	KernelVersion string `json:"kernelVersion"`
+	// DTKImage is the Driver Toolkit image of the target release, given by
+	// oc adm release info --image-for=driver-toolkit <release image>
+	// +kubebuilder:validation:MinLength=1
+	DTKImage string `json:"dtkImage"`
+}
+
+var (
+	kernelModuleRegistryRegex   = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9.-]*[a-zA-Z0-9])?(:[0-9]+)?$`)
+	kernelModuleRepositoryRegex = regexp.MustCompile(`^[a-z0-9]+(([._]|__|-+)[a-z0-9]+)*(/[a-z0-9]+(([._]|__|-+)[a-z0-9]+)*)*$`)
+)
+
+// Validate returns an error when the kernel module configuration cannot be used to build images
+func (c *KernelModuleConfig) Validate() error {
+	if c == nil {
+		return nil
+	}
+	if c.Registry != "" && !kernelModuleRegistryRegex.MatchString(c.Registry) {
+		return fmt.Errorf("invalid kernelModule registry %q, it must be a host with an optional port, without scheme or path", c.Registry)
+	}
+	if c.Repository != "" && !kernelModuleRepositoryRegex.MatchString(c.Repository) {
+		return fmt.Errorf("invalid kernelModule repository %q", c.Repository)
+	}
+	if c.RegistrySecret != nil {
+		if err := validateSecretName("registrySecret", c.RegistrySecret.Name); err != nil {
+			return err
+		}
+	}
+	if c.Signing != nil {
+		if err := validateSecretName("signing keySecret", c.Signing.KeySecret); err != nil {
+			return err
+		}
+		if err := validateSecretName("signing certSecret", c.Signing.CertSecret); err != nil {
+			return err
+		}
+	}
+	if c.BaseImages != nil {
+		if strings.ContainsAny(c.BaseImages.Builder, " \t\n") {
+			return fmt.Errorf("invalid kernelModule builder base image %q", c.BaseImages.Builder)
+		}
+		if strings.ContainsAny(c.BaseImages.Runtime, " \t\n") {
+			return fmt.Errorf("invalid kernelModule runtime base image %q", c.BaseImages.Runtime)
+		}
+	}
+	if c.UpgradePreflight != nil {
+		kernel := c.UpgradePreflight.KernelVersion
+		if kernel == "" || strings.ContainsAny(kernel, " \t\n") {
+			return fmt.Errorf("invalid kernelModule upgradePreflight kernelVersion %q", kernel)
+		}
+		dtkImage := c.UpgradePreflight.DTKImage
+		if dtkImage == "" || strings.ContainsAny(dtkImage, " \t\n") {
+			return fmt.Errorf("invalid kernelModule upgradePreflight dtkImage %q", dtkImage)
+		}
+	}
+	return nil
+}
+
+// validateSecretName returns an error when a secret of the kernel module configuration has an invalid name
+func validateSecretName(field, name string) error {
+	if name == "" {
+		return fmt.Errorf("kernelModule %s needs a name", field)
+	}
+	if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+		return fmt.Errorf("invalid kernelModule %s name %q: %s", field, name, strings.Join(errs, ", "))
+	}
+	return nil
 }
 
 // FusionAccessStatus defines the observed state of FusionAccess
@@ -53,13 +214,83 @@ type FusionAccessStatus struct {
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
 	// TotalProvisionedDeviceCount is the count of the total devices over which the PVs has been provisioned
 	TotalProvisionedDeviceCount *int32 `json:"totalProvisionedDeviceCount,omitempty"`
+	// ProvisionedDevicesByNode is the count of provisioned devices per node the device was gathered from
+	// +optional
+	ProvisionedDevicesByNode []ProvisionedDeviceCount `json:"provisionedDevicesByNode,omitempty"`
+	// ProvisionedDevicesByFilesystem is the count of provisioned devices per filesystem using them.
+	// Devices that are not used by a filesystem yet are not counted here
+	// +optional
+	ProvisionedDevicesByFilesystem []ProvisionedDeviceCount `json:"provisionedDevicesByFilesystem,omitempty"`
 	// observedGeneration is the last generation change the operator has dealt with
 	// +optional
 	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
-	// Show the general status of the fusion access object (this can be shown nicely on ocp console UI)
+	// Show the general status of the fusion access object (this can be shown nicely on ocp console UI).
+	// It is derived from the conditions and is one of Progressing, Ready, Degraded or Deleting
 	Status string `json:"status,omitempty"`
+	// InstalledStorageScaleVersion is the IBM Storage Scale version whose manifest was last applied successfully
+	// +optional
+	InstalledStorageScaleVersion string `json:"installedStorageScaleVersion,omitempty"`
+	// KernelModuleBuilds is the state of the kernel module image of each kernel KMM loads the module on
+	// +optional
+	KernelModuleBuilds []KernelModuleBuild `json:"kernelModuleBuilds,omitempty"`
 }
 
+// KernelModuleBuildState is the state of the kernel module image of a kernel
+// +kubebuilder:validation:Enum=Ready;Pending;Building;Signing;BuildFailed;SignFailed;Missing
+type KernelModuleBuildState string
+
+const (
+	// KernelModuleBuildReady means the image exists in the registry
+	KernelModuleBuildReady KernelModuleBuildState = "Ready"
+	// KernelModuleBuildPending means KMM did not check the image yet
+	KernelModuleBuildPending KernelModuleBuildState = "Pending"
+	// KernelModuleBuildBuilding means the image is being built
+	KernelModuleBuildBuilding KernelModuleBuildState = "Building"
+	// KernelModuleBuildSigning means the kernel modules of the image are being signed
+	KernelModuleBuildSigning KernelModuleBuildState = "Signing"
+	// KernelModuleBuildBuildFailed means the build pod of the image failed
+	KernelModuleBuildBuildFailed KernelModuleBuildState = "BuildFailed"
+	// KernelModuleBuildSignFailed means the sign pod of the image failed
+	KernelModuleBuildSignFailed KernelModuleBuildState = "SignFailed"
+	// KernelModuleBuildMissing means the image does not exist and cannot be built
+	KernelModuleBuildMissing KernelModuleBuildState = "Missing"
+)
+
+// KernelModuleBuild is the state of the kernel module image of a kernel
+type KernelModuleBuild struct {
+	// KernelVersion is the kernel the image is built for
+	KernelVersion string `json:"kernelVersion"`
+	// Module is the KMM Module the image belongs to
+	Module string `json:"module"`
+	// Image is the kernel module image
+	Image string `json:"image"`
+	// State of the image
+	State KernelModuleBuildState `json:"state"`
+	// Message is the tail of the log of the failed build or sign pod
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// ProvisionedDeviceCount is the number of provisioned devices for a node or a filesystem
+type ProvisionedDeviceCount struct {
+	// Name of the node or the filesystem
+	Name string `json:"name"`
+	// Count of provisioned devices
+	Count int32 `json:"count"`
+}
+
+// Phases reported in FusionAccessStatus.Status
+const (
+	// PhaseProgressing means at least one component has not caught up with the current generation yet
+	PhaseProgressing = "Progressing"
+	// PhaseReady means every component is reconciled
+	PhaseReady = "Ready"
+	// PhaseDegraded means at least one component failed
+	PhaseDegraded = "Degraded"
+	// PhaseDeleting means the operator is tearing down the resources it created
+	PhaseDeleting = "Deleting"
+)
+
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
 
@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/Masterminds/semver/v3"
 	"github.com/openshift-storage-scale/openshift-fusion-access-operator/internal/utils"
 	configclient "github.com/openshift/client-go/config/clientset/versioned"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -32,6 +33,10 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 )
 
+// AllowUnsupportedVersionAnnotation lets a FusionAccess be admitted even if the IBM Storage Scale
+// version is not supported on the running OpenShift version
+const AllowUnsupportedVersionAnnotation = "fusion.storage.openshift.io/allow-unsupported-version"
+
 // log is for logging in this package.
 var fusionaccesslog = logf.Log.WithName("fusionaccess-resource")
 
@@ -85,29 +90,28 @@ func (r *FusionAccessValidator) ValidateCreate(ctx context.Context, obj runtime.
 	if len(fusionaccesses.Items) > 0 {
 		return nil, fmt.Errorf("only one FusionAccess resource is allowed")
 	}
-
-	clusterVersions, err := r.configClient.ConfigV1().ClusterVersions().Get(context.Background(), "version", metav1.GetOptions{})
-	if err != nil {
-		return nil, fmt.Errorf("failed to list ClusterVersions: %v", err)
+	if err := p.Spec.LocalVolumeDiscovery.DeviceFilter.Validate(); err != nil {
+		return nil, err
+	}
+	if err := p.Spec.KernelModule.Validate(); err != nil {
+		return nil, err
 	}
 
 	// Check if the IBM version we are running is an allowed one
-	ocpVersion, err := utils.GetCurrentClusterVersion(clusterVersions)
+	ocpVersion, err := r.getOpenShiftVersion(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get current cluster version: %v", err)
+		return nil, err
 	}
-	if !utils.IsOpenShiftSupported(string(p.Spec.StorageScaleVersion), *ocpVersion) {
-		// FIXME(bandini): we currently only log this so QE can test on upcoming versions that are not yet supported by IBM
-		fusionaccesslog.Info("IBM Storage Scale version not supported", "OCP Version", ocpVersion, "IBM Storage ScaleVersion", p.Spec.StorageScaleVersion)
-		// FIXME(bandini): return nil, fmt.Errorf("IBM Storage Scale version %s is not supported", ocpVersion)
-	} else {
-		fusionaccesslog.Info("validate create", "name", p.Name, "OCP Version", ocpVersion, "IBM Storage Scale Version", p.Spec.StorageScaleVersion)
+	warnings, err := validateOpenShiftSupport(p, ocpVersion)
+	if err != nil {
+		return nil, err
 	}
-	return nil, nil
+	fusionaccesslog.Info("validate create", "name", p.Name, "OCP Version", ocpVersion, "IBM Storage Scale Version", p.Spec.StorageScaleVersion)
+	return warnings, nil
 }
 
 // ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
-func (r *FusionAccessValidator) ValidateUpdate(_ context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+func (r *FusionAccessValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
 	p, err := convertToFusionAccess(oldObj)
 	if err != nil {
 		fusionaccesslog.Error(err, "validate update", "name", p.Name)
@@ -119,12 +123,6 @@ func (r *FusionAccessValidator) ValidateUpdate(_ context.Context, oldObj, newObj
 		return nil, err
 	}
 
-	// FIXME(bandini): IBM CNSA version cannot be updated for now
-	// FIXME(bandini): Maybe here we could introduce code to double check which upgrades paths we allow
-	// but for now we just log things
-	// if pNew.Spec.IbmCnsaVersion != p.Spec.IbmCnsaVersion {
-	// 	return nil, fmt.Errorf("IBM CNSA version cannot be updated")
-	// }
 	fusionaccesslog.Info(
 		"validate update",
 		"name",
@@ -135,7 +133,62 @@ func (r *FusionAccessValidator) ValidateUpdate(_ context.Context, oldObj, newObj
 		p.Spec.StorageScaleVersion,
 	)
 
-	return nil, nil
+	if err := pNew.Spec.LocalVolumeDiscovery.DeviceFilter.Validate(); err != nil {
+		return nil, err
+	}
+	if err := pNew.Spec.KernelModule.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Only check the support matrix when the version changes, otherwise an OpenShift upgrade
+	// would block any further update of the object (including the removal of our finalizer)
+	if pNew.Spec.StorageScaleVersion == p.Spec.StorageScaleVersion {
+		return nil, nil
+	}
+	// Only follow the upgrade graph between two known versions, external manifests have none
+	if p.Spec.StorageScaleVersion != "" && pNew.Spec.StorageScaleVersion != "" {
+		if err := utils.ValidateStorageScaleUpgrade(string(p.Spec.StorageScaleVersion), string(pNew.Spec.StorageScaleVersion)); err != nil {
+			return nil, err
+		}
+	}
+	ocpVersion, err := r.getOpenShiftVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return validateOpenShiftSupport(pNew, ocpVersion)
+}
+
+// getOpenShiftVersion returns the current version of the cluster
+func (r *FusionAccessValidator) getOpenShiftVersion(ctx context.Context) (*semver.Version, error) {
+	clusterVersions, err := r.configClient.ConfigV1().ClusterVersions().Get(ctx, "version", metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ClusterVersions: %v", err)
+	}
+	ocpVersion, err := utils.GetCurrentClusterVersion(clusterVersions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current cluster version: %v", err)
+	}
+	return ocpVersion, nil
+}
+
+// validateOpenShiftSupport returns an error when the requested IBM Storage Scale version is not supported
+// on the running OpenShift version. When the AllowUnsupportedVersionAnnotation is set to "true" the error
+// is turned into a warning, so QE can test on upcoming versions that are not yet supported by IBM
+func validateOpenShiftSupport(p *FusionAccess, ocpVersion *semver.Version) (admission.Warnings, error) {
+	// An external manifest has no support matrix to check against
+	if p.Spec.StorageScaleVersion == "" {
+		return nil, nil
+	}
+	if utils.IsOpenShiftSupported(string(p.Spec.StorageScaleVersion), *ocpVersion) {
+		return nil, nil
+	}
+	msg := fmt.Sprintf("IBM Storage Scale version %s is not supported on OpenShift %s", p.Spec.StorageScaleVersion, ocpVersion)
+	if p.GetAnnotations()[AllowUnsupportedVersionAnnotation] == "true" {
+		fusionaccesslog.Info("IBM Storage Scale version not supported, allowed via annotation",
+			"OCP Version", ocpVersion, "IBM Storage Scale Version", p.Spec.StorageScaleVersion)
+		return admission.Warnings{fmt.Sprintf("%s, allowed by the %s annotation", msg, AllowUnsupportedVersionAnnotation)}, nil
+	}
+	return nil, fmt.Errorf("%s. Set the %s annotation to \"true\" to override", msg, AllowUnsupportedVersionAnnotation)
 }
 
 // ValidateDelete implements webhook.Validator so a webhook will be registered for the type
@@ -17,7 +17,18 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"context"
+
 	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	configv1 "github.com/openshift/api/config/v1"
+	configfake "github.com/openshift/client-go/config/clientset/versioned/fake"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	apimachineryruntime "k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 var _ = Describe("FusionAccess Webhook", func() {
@@ -45,3 +56,194 @@ var _ = Describe("FusionAccess Webhook", func() {
 	})
 
 })
+
+var _ = Describe("FusionAccess Validating Webhook", func() {
+	var (
+		validator *FusionAccessValidator
+		testCtx   = context.Background()
+	)
+
+	newValidator := func(ocpVersion string, objs ...client.Object) *FusionAccessValidator {
+		scheme := apimachineryruntime.NewScheme()
+		Expect(AddToScheme(scheme)).To(Succeed())
+		clusterVersion := &configv1.ClusterVersion{
+			ObjectMeta: metav1.ObjectMeta{Name: "version"},
+			Status: configv1.ClusterVersionStatus{
+				History: []configv1.UpdateHistory{{State: configv1.CompletedUpdate, Version: ocpVersion}},
+			},
+		}
+		return &FusionAccessValidator{
+			Client:       fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build(),
+			configClient: configfake.NewSimpleClientset(clusterVersion),
+		}
+	}
+
+	newFusionAccess := func(version StorageScaleVersions, annotations map[string]string) *FusionAccess {
+		return &FusionAccess{
+			ObjectMeta: metav1.ObjectMeta{Name: "fusionaccess", Namespace: "default", Annotations: annotations},
+			Spec:       FusionAccessSpec{StorageScaleVersion: version},
+		}
+	}
+
+	Context("When creating a FusionAccess", func() {
+		It("admits a supported version without warnings", func() {
+			validator = newValidator("4.19.1")
+			warnings, err := validator.ValidateCreate(testCtx, newFusionAccess("v5.2.3.1", nil))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(warnings).To(BeEmpty())
+		})
+
+		It("denies an unsupported version", func() {
+			validator = newValidator("4.12.0")
+			_, err := validator.ValidateCreate(testCtx, newFusionAccess("v5.2.3.1", nil))
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("not supported on OpenShift 4.12.0"))
+			Expect(err.Error()).To(ContainSubstring(AllowUnsupportedVersionAnnotation))
+		})
+
+		It("turns an unsupported version into a warning with the annotation", func() {
+			validator = newValidator("4.12.0")
+			fa := newFusionAccess("v5.2.3.1", map[string]string{AllowUnsupportedVersionAnnotation: "true"})
+			warnings, err := validator.ValidateCreate(testCtx, fa)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(warnings).To(HaveLen(1))
+			Expect(warnings[0]).To(ContainSubstring("not supported on OpenShift 4.12.0"))
+		})
+
+		It("does not check the support matrix with an external manifest", func() {
+			validator = newValidator("4.12.0")
+			fa := newFusionAccess("", nil)
+			fa.Spec.ExternalManifestURL = "https://raw.githubusercontent.com/openshift-storage-scale/manifests/install.yaml"
+			warnings, err := validator.ValidateCreate(testCtx, fa)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(warnings).To(BeEmpty())
+		})
+
+		It("denies a second FusionAccess", func() {
+			validator = newValidator("4.19.1", newFusionAccess("v5.2.3.1", nil))
+			fa := newFusionAccess("v5.2.3.1", nil)
+			fa.Name = "second"
+			_, err := validator.ValidateCreate(testCtx, fa)
+			Expect(err).To(MatchError(ContainSubstring("only one FusionAccess resource is allowed")))
+		})
+
+		It("denies an invalid device filter regex", func() {
+			validator = newValidator("4.19.1")
+			fa := newFusionAccess("v5.2.3.1", nil)
+			fa.Spec.LocalVolumeDiscovery.DeviceFilter = &DeviceFilterPolicy{VendorRegex: "IBM("}
+			_, err := validator.ValidateCreate(testCtx, fa)
+			Expect(err).To(MatchError(ContainSubstring("invalid deviceFilter vendorRegex")))
+		})
+
+		It("denies a kernel module registry with a scheme", func() {
+			validator = newValidator("4.19.1")
+			fa := newFusionAccess("v5.2.3.1", nil)
+			fa.Spec.KernelModule = &KernelModuleConfig{Registry: "https://quay.io"}
+			_, err := validator.ValidateCreate(testCtx, fa)
+			Expect(err).To(MatchError(ContainSubstring("invalid kernelModule registry")))
+		})
+	})
+
+	Context("When updating a FusionAccess", func() {
+		It("does not check the support matrix if the version is unchanged", func() {
+			validator = newValidator("4.12.0")
+			oldFa := newFusionAccess("v5.2.3.1", nil)
+			newFa := oldFa.DeepCopy()
+			newFa.Finalizers = nil
+			warnings, err := validator.ValidateUpdate(testCtx, oldFa, newFa)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(warnings).To(BeEmpty())
+		})
+
+		It("admits an upgrade declared in the upgrade graph", func() {
+			validator = newValidator("4.19.1")
+			warnings, err := validator.ValidateUpdate(testCtx, newFusionAccess("v5.2.2.1", nil), newFusionAccess("v5.2.3.1", nil))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(warnings).To(BeEmpty())
+		})
+
+		It("denies a downgrade", func() {
+			validator = newValidator("4.19.1")
+			_, err := validator.ValidateUpdate(testCtx, newFusionAccess("v5.2.3.1", nil), newFusionAccess("v5.2.2.1", nil))
+			Expect(err).To(MatchError(ContainSubstring("downgrading")))
+		})
+
+		It("denies a jump that is not in the upgrade graph", func() {
+			validator = newValidator("4.19.1")
+			_, err := validator.ValidateUpdate(testCtx, newFusionAccess("v5.2.1.1", nil), newFusionAccess("v5.2.3.1", nil))
+			Expect(err).To(MatchError(ContainSubstring("can only be reached from")))
+		})
+
+		It("denies a device filter whose minimum size is larger than its maximum size", func() {
+			validator = newValidator("4.12.0")
+			oldFa := newFusionAccess("v5.2.3.1", nil)
+			newFa := oldFa.DeepCopy()
+			minSize, maxSize := resource.MustParse("2Ti"), resource.MustParse("1Ti")
+			newFa.Spec.LocalVolumeDiscovery.DeviceFilter = &DeviceFilterPolicy{MinSize: &minSize, MaxSize: &maxSize}
+			_, err := validator.ValidateUpdate(testCtx, oldFa, newFa)
+			Expect(err).To(MatchError(ContainSubstring("is larger than maxSize")))
+		})
+
+		It("denies an invalid device filter glob", func() {
+			validator = newValidator("4.12.0")
+			oldFa := newFusionAccess("v5.2.3.1", nil)
+			newFa := oldFa.DeepCopy()
+			newFa.Spec.LocalVolumeDiscovery.DeviceFilter = &DeviceFilterPolicy{ByIDGlobs: []string{"/dev/disk/by-id/wwn-["}}
+			_, err := validator.ValidateUpdate(testCtx, oldFa, newFa)
+			Expect(err).To(MatchError(ContainSubstring("invalid deviceFilter glob")))
+		})
+
+		It("denies kernel module signing without a key secret", func() {
+			validator = newValidator("4.12.0")
+			oldFa := newFusionAccess("v5.2.3.1", nil)
+			newFa := oldFa.DeepCopy()
+			newFa.Spec.KernelModule = &KernelModuleConfig{Signing: &KernelModuleSigning{CertSecret: "signing-cert"}}
+			_, err := validator.ValidateUpdate(testCtx, oldFa, newFa)
+			Expect(err).To(MatchError(ContainSubstring("kernelModule signing keySecret needs a name")))
+		})
+	})
+
+	DescribeTable("validates the kernel module configuration",
+		func(config *KernelModuleConfig, expected string) {
+			err := config.Validate()
+			if expected == "" {
+				Expect(err).ToNot(HaveOccurred())
+			} else {
+				Expect(err).To(MatchError(ContainSubstring(expected)))
+			}
+		},
+		Entry("not set", nil, ""),
+		Entry("complete configuration", &KernelModuleConfig{
+			Registry:       "registry.example.com:5000",
+			Repository:     "ibm-fusion-access/gpfs_compat_kmod",
+			TLS:            KernelModuleRegistryTLS{InsecureSkipVerify: true},
+			RegistrySecret: &corev1.LocalObjectReference{Name: "registry-secret"},
+			Signing:        &KernelModuleSigning{KeySecret: "signing-key", CertSecret: "signing-cert"},
+			BaseImages: &KernelModuleBaseImages{
+				Builder: "mirror.example.com/openshift/driver-toolkit@sha256:abc123",
+				Runtime: "mirror.example.com/ubi9/ubi-minimal:latest",
+			},
+			UpgradePreflight: &KernelModuleUpgradePreflight{
+				KernelVersion: "5.14.0-570.12.1.el9_6.x86_64",
+				DTKImage:      "quay.io/openshift-release-dev/ocp-v4.0-art-dev@sha256:def456",
+			},
+		}, ""),
+		Entry("registry with a path", &KernelModuleConfig{Registry: "quay.io/fusion"}, "invalid kernelModule registry"),
+		Entry("repository with upper case letters", &KernelModuleConfig{Repository: "Fusion/GPFS"}, "invalid kernelModule repository"),
+		Entry("repository with a tag", &KernelModuleConfig{Repository: "fusion/gpfs:latest"}, "invalid kernelModule repository"),
+		Entry("registry secret without a name", &KernelModuleConfig{RegistrySecret: &corev1.LocalObjectReference{}},
+			"kernelModule registrySecret needs a name"),
+		Entry("invalid certificate secret name", &KernelModuleConfig{
+			Signing: &KernelModuleSigning{KeySecret: "signing-key", CertSecret: "Signing_Cert"},
+		}, "invalid kernelModule signing certSecret name"),
+		Entry("builder image with a space", &KernelModuleConfig{
+			BaseImages: &KernelModuleBaseImages{Builder: "mirror.example.com/dtk latest"},
+		}, "invalid kernelModule builder base image"),
+		Entry("upgrade preflight without a kernel", &KernelModuleConfig{
+			UpgradePreflight: &KernelModuleUpgradePreflight{DTKImage: "quay.io/openshift-release-dev/ocp-v4.0-art-dev@sha256:def456"},
+		}, "invalid kernelModule upgradePreflight kernelVersion"),
+		Entry("upgrade preflight without a Driver Toolkit image", &KernelModuleConfig{
+			UpgradePreflight: &KernelModuleUpgradePreflight{KernelVersion: "5.14.0-570.12.1.el9_6.x86_64"},
+		}, "invalid kernelModule upgradePreflight dtkImage"),
+	)
+})
@@ -17,7 +17,12 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"fmt"
+	"path"
+	"regexp"
+
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -55,6 +60,71 @@ type LocalVolumeDiscoverySpec struct {
 	// LocalVolumeDiscovery Daemon
 	// +optional
 	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+	// DeviceFilter selects the devices reported by the discovery daemon. When unset only
+	// disks and multipath devices with a WWN are reported
+	// +optional
+	DeviceFilter *DeviceFilterPolicy `json:"deviceFilter,omitempty"`
+}
+
+// DeviceFilterPolicy narrows down the devices reported by the discovery daemon. Read only,
+// removable, mounted and partitioned devices, or devices holding a filesystem, are never reported.
+// All the set criteria must match for a device to be reported
+type DeviceFilterPolicy struct {
+	// MinSize is the minimum size of the reported devices
+	// +optional
+	MinSize *resource.Quantity `json:"minSize,omitempty"`
+	// MaxSize is the maximum size of the reported devices
+	// +optional
+	MaxSize *resource.Quantity `json:"maxSize,omitempty"`
+	// VendorRegex is a regular expression the vendor of the reported devices must match
+	// +optional
+	VendorRegex string `json:"vendorRegex,omitempty"`
+	// ModelRegex is a regular expression the model of the reported devices must match
+	// +optional
+	ModelRegex string `json:"modelRegex,omitempty"`
+	// DeviceTypes are the device types to report. Defaults to disk and mpath
+	// +kubebuilder:validation:items:Enum=disk;part;lvm;mpath
+	// +optional
+	DeviceTypes []DiscoveredDeviceType `json:"deviceTypes,omitempty"`
+	// ByPathGlobs are glob patterns, for eg. /dev/disk/by-path/pci-0000:3b:00.0-fc-*. When set, a
+	// device is only reported when one of its /dev/disk/by-path links matches one of them
+	// +optional
+	ByPathGlobs []string `json:"byPathGlobs,omitempty"`
+	// ByIDGlobs are glob patterns, for eg. /dev/disk/by-id/wwn-0x6005*. When set, a device is
+	// only reported when one of its /dev/disk/by-id links matches one of them
+	// +optional
+	ByIDGlobs []string `json:"byIDGlobs,omitempty"`
+	// AllowNoWWN reports the devices without a WWN as well
+	// +optional
+	AllowNoWWN bool `json:"allowNoWWN,omitempty"`
+	// ExcludeDevices are never reported. Each entry is a WWN, a device path or a /dev/disk link and
+	// can be a glob pattern
+	// +optional
+	ExcludeDevices []string `json:"excludeDevices,omitempty"`
+}
+
+// Validate checks that the regular expressions, globs and sizes of the policy are usable
+func (p *DeviceFilterPolicy) Validate() error {
+	if p == nil {
+		return nil
+	}
+	if p.MinSize != nil && p.MaxSize != nil && p.MinSize.Cmp(*p.MaxSize) > 0 {
+		return fmt.Errorf("deviceFilter minSize %s is larger than maxSize %s", p.MinSize, p.MaxSize)
+	}
+	if _, err := regexp.Compile(p.VendorRegex); err != nil {
+		return fmt.Errorf("invalid deviceFilter vendorRegex: %w", err)
+	}
+	if _, err := regexp.Compile(p.ModelRegex); err != nil {
+		return fmt.Errorf("invalid deviceFilter modelRegex: %w", err)
+	}
+	for _, globs := range [][]string{p.ByPathGlobs, p.ByIDGlobs, p.ExcludeDevices} {
+		for _, glob := range globs {
+			if _, err := path.Match(glob, ""); err != nil {
+				return fmt.Errorf("invalid deviceFilter glob %q: %w", glob, err)
+			}
+		}
+	}
+	return nil
 }
 
 // LocalVolumeDiscoveryStatus defines the observed state of LocalVolumeDiscovery
@@ -36,8 +36,195 @@ type DiscoveredDevice struct {
 	Size int64 `json:"size"`
 	// WWN defines the WWN value of the device.
 	WWN string `json:"WWN"`
+	// Serial number of the discovered device
+	// +optional
+	Serial string `json:"serial,omitempty"`
+	// Property represents whether the device is rotational or not
+	// +optional
+	Property DeviceMechanicalProperty `json:"property,omitempty"`
+	// Transport the device is attached with. For eg, fc, iscsi, sas or nvme
+	// +optional
+	Transport string `json:"transport,omitempty"`
+	// LogicalSectorSize of the device in bytes
+	// +optional
+	LogicalSectorSize int64 `json:"logicalSectorSize,omitempty"`
+	// PhysicalSectorSize of the device in bytes
+	// +optional
+	PhysicalSectorSize int64 `json:"physicalSectorSize,omitempty"`
+	// ByPath represents the /dev/disk/by-path name of the device. Multipath devices have one per path,
+	// reported in MultipathMembers
+	// +optional
+	ByPath string `json:"byPath,omitempty"`
+	// MultipathMembers are the paths of a multipath device
+	// +optional
+	MultipathMembers []MultipathMember `json:"multipathMembers,omitempty"`
+	// TotalPaths is the number of paths of a multipath device
+	// +optional
+	TotalPaths int32 `json:"totalPaths,omitempty"`
+	// ActivePaths is the number of paths of a multipath device whose SCSI device is running
+	// +optional
+	ActivePaths int32 `json:"activePaths,omitempty"`
+	// ExpectedPaths is the highest number of paths seen for a multipath device on this node. A LUN
+	// with fewer active paths is degraded
+	// +optional
+	ExpectedPaths int32 `json:"expectedPaths,omitempty"`
+	// NUMANode of the controller the device is attached to
+	// +optional
+	NUMANode *int32 `json:"numaNode,omitempty"`
+	// Header classifies what the first sectors of the device hold. Only Free devices can be used for
+	// new LocalDisks
+	// +kubebuilder:validation:Enum=Free;GPFSNSD;ForeignSignature;Unknown
+	// +optional
+	Header DeviceHeaderClass `json:"header,omitempty"`
+	// NSDName is the name of the Storage Scale NSD on a GPFSNSD device, when it can be decoded
+	// +optional
+	NSDName string `json:"nsdName,omitempty"`
+	// Signature found on a ForeignSignature device. For eg, xfs, LVM2_member or gpt
+	// +optional
+	Signature string `json:"signature,omitempty"`
+	// PersistentReservation tells whether the LUN supports SCSI-3 persistent reservations, which the
+	// tiebreaker disks of a Storage Scale cluster rely on
+	// +kubebuilder:validation:Enum=Supported;Unsupported;Unknown
+	// +optional
+	PersistentReservation PersistentReservationSupport `json:"persistentReservation,omitempty"`
+	// PersistentReservationTypes are the reservation types a Supported LUN reports. For eg,
+	// WriteExclusiveRegistrantsOnly
+	// +optional
+	PersistentReservationTypes []string `json:"persistentReservationTypes,omitempty"`
+}
+
+// Names returns the paths the device can be referenced with, like in the device of a LocalDisk
+func (d *DiscoveredDevice) Names() []string {
+	names := []string{d.Path}
+	if d.DeviceID != "" {
+		names = append(names, d.DeviceID)
+	}
+	if d.ByPath != "" {
+		names = append(names, d.ByPath)
+	}
+	for _, member := range d.MultipathMembers {
+		names = append(names, member.Path)
+		if member.ByPath != "" {
+			names = append(names, member.ByPath)
+		}
+	}
+	return names
+}
+
+// DeviceHeaderClass classifies the content of the header region of a device
+type DeviceHeaderClass string
+
+const (
+	// HeaderFree is a device without any known signature
+	HeaderFree DeviceHeaderClass = "Free"
+	// HeaderGPFSNSD is a device already holding a Storage Scale NSD
+	HeaderGPFSNSD DeviceHeaderClass = "GPFSNSD"
+	// HeaderForeignSignature is a device holding a filesystem, a partition table or a volume manager
+	// signature that lsblk did not report
+	HeaderForeignSignature DeviceHeaderClass = "ForeignSignature"
+	// HeaderUnknown is a device whose header could not be read
+	HeaderUnknown DeviceHeaderClass = "Unknown"
+)
+
+// PersistentReservationSupport tells whether a device supports SCSI-3 persistent reservations
+type PersistentReservationSupport string
+
+const (
+	// PersistentReservationSupported is a device that reported its persistent reservation capabilities
+	PersistentReservationSupported PersistentReservationSupport = "Supported"
+	// PersistentReservationUnsupported is a device that rejected the PERSISTENT RESERVE IN command
+	PersistentReservationUnsupported PersistentReservationSupport = "Unsupported"
+	// PersistentReservationUnknown is a device that could not be probed, like a device that is not SCSI
+	PersistentReservationUnknown PersistentReservationSupport = "Unknown"
+)
+
+// MultipathMember is one of the paths of a multipath device
+type MultipathMember struct {
+	// Name is the kernel name of the path. For eg, sdb
+	Name string `json:"name"`
+	// Path represents the device path. For eg, /dev/sdb
+	Path string `json:"path"`
+	// ByPath represents the /dev/disk/by-path name of the path
+	// +optional
+	ByPath string `json:"byPath,omitempty"`
+	// State of the SCSI device of the path. For eg, running, offline or blocked
+	// +optional
+	State string `json:"state,omitempty"`
+	// Transport the path is attached with
+	// +optional
+	Transport string `json:"transport,omitempty"`
+	// NUMANode of the controller of the path
+	// +optional
+	NUMANode *int32 `json:"numaNode,omitempty"`
 }
 
+// RejectionReason tells why a block device is not part of the discovered devices
+type RejectionReason string
+
+const (
+	// RejectedReadOnly is a read only device
+	RejectedReadOnly RejectionReason = "ReadOnly"
+	// RejectedRemovable is a removable device, like a CD-ROM or a USB stick
+	RejectedRemovable RejectionReason = "Removable"
+	// RejectedHasFilesystem is a device, or the child of a device, holding a filesystem
+	RejectedHasFilesystem RejectionReason = "HasFilesystem"
+	// RejectedHasPartitions is a partitioned device
+	RejectedHasPartitions RejectionReason = "HasPartitions"
+	// RejectedNoWWN is a device without a WWN, which cannot be identified across nodes
+	RejectedNoWWN RejectionReason = "NoWWN"
+	// RejectedBootDevice is a device with a BIOS boot or boot partition
+	RejectedBootDevice RejectionReason = "BootDevice"
+	// RejectedMounted is a mounted device
+	RejectedMounted RejectionReason = "Mounted"
+	// RejectedUnsupportedType is a device whose type is not discovered, like a loop device
+	RejectedUnsupportedType RejectionReason = "UnsupportedType"
+	// RejectedSuspended is a suspended device mapper device
+	RejectedSuspended RejectionReason = "Suspended"
+	// RejectedZeroSize is a device without any capacity
+	RejectedZeroSize RejectionReason = "ZeroSize"
+	// RejectedByDeviceFilter is a device excluded by the DeviceFilterPolicy of the LocalVolumeDiscovery
+	RejectedByDeviceFilter RejectionReason = "ExcludedByDeviceFilter"
+)
+
+// RejectedDevice is a block device found on the node that is not usable for creating LocalDisks
+type RejectedDevice struct {
+	// Path represents the device path. For eg, /dev/sdb
+	Path string `json:"path"`
+	// DeviceID represents the persistent name of the device. For eg, /dev/disk/by-id/...
+	// +optional
+	DeviceID string `json:"deviceID,omitempty"`
+	// WWN defines the WWN value of the device.
+	// +optional
+	WWN string `json:"WWN,omitempty"`
+	// Model of the device
+	// +optional
+	Model string `json:"model,omitempty"`
+	// Vendor of the device
+	// +optional
+	Vendor string `json:"vendor,omitempty"`
+	// Size of the device
+	// +optional
+	Size int64 `json:"size,omitempty"`
+	// Reason is a machine readable reason for rejecting the device
+	// +kubebuilder:validation:Enum=ReadOnly;Removable;HasFilesystem;HasPartitions;NoWWN;BootDevice;Mounted;UnsupportedType;Suspended;ZeroSize;ExcludedByDeviceFilter
+	Reason RejectionReason `json:"reason"`
+	// Message is a human readable explanation of the reason
+	Message string `json:"message"`
+}
+
+const (
+	// MultipathDegradedCondition is True when a LUN used by Storage Scale has fewer active paths than expected
+	MultipathDegradedCondition = "MultipathDegraded"
+	// MultipathPathsLostReason is the reason of the MultipathDegraded condition when paths are missing
+	MultipathPathsLostReason = "PathsLost"
+	// MultipathAllPathsActiveReason is the reason of the MultipathDegraded condition when no paths are missing
+	MultipathAllPathsActiveReason = "AllPathsActive"
+)
+
+// ResetExpectedPathsAnnotation asks the device finder of the node to take the current number of paths of
+// its multipath LUNs as their expected number of paths. It is removed once applied
+const ResetExpectedPathsAnnotation = "fusion.storage.openshift.io/reset-expected-paths"
+
 // LocalVolumeDiscoveryResultSpec defines the desired state of LocalVolumeDiscoveryResult
 type LocalVolumeDiscoveryResultSpec struct {
 	// Node on which the devices are discovered
@@ -59,6 +246,50 @@ type LocalVolumeDiscoveryResultStatus struct {
 	// - it should have a WWN value
 	// +optional
 	DiscoveredDevices []DiscoveredDevice `json:"discoveredDevices"`
+	// RejectedDevices contains the block devices that do not qualify the conditions above, along with
+	// the reason they were rejected
+	// +optional
+	RejectedDevices []RejectedDevice `json:"rejectedDevices,omitempty"`
+	// Initiators are the SAN initiators of the node, to zone the storage to it
+	// +optional
+	Initiators *NodeInitiators `json:"initiators,omitempty"`
+	// Conditions of the node's devices, like MultipathDegraded
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// NodeInitiators are the Fibre Channel ports and the iSCSI initiator of a node
+type NodeInitiators struct {
+	// FCHosts are the Fibre Channel HBA ports of the node
+	// +optional
+	FCHosts []FCHost `json:"fcHosts,omitempty"`
+	// ISCSIInitiatorName is the IQN of the iSCSI initiator of the node
+	// +optional
+	ISCSIInitiatorName string `json:"iscsiInitiatorName,omitempty"`
+}
+
+// FCHost is a Fibre Channel HBA port of a node. The names are colon separated bytes, for eg.
+// 10:00:00:90:fa:8c:1f:52
+type FCHost struct {
+	// Name of the SCSI host of the port. For eg, host1
+	Name string `json:"name"`
+	// PortName is the WWPN of the port, which the fabric is zoned with
+	// +optional
+	PortName string `json:"portName,omitempty"`
+	// NodeName is the WWNN of the HBA
+	// +optional
+	NodeName string `json:"nodeName,omitempty"`
+	// PortState of the port. For eg, Online or Linkdown
+	// +optional
+	PortState string `json:"portState,omitempty"`
+	// Speed of the link. For eg, 16 Gbit
+	// +optional
+	Speed string `json:"speed,omitempty"`
+	// FabricName is the name of the fabric the port is logged in, empty when it is not
+	// +optional
+	FabricName string `json:"fabricName,omitempty"`
 }
 
 //+kubebuilder:object:root=true
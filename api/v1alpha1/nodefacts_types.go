@@ -0,0 +1,120 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// StorageScaleKernelModule is the kernel module of Storage Scale
+const StorageScaleKernelModule = "mmfs26"
+
+// SecureBootState is the Secure Boot state of a node, as found in its EFI variables
+type SecureBootState string
+
+const (
+	// SecureBootEnabled means the firmware only boots signed kernels, which only load signed modules
+	SecureBootEnabled SecureBootState = "Enabled"
+	// SecureBootDisabled means the node boots with EFI, without Secure Boot
+	SecureBootDisabled SecureBootState = "Disabled"
+	// SecureBootUnsupported means the node does not boot with EFI, like s390x and ppc64le nodes
+	SecureBootUnsupported SecureBootState = "Unsupported"
+	// SecureBootUnknown means the EFI variable of Secure Boot could not be read
+	SecureBootUnknown SecureBootState = "Unknown"
+)
+
+// HugePages is the configuration of the hugepages of a size
+type HugePages struct {
+	// Size of the pages. For eg, 2Mi or 1Gi
+	Size string `json:"size"`
+	// Total number of pages of the size
+	Total int64 `json:"total"`
+	// Free number of pages of the size
+	Free int64 `json:"free"`
+}
+
+// NodeFactsSpec defines the node the facts are collected on
+type NodeFactsSpec struct {
+	// NodeName is the node the facts are collected on
+	NodeName string `json:"nodeName"`
+}
+
+// NodeFactsStatus are the facts of the host that matter to Storage Scale
+type NodeFactsStatus struct {
+	// KernelVersion is the release of the running kernel. For eg, 5.14.0-427.50.1.el9_4.x86_64
+	// +optional
+	KernelVersion string `json:"kernelVersion,omitempty"`
+	// Architecture of the node, as named by Kubernetes. For eg, amd64
+	// +optional
+	Architecture string `json:"architecture,omitempty"`
+	// SecureBoot is the Secure Boot state of the node
+	// +kubebuilder:validation:Enum=Enabled;Disabled;Unsupported;Unknown
+	// +optional
+	SecureBoot SecureBootState `json:"secureBoot,omitempty"`
+	// MultipathdActive is true when the multipathd daemon runs on the node
+	// +optional
+	MultipathdActive bool `json:"multipathdActive,omitempty"`
+	// HugePages are the configured hugepages, by size
+	// +optional
+	HugePages []HugePages `json:"hugePages,omitempty"`
+	// MemoryTotal is the usable memory of the node, in bytes
+	// +optional
+	MemoryTotal int64 `json:"memoryTotal,omitempty"`
+	// CPUCount is the number of online CPUs of the node
+	// +optional
+	CPUCount int32 `json:"cpuCount,omitempty"`
+	// LoadedModules are the names of the loaded kernel modules, sorted
+	// +optional
+	LoadedModules []string `json:"loadedModules,omitempty"`
+	// StorageScaleModuleLoaded is true when the mmfs26 kernel module of Storage Scale is loaded
+	// +optional
+	StorageScaleModuleLoaded bool `json:"storageScaleModuleLoaded,omitempty"`
+	// CollectedTimeStamp is the last time the facts changed
+	// +optional
+	CollectedTimeStamp string `json:"collectedTimeStamp,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:path=nodefacts,scope=Namespaced
+//+kubebuilder:printcolumn:name="Node",type=string,JSONPath=`.spec.nodeName`
+//+kubebuilder:printcolumn:name="Kernel",type=string,JSONPath=`.status.kernelVersion`
+//+kubebuilder:printcolumn:name="Arch",type=string,JSONPath=`.status.architecture`
+//+kubebuilder:printcolumn:name="SecureBoot",type=string,JSONPath=`.status.secureBoot`
+//+kubebuilder:printcolumn:name="Module",type=boolean,JSONPath=`.status.storageScaleModuleLoaded`
+
+// NodeFacts are the facts of a node that Storage Scale depends on, collected by the devicefinder daemon
+type NodeFacts struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   NodeFactsSpec   `json:"spec,omitempty"`
+	Status NodeFactsStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// NodeFactsList contains a list of NodeFacts
+type NodeFactsList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NodeFacts `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&NodeFacts{}, &NodeFactsList{})
+}
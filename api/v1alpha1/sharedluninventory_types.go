@@ -0,0 +1,173 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SharedLUNInventoryName is the name of the single SharedLUNInventory object maintained by the operator
+const SharedLUNInventoryName = "cluster"
+
+// LUNSharing tells from how many of the storage nodes a LUN is visible
+type LUNSharing string
+
+const (
+	// LUNSharedByAll means the LUN is visible from every storage node
+	LUNSharedByAll LUNSharing = "SharedByAll"
+	// LUNPartiallyShared means the LUN is visible from more than one storage node, but not from all of them
+	LUNPartiallyShared LUNSharing = "PartiallyShared"
+	// LUNNodeLocal means the LUN is only visible from a single storage node
+	LUNNodeLocal LUNSharing = "NodeLocal"
+)
+
+// LUNUsage tells whether a LUN is already used by Storage Scale
+type LUNUsage string
+
+const (
+	// LUNAvailable means no LocalDisk uses the LUN
+	LUNAvailable LUNUsage = "Available"
+	// LUNClaimedByLocalDisk means a LocalDisk uses the LUN, but it is not part of a filesystem yet
+	LUNClaimedByLocalDisk LUNUsage = "ClaimedByLocalDisk"
+	// LUNInFilesystem means the LocalDisk using the LUN is part of a filesystem
+	LUNInFilesystem LUNUsage = "InFilesystem"
+)
+
+// LUNNodeDevice is the device through which a node sees a LUN
+type LUNNodeDevice struct {
+	// NodeName is the node on which the device was discovered
+	NodeName string `json:"nodeName"`
+	// DeviceID represents the persistent name of the device. For eg, /dev/disk/by-id/...
+	DeviceID string `json:"deviceID"`
+	// Path represents the device path on that node. For eg, /dev/sdb
+	Path string `json:"path"`
+	// Size of the device as seen from that node
+	Size int64 `json:"size"`
+	// Vendor of the device as seen from that node
+	Vendor string `json:"vendor"`
+	// Model of the device as seen from that node
+	Model string `json:"model"`
+}
+
+// SharedLUN groups the devices with the same WWN discovered on the storage nodes
+type SharedLUN struct {
+	// WWN of the LUN
+	WWN string `json:"WWN"`
+	// Sharing tells from how many of the storage nodes the LUN is visible
+	// +kubebuilder:validation:Enum=SharedByAll;PartiallyShared;NodeLocal
+	Sharing LUNSharing `json:"sharing"`
+	// Size of the LUN, as reported by the first node in Nodes
+	Size int64 `json:"size"`
+	// Vendor of the LUN, as reported by the first node in Nodes
+	Vendor string `json:"vendor"`
+	// Model of the LUN, as reported by the first node in Nodes
+	Model string `json:"model"`
+	// SizeMismatch is true when the nodes do not report the same size for this WWN
+	// +optional
+	SizeMismatch bool `json:"sizeMismatch,omitempty"`
+	// VendorMismatch is true when the nodes do not report the same vendor for this WWN
+	// +optional
+	VendorMismatch bool `json:"vendorMismatch,omitempty"`
+	// Nodes lists the device of every node that sees this LUN
+	Nodes []LUNNodeDevice `json:"nodes"`
+	// Usage tells whether the LUN is available for a new LocalDisk
+	// +kubebuilder:validation:Enum=Available;ClaimedByLocalDisk;InFilesystem
+	Usage LUNUsage `json:"usage"`
+	// LocalDisk is the name of the LocalDisk using the LUN
+	// +optional
+	LocalDisk string `json:"localDisk,omitempty"`
+	// Filesystem is the name of the filesystem the LocalDisk of the LUN belongs to
+	// +optional
+	Filesystem string `json:"filesystem,omitempty"`
+}
+
+// NodeLocalDevice is a discovered device without a WWN. It cannot be shared and can only be used by a
+// LocalDisk of its own node
+type NodeLocalDevice struct {
+	// NodeName is the node on which the device was discovered
+	NodeName string `json:"nodeName"`
+	// DeviceID represents the persistent name of the device. For eg, /dev/disk/by-id/...
+	DeviceID string `json:"deviceID"`
+	// Path represents the device path on that node. For eg, /dev/sdb
+	Path string `json:"path"`
+	// Size of the device
+	Size int64 `json:"size"`
+	// Usage tells whether the device is available for a new LocalDisk
+	// +kubebuilder:validation:Enum=Available;ClaimedByLocalDisk;InFilesystem
+	Usage LUNUsage `json:"usage"`
+	// LocalDisk is the name of the LocalDisk using the device
+	// +optional
+	LocalDisk string `json:"localDisk,omitempty"`
+	// Filesystem is the name of the filesystem the LocalDisk of the device belongs to
+	// +optional
+	Filesystem string `json:"filesystem,omitempty"`
+}
+
+// SharedLUNInventorySpec defines the desired state of SharedLUNInventory
+type SharedLUNInventorySpec struct {
+}
+
+// SharedLUNInventoryStatus defines the observed state of SharedLUNInventory
+type SharedLUNInventoryStatus struct {
+	// StorageNodes are the nodes that reported a LocalVolumeDiscoveryResult
+	// +optional
+	StorageNodes []string `json:"storageNodes,omitempty"`
+	// LUNs contains the discovered LUNs sorted by WWN
+	// +optional
+	LUNs []SharedLUN `json:"luns,omitempty"`
+	// NodeLocalDevices contains the discovered devices without a WWN sorted by node and path
+	// +optional
+	NodeLocalDevices []NodeLocalDevice `json:"nodeLocalDevices,omitempty"`
+	// MismatchedLUNCount is the number of LUNs whose size or vendor differ between nodes
+	// +optional
+	MismatchedLUNCount int32 `json:"mismatchedLUNCount,omitempty"`
+	// AvailableLUNCount is the number of LUNs no LocalDisk uses
+	// +optional
+	AvailableLUNCount int32 `json:"availableLUNCount,omitempty"`
+	// LastUpdated is the last time the inventory was rebuilt from the discovery results
+	// +optional
+	LastUpdated *metav1.Time `json:"lastUpdated,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:path=sharedluninventories,scope=Cluster
+//+kubebuilder:printcolumn:name="Available",type=integer,JSONPath=`.status.availableLUNCount`
+//+kubebuilder:printcolumn:name="Mismatched",type=integer,JSONPath=`.status.mismatchedLUNCount`
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// SharedLUNInventory is the cluster wide view of the LUNs discovered on the storage nodes, grouped by WWN
+type SharedLUNInventory struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   SharedLUNInventorySpec   `json:"spec,omitempty"`
+	Status SharedLUNInventoryStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// SharedLUNInventoryList contains a list of SharedLUNInventory
+type SharedLUNInventoryList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SharedLUNInventory `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&SharedLUNInventory{}, &SharedLUNInventoryList{})
+}
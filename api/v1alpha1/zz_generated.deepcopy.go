@@ -21,14 +21,173 @@ limitations under the License.
 package v1alpha1
 
 import (
-	corev1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeviceFilterPolicy) DeepCopyInto(out *DeviceFilterPolicy) {
+	*out = *in
+	if in.MinSize != nil {
+		in, out := &in.MinSize, &out.MinSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.MaxSize != nil {
+		in, out := &in.MaxSize, &out.MaxSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.DeviceTypes != nil {
+		in, out := &in.DeviceTypes, &out.DeviceTypes
+		*out = make([]DiscoveredDeviceType, len(*in))
+		copy(*out, *in)
+	}
+	if in.ByPathGlobs != nil {
+		in, out := &in.ByPathGlobs, &out.ByPathGlobs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ByIDGlobs != nil {
+		in, out := &in.ByIDGlobs, &out.ByIDGlobs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExcludeDevices != nil {
+		in, out := &in.ExcludeDevices, &out.ExcludeDevices
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeviceFilterPolicy.
+func (in *DeviceFilterPolicy) DeepCopy() *DeviceFilterPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(DeviceFilterPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeviceWipeRequest) DeepCopyInto(out *DeviceWipeRequest) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeviceWipeRequest.
+func (in *DeviceWipeRequest) DeepCopy() *DeviceWipeRequest {
+	if in == nil {
+		return nil
+	}
+	out := new(DeviceWipeRequest)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DeviceWipeRequest) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeviceWipeRequestList) DeepCopyInto(out *DeviceWipeRequestList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]DeviceWipeRequest, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeviceWipeRequestList.
+func (in *DeviceWipeRequestList) DeepCopy() *DeviceWipeRequestList {
+	if in == nil {
+		return nil
+	}
+	out := new(DeviceWipeRequestList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DeviceWipeRequestList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeviceWipeRequestSpec) DeepCopyInto(out *DeviceWipeRequestSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeviceWipeRequestSpec.
+func (in *DeviceWipeRequestSpec) DeepCopy() *DeviceWipeRequestSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DeviceWipeRequestSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeviceWipeRequestStatus) DeepCopyInto(out *DeviceWipeRequestStatus) {
+	*out = *in
+	if in.ApprovalTime != nil {
+		in, out := &in.ApprovalTime, &out.ApprovalTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeviceWipeRequestStatus.
+func (in *DeviceWipeRequestStatus) DeepCopy() *DeviceWipeRequestStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DeviceWipeRequestStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DiscoveredDevice) DeepCopyInto(out *DiscoveredDevice) {
 	*out = *in
+	if in.MultipathMembers != nil {
+		in, out := &in.MultipathMembers, &out.MultipathMembers
+		*out = make([]MultipathMember, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.NUMANode != nil {
+		in, out := &in.NUMANode, &out.NUMANode
+		*out = new(int32)
+		**out = **in
+	}
+	if in.PersistentReservationTypes != nil {
+		in, out := &in.PersistentReservationTypes, &out.PersistentReservationTypes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiscoveredDevice.
@@ -41,12 +200,27 @@ func (in *DiscoveredDevice) DeepCopy() *DiscoveredDevice {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FCHost) DeepCopyInto(out *FCHost) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FCHost.
+func (in *FCHost) DeepCopy() *FCHost {
+	if in == nil {
+		return nil
+	}
+	out := new(FCHost)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *FusionAccess) DeepCopyInto(out *FusionAccess) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	in.Status.DeepCopyInto(&out.Status)
 }
 
@@ -103,7 +277,12 @@ func (in *FusionAccessList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *FusionAccessSpec) DeepCopyInto(out *FusionAccessSpec) {
 	*out = *in
-	out.LocalVolumeDiscovery = in.LocalVolumeDiscovery
+	in.LocalVolumeDiscovery.DeepCopyInto(&out.LocalVolumeDiscovery)
+	if in.KernelModule != nil {
+		in, out := &in.KernelModule, &out.KernelModule
+		*out = new(KernelModuleConfig)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FusionAccessSpec.
@@ -121,7 +300,7 @@ func (in *FusionAccessStatus) DeepCopyInto(out *FusionAccessStatus) {
 	*out = *in
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
-		*out = make([]v1.Condition, len(*in))
+		*out = make([]metav1.Condition, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -131,6 +310,21 @@ func (in *FusionAccessStatus) DeepCopyInto(out *FusionAccessStatus) {
 		*out = new(int32)
 		**out = **in
 	}
+	if in.ProvisionedDevicesByNode != nil {
+		in, out := &in.ProvisionedDevicesByNode, &out.ProvisionedDevicesByNode
+		*out = make([]ProvisionedDeviceCount, len(*in))
+		copy(*out, *in)
+	}
+	if in.ProvisionedDevicesByFilesystem != nil {
+		in, out := &in.ProvisionedDevicesByFilesystem, &out.ProvisionedDevicesByFilesystem
+		*out = make([]ProvisionedDeviceCount, len(*in))
+		copy(*out, *in)
+	}
+	if in.KernelModuleBuilds != nil {
+		in, out := &in.KernelModuleBuilds, &out.KernelModuleBuilds
+		*out = make([]KernelModuleBuild, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FusionAccessStatus.
@@ -143,6 +337,147 @@ func (in *FusionAccessStatus) DeepCopy() *FusionAccessStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HugePages) DeepCopyInto(out *HugePages) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HugePages.
+func (in *HugePages) DeepCopy() *HugePages {
+	if in == nil {
+		return nil
+	}
+	out := new(HugePages)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KernelModuleBaseImages) DeepCopyInto(out *KernelModuleBaseImages) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KernelModuleBaseImages.
+func (in *KernelModuleBaseImages) DeepCopy() *KernelModuleBaseImages {
+	if in == nil {
+		return nil
+	}
+	out := new(KernelModuleBaseImages)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KernelModuleBuild) DeepCopyInto(out *KernelModuleBuild) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KernelModuleBuild.
+func (in *KernelModuleBuild) DeepCopy() *KernelModuleBuild {
+	if in == nil {
+		return nil
+	}
+	out := new(KernelModuleBuild)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KernelModuleConfig) DeepCopyInto(out *KernelModuleConfig) {
+	*out = *in
+	out.TLS = in.TLS
+	if in.RegistrySecret != nil {
+		in, out := &in.RegistrySecret, &out.RegistrySecret
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+	if in.Signing != nil {
+		in, out := &in.Signing, &out.Signing
+		*out = new(KernelModuleSigning)
+		**out = **in
+	}
+	if in.BaseImages != nil {
+		in, out := &in.BaseImages, &out.BaseImages
+		*out = new(KernelModuleBaseImages)
+		**out = **in
+	}
+	if in.UpgradePreflight != nil {
+		in, out := &in.UpgradePreflight, &out.UpgradePreflight
+		*out = new(KernelModuleUpgradePreflight)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KernelModuleConfig.
+func (in *KernelModuleConfig) DeepCopy() *KernelModuleConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(KernelModuleConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KernelModuleRegistryTLS) DeepCopyInto(out *KernelModuleRegistryTLS) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KernelModuleRegistryTLS.
+func (in *KernelModuleRegistryTLS) DeepCopy() *KernelModuleRegistryTLS {
+	if in == nil {
+		return nil
+	}
+	out := new(KernelModuleRegistryTLS)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KernelModuleSigning) DeepCopyInto(out *KernelModuleSigning) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KernelModuleSigning.
+func (in *KernelModuleSigning) DeepCopy() *KernelModuleSigning {
+	if in == nil {
+		return nil
+	}
+	out := new(KernelModuleSigning)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KernelModuleUpgradePreflight) DeepCopyInto(out *KernelModuleUpgradePreflight) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KernelModuleUpgradePreflight.
+func (in *KernelModuleUpgradePreflight) DeepCopy() *KernelModuleUpgradePreflight {
+	if in == nil {
+		return nil
+	}
+	out := new(KernelModuleUpgradePreflight)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LUNNodeDevice) DeepCopyInto(out *LUNNodeDevice) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LUNNodeDevice.
+func (in *LUNNodeDevice) DeepCopy() *LUNNodeDevice {
+	if in == nil {
+		return nil
+	}
+	out := new(LUNNodeDevice)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LocalVolumeDiscovery) DeepCopyInto(out *LocalVolumeDiscovery) {
 	*out = *in
@@ -282,8 +617,27 @@ func (in *LocalVolumeDiscoveryResultStatus) DeepCopyInto(out *LocalVolumeDiscove
 	if in.DiscoveredDevices != nil {
 		in, out := &in.DiscoveredDevices, &out.DiscoveredDevices
 		*out = make([]DiscoveredDevice, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.RejectedDevices != nil {
+		in, out := &in.RejectedDevices, &out.RejectedDevices
+		*out = make([]RejectedDevice, len(*in))
 		copy(*out, *in)
 	}
+	if in.Initiators != nil {
+		in, out := &in.Initiators, &out.Initiators
+		*out = new(NodeInitiators)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeDiscoveryResultStatus.
@@ -301,16 +655,21 @@ func (in *LocalVolumeDiscoverySpec) DeepCopyInto(out *LocalVolumeDiscoverySpec)
 	*out = *in
 	if in.NodeSelector != nil {
 		in, out := &in.NodeSelector, &out.NodeSelector
-		*out = new(corev1.NodeSelector)
+		*out = new(v1.NodeSelector)
 		(*in).DeepCopyInto(*out)
 	}
 	if in.Tolerations != nil {
 		in, out := &in.Tolerations, &out.Tolerations
-		*out = make([]corev1.Toleration, len(*in))
+		*out = make([]v1.Toleration, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.DeviceFilter != nil {
+		in, out := &in.DeviceFilter, &out.DeviceFilter
+		*out = new(DeviceFilterPolicy)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeDiscoverySpec.
@@ -328,7 +687,7 @@ func (in *LocalVolumeDiscoveryStatus) DeepCopyInto(out *LocalVolumeDiscoveryStat
 	*out = *in
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
-		*out = make([]v1.Condition, len(*in))
+		*out = make([]metav1.Condition, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -345,9 +704,328 @@ func (in *LocalVolumeDiscoveryStatus) DeepCopy() *LocalVolumeDiscoveryStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MultipathMember) DeepCopyInto(out *MultipathMember) {
+	*out = *in
+	if in.NUMANode != nil {
+		in, out := &in.NUMANode, &out.NUMANode
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MultipathMember.
+func (in *MultipathMember) DeepCopy() *MultipathMember {
+	if in == nil {
+		return nil
+	}
+	out := new(MultipathMember)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeFacts) DeepCopyInto(out *NodeFacts) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeFacts.
+func (in *NodeFacts) DeepCopy() *NodeFacts {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeFacts)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NodeFacts) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeFactsList) DeepCopyInto(out *NodeFactsList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NodeFacts, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeFactsList.
+func (in *NodeFactsList) DeepCopy() *NodeFactsList {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeFactsList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NodeFactsList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeFactsSpec) DeepCopyInto(out *NodeFactsSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeFactsSpec.
+func (in *NodeFactsSpec) DeepCopy() *NodeFactsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeFactsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeFactsStatus) DeepCopyInto(out *NodeFactsStatus) {
+	*out = *in
+	if in.HugePages != nil {
+		in, out := &in.HugePages, &out.HugePages
+		*out = make([]HugePages, len(*in))
+		copy(*out, *in)
+	}
+	if in.LoadedModules != nil {
+		in, out := &in.LoadedModules, &out.LoadedModules
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeFactsStatus.
+func (in *NodeFactsStatus) DeepCopy() *NodeFactsStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeFactsStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeInitiators) DeepCopyInto(out *NodeInitiators) {
+	*out = *in
+	if in.FCHosts != nil {
+		in, out := &in.FCHosts, &out.FCHosts
+		*out = make([]FCHost, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeInitiators.
+func (in *NodeInitiators) DeepCopy() *NodeInitiators {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeInitiators)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeLocalDevice) DeepCopyInto(out *NodeLocalDevice) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeLocalDevice.
+func (in *NodeLocalDevice) DeepCopy() *NodeLocalDevice {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeLocalDevice)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProvisionedDeviceCount) DeepCopyInto(out *ProvisionedDeviceCount) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProvisionedDeviceCount.
+func (in *ProvisionedDeviceCount) DeepCopy() *ProvisionedDeviceCount {
+	if in == nil {
+		return nil
+	}
+	out := new(ProvisionedDeviceCount)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RejectedDevice) DeepCopyInto(out *RejectedDevice) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RejectedDevice.
+func (in *RejectedDevice) DeepCopy() *RejectedDevice {
+	if in == nil {
+		return nil
+	}
+	out := new(RejectedDevice)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SharedLUN) DeepCopyInto(out *SharedLUN) {
+	*out = *in
+	if in.Nodes != nil {
+		in, out := &in.Nodes, &out.Nodes
+		*out = make([]LUNNodeDevice, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SharedLUN.
+func (in *SharedLUN) DeepCopy() *SharedLUN {
+	if in == nil {
+		return nil
+	}
+	out := new(SharedLUN)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SharedLUNInventory) DeepCopyInto(out *SharedLUNInventory) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SharedLUNInventory.
+func (in *SharedLUNInventory) DeepCopy() *SharedLUNInventory {
+	if in == nil {
+		return nil
+	}
+	out := new(SharedLUNInventory)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SharedLUNInventory) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SharedLUNInventoryList) DeepCopyInto(out *SharedLUNInventoryList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SharedLUNInventory, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SharedLUNInventoryList.
+func (in *SharedLUNInventoryList) DeepCopy() *SharedLUNInventoryList {
+	if in == nil {
+		return nil
+	}
+	out := new(SharedLUNInventoryList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SharedLUNInventoryList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SharedLUNInventorySpec) DeepCopyInto(out *SharedLUNInventorySpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SharedLUNInventorySpec.
+func (in *SharedLUNInventorySpec) DeepCopy() *SharedLUNInventorySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SharedLUNInventorySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SharedLUNInventoryStatus) DeepCopyInto(out *SharedLUNInventoryStatus) {
+	*out = *in
+	if in.StorageNodes != nil {
+		in, out := &in.StorageNodes, &out.StorageNodes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LUNs != nil {
+		in, out := &in.LUNs, &out.LUNs
+		*out = make([]SharedLUN, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.NodeLocalDevices != nil {
+		in, out := &in.NodeLocalDevices, &out.NodeLocalDevices
+		*out = make([]NodeLocalDevice, len(*in))
+		copy(*out, *in)
+	}
+	if in.LastUpdated != nil {
+		in, out := &in.LastUpdated, &out.LastUpdated
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SharedLUNInventoryStatus.
+func (in *SharedLUNInventoryStatus) DeepCopy() *SharedLUNInventoryStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SharedLUNInventoryStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *StorageDeviceDiscovery) DeepCopyInto(out *StorageDeviceDiscovery) {
 	*out = *in
+	if in.DeviceFilter != nil {
+		in, out := &in.DeviceFilter, &out.DeviceFilter
+		*out = new(DeviceFilterPolicy)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StorageDeviceDiscovery.
@@ -27,24 +27,34 @@ import (
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
+	corev1 "k8s.io/api/core/v1"
 	k8sruntime "k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	kmmv1beta1 "github.com/rh-ecosystem-edge/kernel-module-management/api/v1beta1"
+	kmmv1beta2 "github.com/rh-ecosystem-edge/kernel-module-management/api/v1beta2"
 
+	operatorsv2 "github.com/operator-framework/api/pkg/operators/v2"
+
+	configv1 "github.com/openshift/api/config/v1"
 	consolev1 "github.com/openshift/api/console/v1"
 	imageregistryv1 "github.com/openshift/api/imageregistry/v1"
 	operatorv1 "github.com/openshift/api/operator/v1"
 
+	"github.com/openshift-storage-scale/openshift-fusion-access-operator/internal/controller/devicewipe"
 	lvdcontroller "github.com/openshift-storage-scale/openshift-fusion-access-operator/internal/controller/localvolumediscovery"
+	"github.com/openshift-storage-scale/openshift-fusion-access-operator/internal/controller/sharedluninventory"
 
 	fusionv1alpha "github.com/openshift-storage-scale/openshift-fusion-access-operator/api/v1alpha1"
 	"github.com/openshift-storage-scale/openshift-fusion-access-operator/internal/controller"
+	"github.com/openshift-storage-scale/openshift-fusion-access-operator/internal/utils"
 	"github.com/openshift-storage-scale/openshift-fusion-access-operator/version"
 	//+kubebuilder:scaffold:imports
 )
@@ -67,6 +77,12 @@ func init() {
 
 	utilruntime.Must(kmmv1beta1.AddToScheme(scheme))
 
+	utilruntime.Must(kmmv1beta2.AddToScheme(scheme))
+
+	utilruntime.Must(configv1.AddToScheme(scheme))
+
+	utilruntime.Must(operatorsv2.AddToScheme(scheme))
+
 	//+kubebuilder:scaffold:scheme
 }
 
@@ -109,9 +125,20 @@ func main() {
 		TLSOpts: tlsOpts,
 	})
 
+	namespace, err := utils.GetDeploymentNamespace()
+	if err != nil {
+		setupLog.Error(err, "unable to get the namespace of the operator")
+		os.Exit(1)
+	}
+
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
-		Scheme:                 scheme,
-		WebhookServer:          webhookServer,
+		Scheme:        scheme,
+		WebhookServer: webhookServer,
+		Cache: cache.Options{
+			ByObject: map[client.Object]cache.ByObject{
+				&corev1.Pod{}: controller.PodCacheOptions(namespace),
+			},
+		},
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection:         enableLeaderElection,
 		LeaderElectionID:       "769abcff.fusion.storage.openshift.io",
@@ -140,6 +167,22 @@ func main() {
 		os.Exit(1)
 	}
 
+	if err = (&sharedluninventory.SharedLUNInventoryReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create SharedLUNInventory controller")
+		os.Exit(1)
+	}
+
+	if err = (&devicewipe.DeviceWipeRequestReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create DeviceWipeRequest controller")
+		os.Exit(1)
+	}
+
 	if err = (controller.NewFusionAccessReconciler(mgr.GetClient(), mgr.GetScheme())).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "FusionAccess")
 		os.Exit(1)
@@ -59,6 +59,7 @@ export const useLunsViewModel = () => {
       setLuns(
         discoveredDevices
           .filter(outDevicesUsedByLocalDisks(localDisks.data ?? []))
+          .filter(outDevicesWithData)
           .map(toLun)
       );
     }
@@ -127,6 +128,10 @@ const outDevicesUsedByLocalDisks =
         )
       : true;
 
+// Devices discovered before the header was classified have no header
+const outDevicesWithData = (disk: DiscoveredDevice) =>
+  disk.header === undefined || disk.header === "Free";
+
 const toLun = (disk: DiscoveredDevice): Lun => {
   return {
     isSelected: false,
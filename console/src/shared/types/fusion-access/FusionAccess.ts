@@ -19,6 +19,7 @@ interface FusionAccessSpec {
      * @default true
      */
     create?: boolean;
+    deviceFilter?: DeviceFilterPolicy;
   };
 
   /**
@@ -57,6 +58,35 @@ interface FusionAccessStatus {
    * @format int32
    */
   totalProvisionedDeviceCount?: number;
+
+  /**
+   * ProvisionedDevicesByNode is the count of provisioned devices
+   * per node the device was gathered from
+   */
+  provisionedDevicesByNode?: ProvisionedDeviceCount[];
+
+  /**
+   * ProvisionedDevicesByFilesystem is the count of provisioned
+   * devices per filesystem using them
+   */
+  provisionedDevicesByFilesystem?: ProvisionedDeviceCount[];
+}
+
+/**
+ * ProvisionedDeviceCount is the number of provisioned devices for
+ * a node or a filesystem
+ */
+interface ProvisionedDeviceCount {
+  /**
+   * Name of the node or the filesystem
+   */
+  name: string;
+
+  /**
+   * Count of provisioned devices
+   * @format int32
+   */
+  count: number;
 }
 
 /**
@@ -111,3 +141,15 @@ interface Condition {
    */
   type: string;
 }
+
+export interface DeviceFilterPolicy {
+  minSize?: string | number;
+  maxSize?: string | number;
+  vendorRegex?: string;
+  modelRegex?: string;
+  deviceTypes?: Array<"disk" | "part" | "lvm" | "mpath">;
+  byPathGlobs?: string[];
+  byIDGlobs?: string[];
+  allowNoWWN?: boolean;
+  excludeDevices?: string[];
+}
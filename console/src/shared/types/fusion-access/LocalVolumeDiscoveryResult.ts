@@ -7,9 +7,33 @@ export interface LocalVolumeDiscoveryResult extends K8sResourceKind {
   status?: {
     discoveredDevices?: DiscoveredDevice[];
     discoveredTimeStamp?: string;
+    rejectedDevices?: RejectedDevice[];
+    initiators?: NodeInitiators;
+    conditions?: Array<{
+      lastTransitionTime: string;
+      message: string;
+      observedGeneration?: number;
+      reason: string;
+      status: "True" | "False" | "Unknown";
+      type: string;
+    }>;
   };
 }
 
+export interface NodeInitiators {
+  fcHosts?: FCHost[];
+  iscsiInitiatorName?: string;
+}
+
+export interface FCHost {
+  name: string;
+  portName?: string;
+  nodeName?: string;
+  portState?: string;
+  speed?: string;
+  fabricName?: string;
+}
+
 export interface DiscoveredDevice {
   WWN: string;
   deviceID: string;
@@ -18,4 +42,64 @@ export interface DiscoveredDevice {
   size: number;
   type: string;
   vendor: string;
+  serial?: string;
+  property?: "Rotational" | "NonRotational";
+  transport?: string;
+  logicalSectorSize?: number;
+  physicalSectorSize?: number;
+  byPath?: string;
+  multipathMembers?: MultipathMember[];
+  totalPaths?: number;
+  activePaths?: number;
+  expectedPaths?: number;
+  numaNode?: number;
+  header?: DeviceHeaderClass;
+  nsdName?: string;
+  signature?: string;
+  persistentReservation?: PersistentReservationSupport;
+  persistentReservationTypes?: string[];
+}
+
+export type DeviceHeaderClass =
+  | "Free"
+  | "GPFSNSD"
+  | "ForeignSignature"
+  | "Unknown";
+
+export type PersistentReservationSupport =
+  | "Supported"
+  | "Unsupported"
+  | "Unknown";
+
+export interface MultipathMember {
+  name: string;
+  path: string;
+  byPath?: string;
+  state?: string;
+  transport?: string;
+  numaNode?: number;
+}
+
+export type RejectionReason =
+  | "ReadOnly"
+  | "Removable"
+  | "HasFilesystem"
+  | "HasPartitions"
+  | "NoWWN"
+  | "BootDevice"
+  | "Mounted"
+  | "UnsupportedType"
+  | "Suspended"
+  | "ZeroSize"
+  | "ExcludedByDeviceFilter";
+
+export interface RejectedDevice {
+  WWN?: string;
+  deviceID?: string;
+  model?: string;
+  path: string;
+  size?: number;
+  vendor?: string;
+  reason: RejectionReason;
+  message: string;
 }
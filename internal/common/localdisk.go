@@ -0,0 +1,32 @@
+package common
+
+import (
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// LocalDiskGVK is the kind of the IBM Storage Scale LocalDisk objects. There are no Go types for
+// them, so they are read as unstructured objects
+var LocalDiskGVK = schema.GroupVersionKind{Group: "scale.spectrum.ibm.com", Version: "v1beta1", Kind: "LocalDisk"}
+
+// LocalDiskNameHasWWN returns true when the LocalDisk name ends with the WWN, which is how the console
+// names the LocalDisks it creates
+func LocalDiskNameHasWWN(name, wwn string) bool {
+	return wwn != "" && strings.HasSuffix(name, "-"+strings.ReplaceAll(strings.TrimPrefix(wwn, "uuid."), ".", "-"))
+}
+
+// LocalDiskUsesLUN returns true when the LocalDisk uses the LUN with the given WWN. namesByNode holds the
+// names of the devices of the LUN on each node that discovered it. A LocalDisk references the device of
+// its node by one of these names. The LocalDisks whose node did not discover the LUN, e.g. because the
+// node is down, are matched by the WWN in their name. A LUN without a WWN is only matched by its names
+func LocalDiskUsesLUN(localDisk *unstructured.Unstructured, wwn string, namesByNode map[string][]string) bool {
+	if LocalDiskNameHasWWN(localDisk.GetName(), wwn) {
+		return true
+	}
+	node, _, _ := unstructured.NestedString(localDisk.Object, "spec", "node")
+	device, _, _ := unstructured.NestedString(localDisk.Object, "spec", "device")
+	return device != "" && slices.Contains(namesByNode[node], device)
+}
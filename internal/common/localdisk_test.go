@@ -0,0 +1,39 @@
+package common
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+var _ = Describe("LocalDiskUsesLUN", func() {
+	const wwn = "0x600a098038314d6f4a5d4f6b2f6c7a01"
+
+	localDisk := func(name, node, device string) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{Object: map[string]interface{}{
+			"spec": map[string]interface{}{"node": node, "device": device},
+		}}
+		obj.SetName(name)
+		return obj
+	}
+
+	namesByNode := map[string][]string{
+		"worker-0": {"/dev/dm-0", "/dev/disk/by-id/dm-uuid-mpath-3600a0980", "/dev/sdb", "/dev/sdc"},
+		"worker-1": {"/dev/sdd"},
+	}
+
+	DescribeTable("matches the LocalDisks using the LUN",
+		func(disk *unstructured.Unstructured, wwn string, expected bool) {
+			Expect(LocalDiskUsesLUN(disk, wwn, namesByNode)).To(Equal(expected))
+		},
+		Entry("device of its node", localDisk("nsd1", "worker-0", "/dev/dm-0"), wwn, true),
+		Entry("path of a multipath device", localDisk("nsd1", "worker-0", "/dev/sdc"), wwn, true),
+		Entry("persistent name", localDisk("nsd1", "worker-0", "/dev/disk/by-id/dm-uuid-mpath-3600a0980"), wwn, true),
+		Entry("device of another node", localDisk("nsd1", "worker-1", "/dev/dm-0"), wwn, false),
+		Entry("node that did not discover the LUN", localDisk("nsd1", "worker-2", "/dev/sdb"), wwn, false),
+		Entry("WWN in the name", localDisk("sdb-"+wwn, "worker-2", "/dev/sdb"), wwn, true),
+		Entry("uuid WWN in the name", localDisk("sdb-0x600d", "worker-2", "/dev/sdb"), "uuid.0x600d", true),
+		Entry("LUN without WWN", localDisk("nsd1", "worker-1", "/dev/sdd"), "", true),
+		Entry("LocalDisk without device", localDisk("nsd1", "worker-0", ""), wwn, false),
+	)
+})
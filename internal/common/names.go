@@ -22,6 +22,9 @@ const (
 	DiscoveryNodeLabel = "discovery-result-node"
 
 	DeviceFinderDiscoveryDaemonSetTemplate = "templates/devicefinder-discovery-daemonset.yaml"
+
+	// StorageScaleNamespace is where the IBM Storage Scale operator runs the core daemons and the LocalDisks live
+	StorageScaleNamespace = "ibm-spectrum-scale"
 )
 
 // GetDeviceFinderImage returns the image to be used for devicefinder daemonset
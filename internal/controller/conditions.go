@@ -0,0 +1,259 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	kmmv1beta1 "github.com/rh-ecosystem-edge/kernel-module-management/api/v1beta1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/api/meta"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	fusionv1alpha1 "github.com/openshift-storage-scale/openshift-fusion-access-operator/api/v1alpha1"
+	"github.com/openshift-storage-scale/openshift-fusion-access-operator/internal/controller/kernelmodule"
+	"github.com/openshift-storage-scale/openshift-fusion-access-operator/internal/controller/localvolumediscovery"
+)
+
+// Conditions reported for each component the operator manages
+const (
+	ConditionManifestApplied          = "ManifestApplied"
+	ConditionEntitlementSecretsSynced = "EntitlementSecretsSynced"
+	ConditionRegistryValid            = "RegistryValid"
+	ConditionKernelModuleReady        = "KernelModuleReady"
+	ConditionConsolePluginEnabled     = "ConsolePluginEnabled"
+	ConditionImagePullVerified        = "ImagePullVerified"
+	ConditionDeviceDiscoveryRunning   = "DeviceDiscoveryRunning"
+)
+
+// Reasons shared by all the component conditions
+const (
+	// ReasonSucceeded is used with status True once the component is reconciled
+	ReasonSucceeded = "Succeeded"
+	// ReasonInProgress is used with status False while we wait on the component
+	ReasonInProgress = "InProgress"
+	// ReasonFailed is used with status False when reconciling the component failed
+	ReasonFailed = "Failed"
+	// ReasonNotRequired is used with status False when the component is not needed with the current spec
+	ReasonNotRequired = "NotRequired"
+)
+
+// componentConditions are the conditions the overall phase is derived from
+var componentConditions = []string{
+	ConditionManifestApplied,
+	ConditionEntitlementSecretsSynced,
+	ConditionRegistryValid,
+	ConditionKernelModuleReady,
+	ConditionConsolePluginEnabled,
+	ConditionImagePullVerified,
+	ConditionDeviceDiscoveryRunning,
+}
+
+// legacyConditions were reported by older versions of the operator and are replaced by componentConditions
+var legacyConditions = []string{"ManifestApply", "ImageRegistryStorage", "ImagePull"}
+
+// setComponentCondition records the state of a component for the current generation
+func setComponentCondition(fusionaccess *fusionv1alpha1.FusionAccess, conditionType, reason, message string) {
+	status := v1.ConditionFalse
+	if reason == ReasonSucceeded {
+		status = v1.ConditionTrue
+	}
+	meta.SetStatusCondition(&fusionaccess.Status.Conditions, v1.Condition{
+		Type:               conditionType,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: fusionaccess.Generation,
+	})
+}
+
+// computePhase derives the overall phase from the component conditions. A failed component makes
+// the object Degraded, a missing, outdated or pending one makes it Progressing
+func computePhase(fusionaccess *fusionv1alpha1.FusionAccess) string {
+	phase := fusionv1alpha1.PhaseReady
+	for _, conditionType := range componentConditions {
+		condition := meta.FindStatusCondition(fusionaccess.Status.Conditions, conditionType)
+		switch {
+		case condition == nil || condition.ObservedGeneration != fusionaccess.Generation:
+			phase = fusionv1alpha1.PhaseProgressing
+		case condition.Reason == ReasonFailed:
+			return fusionv1alpha1.PhaseDegraded
+		case condition.Status != v1.ConditionTrue && condition.Reason != ReasonNotRequired:
+			phase = fusionv1alpha1.PhaseProgressing
+		}
+	}
+	if meta.IsStatusConditionTrue(fusionaccess.Status.Conditions, ConditionUpgrading) {
+		phase = fusionv1alpha1.PhaseProgressing
+	}
+	return phase
+}
+
+// computeObservedGeneration returns the generation every component condition has been evaluated for,
+// or the current observedGeneration if some components were not evaluated yet
+func computeObservedGeneration(fusionaccess *fusionv1alpha1.FusionAccess) int64 {
+	var observed int64 = -1
+	for _, conditionType := range componentConditions {
+		condition := meta.FindStatusCondition(fusionaccess.Status.Conditions, conditionType)
+		if condition == nil {
+			return fusionaccess.Status.ObservedGeneration
+		}
+		if observed == -1 || condition.ObservedGeneration < observed {
+			observed = condition.ObservedGeneration
+		}
+	}
+	return observed
+}
+
+// updateStatus derives the phase and the observedGeneration from the conditions and updates the status
+func (r *FusionAccessReconciler) updateStatus(ctx context.Context, fusionaccess *fusionv1alpha1.FusionAccess) error {
+	for _, conditionType := range legacyConditions {
+		meta.RemoveStatusCondition(&fusionaccess.Status.Conditions, conditionType)
+	}
+	fusionaccess.Status.Status = computePhase(fusionaccess)
+	fusionaccess.Status.ObservedGeneration = computeObservedGeneration(fusionaccess)
+	return r.Status().Update(ctx, fusionaccess)
+}
+
+// failComponent marks the component as failed and updates the status. It returns the original error
+func (r *FusionAccessReconciler) failComponent(ctx context.Context, fusionaccess *fusionv1alpha1.FusionAccess, conditionType string, err error) error {
+	setComponentCondition(fusionaccess, conditionType, ReasonFailed, err.Error())
+	if serr := r.updateStatus(ctx, fusionaccess); serr != nil {
+		return errors.Join(serr, err)
+	}
+	return err
+}
+
+// Reasons of the events recorded when the KernelModuleReady condition changes
+const (
+	EventKernelModuleLoaded      = "KernelModuleLoaded"
+	EventKernelModuleLoading     = "KernelModuleLoading"
+	EventKernelModuleBuildFailed = "KernelModuleBuildFailed"
+)
+
+// updateKernelModuleCondition reports the state of the kernel module image of each kernel and whether KMM
+// loaded the kernel module on all the selected nodes. Changes of the condition are recorded as events
+func (r *FusionAccessReconciler) updateKernelModuleCondition(ctx context.Context, ns string, fusionaccess *fusionv1alpha1.FusionAccess) error {
+	modules, err := kernelmodule.ListKMMModules(ctx, r.Client, ns)
+	if err != nil {
+		return fmt.Errorf("failed to list modules in updateKernelModuleCondition: %w", err)
+	}
+	builds, err := kernelmodule.GetKMMBuildStatus(ctx, r.Client, ns)
+	if err != nil {
+		return fmt.Errorf("failed to get the kernel module builds in updateKernelModuleCondition: %w", err)
+	}
+	fusionaccess.Status.KernelModuleBuilds = builds
+
+	// The Modules of the architectures load the kernel module on distinct nodes
+	loader := kmmv1beta1.DaemonSetStatus{}
+	for _, module := range modules {
+		loader.DesiredNumber += module.Status.ModuleLoader.DesiredNumber
+		loader.AvailableNumber += module.Status.ModuleLoader.AvailableNumber
+	}
+	loaded := fmt.Sprintf("Kernel module loaded on %d of %d nodes", loader.AvailableNumber, loader.DesiredNumber)
+	failed := []string{}
+	building := []string{}
+	for _, build := range builds {
+		switch build.State {
+		case fusionv1alpha1.KernelModuleBuildBuildFailed, fusionv1alpha1.KernelModuleBuildSignFailed:
+			failed = append(failed, fmt.Sprintf("kernel %s: %s %s", build.KernelVersion, build.State, build.Message))
+		case fusionv1alpha1.KernelModuleBuildReady:
+		default:
+			building = append(building, fmt.Sprintf("%s (%s)", build.KernelVersion, build.State))
+		}
+	}
+
+	// Copied since setComponentCondition updates the condition in place
+	previous := meta.FindStatusCondition(fusionaccess.Status.Conditions, ConditionKernelModuleReady).DeepCopy()
+	switch {
+	case len(failed) > 0:
+		setComponentCondition(fusionaccess, ConditionKernelModuleReady, ReasonFailed,
+			fmt.Sprintf("%s. Failed to build the kernel module image for %s", loaded, strings.Join(failed, "; ")))
+	case loader.AvailableNumber < loader.DesiredNumber && len(building) > 0:
+		setComponentCondition(fusionaccess, ConditionKernelModuleReady, ReasonInProgress,
+			fmt.Sprintf("%s, waiting for the kernel module image of kernels %s", loaded, strings.Join(building, ", ")))
+	case loader.AvailableNumber < loader.DesiredNumber:
+		setComponentCondition(fusionaccess, ConditionKernelModuleReady, ReasonInProgress, loaded)
+	default:
+		setComponentCondition(fusionaccess, ConditionKernelModuleReady, ReasonSucceeded,
+			fmt.Sprintf("Kernel module loaded on %d nodes", loader.AvailableNumber))
+	}
+	r.recordKernelModuleEvent(fusionaccess, previous)
+	return nil
+}
+
+// recordKernelModuleEvent records an event on the FusionAccess when the KernelModuleReady condition changed
+func (r *FusionAccessReconciler) recordKernelModuleEvent(fusionaccess *fusionv1alpha1.FusionAccess, previous *v1.Condition) {
+	current := meta.FindStatusCondition(fusionaccess.Status.Conditions, ConditionKernelModuleReady)
+	if r.Recorder == nil || current == nil ||
+		(previous != nil && previous.Reason == current.Reason && previous.Message == current.Message) {
+		return
+	}
+	switch current.Reason {
+	case ReasonFailed:
+		r.Recorder.Event(fusionaccess, corev1.EventTypeWarning, EventKernelModuleBuildFailed, current.Message)
+	case ReasonSucceeded:
+		r.Recorder.Event(fusionaccess, corev1.EventTypeNormal, EventKernelModuleLoaded, current.Message)
+	default:
+		r.Recorder.Event(fusionaccess, corev1.EventTypeNormal, EventKernelModuleLoading, current.Message)
+	}
+}
+
+// updateDeviceDiscoveryCondition reports the state of the LocalVolumeDiscovery. Not having any node to run
+// discovery on is expected until the storage nodes are labeled, so it does not degrade the FusionAccess
+func (r *FusionAccessReconciler) updateDeviceDiscoveryCondition(
+	ctx context.Context,
+	lvd *fusionv1alpha1.LocalVolumeDiscovery,
+	fusionaccess *fusionv1alpha1.FusionAccess,
+) error {
+	current := &fusionv1alpha1.LocalVolumeDiscovery{}
+	if err := r.Get(ctx, client.ObjectKeyFromObject(lvd), current); err != nil {
+		return fmt.Errorf("failed to get LocalVolumeDiscovery in updateDeviceDiscoveryCondition: %w", err)
+	}
+	message := "Waiting for device discovery to start"
+	if len(current.Status.Conditions) > 0 {
+		message = current.Status.Conditions[0].Message
+	}
+	switch current.Status.Phase {
+	case fusionv1alpha1.Discovering:
+		if len(current.Status.Conditions) > 0 && current.Status.Conditions[0].Status == v1.ConditionTrue {
+			setComponentCondition(fusionaccess, ConditionDeviceDiscoveryRunning, ReasonSucceeded, message)
+		} else {
+			setComponentCondition(fusionaccess, ConditionDeviceDiscoveryRunning, ReasonInProgress, message)
+		}
+	case fusionv1alpha1.DiscoveryFailed:
+		ds := &appsv1.DaemonSet{}
+		err := r.Get(ctx, types.NamespacedName{Namespace: lvd.Namespace, Name: localvolumediscovery.DeviceFinderDiscovery}, ds)
+		if err != nil && !kerrors.IsNotFound(err) {
+			return fmt.Errorf("failed to get daemonset %s in updateDeviceDiscoveryCondition: %w", localvolumediscovery.DeviceFinderDiscovery, err)
+		}
+		if err == nil && ds.Status.DesiredNumberScheduled == 0 {
+			setComponentCondition(fusionaccess, ConditionDeviceDiscoveryRunning, ReasonNotRequired, "No node is selected for device discovery yet")
+		} else {
+			setComponentCondition(fusionaccess, ConditionDeviceDiscoveryRunning, ReasonFailed, message)
+		}
+	default:
+		setComponentCondition(fusionaccess, ConditionDeviceDiscoveryRunning, ReasonInProgress, message)
+	}
+	return nil
+}
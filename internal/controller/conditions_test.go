@@ -0,0 +1,190 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	kmmv1beta1 "github.com/rh-ecosystem-edge/kernel-module-management/api/v1beta1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	fusionv1alpha "github.com/openshift-storage-scale/openshift-fusion-access-operator/api/v1alpha1"
+	"github.com/openshift-storage-scale/openshift-fusion-access-operator/internal/controller/kernelmodule"
+	"github.com/openshift-storage-scale/openshift-fusion-access-operator/internal/controller/localvolumediscovery"
+)
+
+var _ = Describe("FusionAccess phase", func() {
+	// newFusionAccess returns a FusionAccess at generation 2 where every component succeeded
+	newFusionAccess := func() *fusionv1alpha.FusionAccess {
+		fa := &fusionv1alpha.FusionAccess{ObjectMeta: metav1.ObjectMeta{Generation: 2}}
+		for _, conditionType := range componentConditions {
+			setComponentCondition(fa, conditionType, ReasonSucceeded, "done")
+		}
+		return fa
+	}
+
+	DescribeTable("computePhase",
+		func(mutate func(fa *fusionv1alpha.FusionAccess), expected string) {
+			fa := newFusionAccess()
+			mutate(fa)
+			Expect(computePhase(fa)).To(Equal(expected))
+		},
+		Entry("all components succeeded", func(_ *fusionv1alpha.FusionAccess) {}, fusionv1alpha.PhaseReady),
+		Entry("not required components are ignored", func(fa *fusionv1alpha.FusionAccess) {
+			setComponentCondition(fa, ConditionDeviceDiscoveryRunning, ReasonNotRequired, "disabled")
+		}, fusionv1alpha.PhaseReady),
+		Entry("a component is in progress", func(fa *fusionv1alpha.FusionAccess) {
+			setComponentCondition(fa, ConditionKernelModuleReady, ReasonInProgress, "loading")
+		}, fusionv1alpha.PhaseProgressing),
+		Entry("a component was not evaluated yet", func(fa *fusionv1alpha.FusionAccess) {
+			meta.RemoveStatusCondition(&fa.Status.Conditions, ConditionConsolePluginEnabled)
+		}, fusionv1alpha.PhaseProgressing),
+		Entry("the spec changed since the last reconcile", func(fa *fusionv1alpha.FusionAccess) {
+			fa.Generation = 3
+		}, fusionv1alpha.PhaseProgressing),
+		Entry("a component failed", func(fa *fusionv1alpha.FusionAccess) {
+			setComponentCondition(fa, ConditionKernelModuleReady, ReasonInProgress, "loading")
+			setComponentCondition(fa, ConditionRegistryValid, ReasonFailed, "emptyDir storage")
+		}, fusionv1alpha.PhaseDegraded),
+		Entry("an upgrade is running", func(fa *fusionv1alpha.FusionAccess) {
+			meta.SetStatusCondition(&fa.Status.Conditions, metav1.Condition{
+				Type: ConditionUpgrading, Status: metav1.ConditionTrue, Reason: ReasonUpgradeBuildingKernelModule,
+			})
+		}, fusionv1alpha.PhaseProgressing),
+	)
+
+	It("reports the generation all components were evaluated for", func() {
+		fa := newFusionAccess()
+		Expect(computeObservedGeneration(fa)).To(Equal(int64(2)))
+
+		fa.Generation = 3
+		setComponentCondition(fa, ConditionManifestApplied, ReasonSucceeded, "applied")
+		Expect(computeObservedGeneration(fa)).To(Equal(int64(2)))
+	})
+
+	It("keeps the observed generation until all components were evaluated", func() {
+		fa := &fusionv1alpha.FusionAccess{ObjectMeta: metav1.ObjectMeta{Generation: 2}}
+		fa.Status.ObservedGeneration = 1
+		setComponentCondition(fa, ConditionManifestApplied, ReasonSucceeded, "applied")
+		Expect(computeObservedGeneration(fa)).To(Equal(int64(1)))
+	})
+})
+
+var _ = Describe("DeviceDiscoveryRunning condition", func() {
+	const ns = "ibm-fusion-access-operator"
+
+	DescribeTable("maps the LocalVolumeDiscovery status",
+		func(phase fusionv1alpha.DiscoveryPhase, available metav1.ConditionStatus, desiredDaemons int32, expectedReason string) {
+			lvd := localvolumediscovery.NewLocalVolumeDiscovery(ns)
+			lvd.Status.Phase = phase
+			lvd.Status.Conditions = []metav1.Condition{{Type: "Available", Status: available, Message: "discovery status"}}
+			ds := &appsv1.DaemonSet{
+				ObjectMeta: metav1.ObjectMeta{Name: localvolumediscovery.DeviceFinderDiscovery, Namespace: ns},
+				Status:     appsv1.DaemonSetStatus{DesiredNumberScheduled: desiredDaemons},
+			}
+			scheme := createFakeScheme()
+			Expect(appsv1.AddToScheme(scheme)).To(Succeed())
+			cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(lvd, ds).Build()
+			reconciler := &FusionAccessReconciler{Client: cl, Scheme: scheme}
+
+			fa := &fusionv1alpha.FusionAccess{}
+			Expect(reconciler.updateDeviceDiscoveryCondition(context.Background(), lvd, fa)).To(Succeed())
+			Expect(meta.FindStatusCondition(fa.Status.Conditions, ConditionDeviceDiscoveryRunning).Reason).To(Equal(expectedReason))
+		},
+		Entry("all daemons running", fusionv1alpha.Discovering, metav1.ConditionTrue, int32(3), ReasonSucceeded),
+		Entry("daemons starting", fusionv1alpha.Discovering, metav1.ConditionFalse, int32(3), ReasonInProgress),
+		Entry("no storage nodes yet", fusionv1alpha.DiscoveryFailed, metav1.ConditionFalse, int32(0), ReasonNotRequired),
+		Entry("daemonset failure", fusionv1alpha.DiscoveryFailed, metav1.ConditionFalse, int32(3), ReasonFailed),
+		Entry("not reconciled yet", fusionv1alpha.DiscoveryPhase(""), metav1.ConditionFalse, int32(0), ReasonInProgress),
+	)
+})
+
+var _ = Describe("KernelModuleReady condition", func() {
+	const (
+		ns     = "ibm-fusion-access"
+		kernel = "5.14.0-570.el9.x86_64"
+		image  = "registry.example.com/gpfs_compat_kmod:5.14.0-570.el9.x86_64-abc123"
+	)
+
+	newObjects := func(imageState kmmv1beta1.ImageState, buildStatus kmmv1beta1.BuildOrSignStatus, available int32) []client.Object {
+		return []client.Object{
+			&kmmv1beta1.Module{
+				ObjectMeta: metav1.ObjectMeta{Name: kernelmodule.KMMModuleName, Namespace: ns},
+				Status: kmmv1beta1.ModuleStatus{
+					ModuleLoader: kmmv1beta1.DaemonSetStatus{DesiredNumber: 3, AvailableNumber: available},
+				},
+			},
+			&kmmv1beta1.ModuleImagesConfig{
+				ObjectMeta: metav1.ObjectMeta{Name: kernelmodule.KMMModuleName, Namespace: ns},
+				Spec:       kmmv1beta1.ModuleImagesConfigSpec{Images: []kmmv1beta1.ModuleImageSpec{{Image: image, KernelVersion: kernel}}},
+				Status:     kmmv1beta1.ModuleImagesConfigStatus{ImagesStates: []kmmv1beta1.ModuleImageState{{Image: image, Status: imageState}}},
+			},
+			&kmmv1beta1.ModuleBuildSignConfig{
+				ObjectMeta: metav1.ObjectMeta{Name: kernelmodule.KMMModuleName, Namespace: ns},
+				Status: kmmv1beta1.ModuleBuildSignConfigStatus{
+					Images: []kmmv1beta1.BuildSignImageState{{Image: image, Status: buildStatus, Action: kmmv1beta1.BuildImage}},
+				},
+			},
+		}
+	}
+
+	update := func(reconciler *FusionAccessReconciler, fa *fusionv1alpha.FusionAccess) *metav1.Condition {
+		Expect(reconciler.updateKernelModuleCondition(context.Background(), ns, fa)).To(Succeed())
+		return meta.FindStatusCondition(fa.Status.Conditions, ConditionKernelModuleReady)
+	}
+
+	It("reports the image builds, the loaded nodes and records the changes as events", func() {
+		originalGetPodLogTail := kernelmodule.GetPodLogTail
+		DeferCleanup(func() { kernelmodule.GetPodLogTail = originalGetPodLogTail })
+		kernelmodule.GetPodLogTail = func(_ context.Context, _, _ string, _ int64) (string, error) {
+			return "mmbuildgpl: Building GPL module failed\n", nil
+		}
+		buildPod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "gpfs-module-build-x7k2p", Namespace: ns, Labels: map[string]string{
+				kernelmodule.KMMModuleNameLabel:   kernelmodule.KMMModuleName,
+				kernelmodule.KMMPodTypeLabel:      kernelmodule.KMMPodTypeBuild,
+				kernelmodule.KMMTargetKernelLabel: kernel,
+			}},
+			Status: corev1.PodStatus{Phase: corev1.PodFailed},
+		}
+		recorder := record.NewFakeRecorder(10)
+		fa := &fusionv1alpha.FusionAccess{ObjectMeta: metav1.ObjectMeta{Name: "fusionaccess-object", Namespace: ns}}
+
+		cl := fake.NewClientBuilder().WithScheme(createFakeScheme()).
+			WithObjects(newObjects(kmmv1beta1.ImageNeedsBuilding, "", 0)...).Build()
+		reconciler := &FusionAccessReconciler{Client: cl, Recorder: recorder}
+		condition := update(reconciler, fa)
+		Expect(condition.Reason).To(Equal(ReasonInProgress))
+		Expect(condition.Message).To(Equal("Kernel module loaded on 0 of 3 nodes, waiting for the kernel module image of kernels " +
+			kernel + " (Building)"))
+		Expect(fa.Status.KernelModuleBuilds).To(Equal([]fusionv1alpha.KernelModuleBuild{{
+			KernelVersion: kernel, Module: kernelmodule.KMMModuleName, Image: image, State: fusionv1alpha.KernelModuleBuildBuilding,
+		}}))
+		Expect(recorder.Events).To(Receive(HavePrefix("Normal KernelModuleLoading")))
+
+		cl = fake.NewClientBuilder().WithScheme(createFakeScheme()).
+			WithObjects(append(newObjects(kmmv1beta1.ImageNeedsBuilding, kmmv1beta1.ActionFailure, 0), buildPod)...).Build()
+		reconciler.Client = cl
+		condition = update(reconciler, fa)
+		Expect(condition.Reason).To(Equal(ReasonFailed))
+		Expect(condition.Message).To(ContainSubstring("kernel " + kernel + ": BuildFailed build pod gpfs-module-build-x7k2p failed: " +
+			"mmbuildgpl: Building GPL module failed"))
+		Expect(recorder.Events).To(Receive(HavePrefix("Warning KernelModuleBuildFailed")))
+		update(reconciler, fa)
+		Expect(recorder.Events).ToNot(Receive())
+
+		cl = fake.NewClientBuilder().WithScheme(createFakeScheme()).
+			WithObjects(newObjects(kmmv1beta1.ImageExists, kmmv1beta1.ActionFailure, 3)...).Build()
+		reconciler.Client = cl
+		condition = update(reconciler, fa)
+		Expect(condition.Reason).To(Equal(ReasonSucceeded))
+		Expect(condition.Message).To(Equal("Kernel module loaded on 3 nodes"))
+		Expect(recorder.Events).To(Receive(HavePrefix("Normal KernelModuleLoaded")))
+	})
+})
@@ -119,3 +119,42 @@ func EnablePlugin(ctx context.Context, cl client.Client) error {
 	}
 	return nil
 }
+
+// DisablePlugin removes our plugin from the list of enabled plugins in the cluster console
+func DisablePlugin(ctx context.Context, cl client.Client) error {
+	consoleKey := client.ObjectKey{Namespace: "", Name: "cluster"}
+	consoleObj := &operatorv1.Console{}
+	if err := cl.Get(ctx, consoleKey, consoleObj); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("could not find resource - APIVersion: %s, Kind: %s, Name: %s: %w",
+			consoleObj.APIVersion, consoleObj.Kind, consoleObj.Name, err)
+	}
+
+	if slices.Contains(consoleObj.Spec.Plugins, PluginName) {
+		consoleObj.Spec.Plugins = slices.DeleteFunc(consoleObj.Spec.Plugins, func(p string) bool {
+			return p == PluginName
+		})
+		err := cl.Update(ctx, consoleObj)
+		if err != nil {
+			return fmt.Errorf("could not update resource - APIVersion: %s, Kind: %s, Name: %s: %w",
+				consoleObj.APIVersion, consoleObj.Kind, consoleObj.Name, err)
+		}
+	}
+	return nil
+}
+
+// DeletePlugin deletes the ConsolePlugin resource. The plugin should be disabled first
+// so the console does not try to load a plugin that no longer exists
+func DeletePlugin(ctx context.Context, cl client.Client) error {
+	cp := &consolev1.ConsolePlugin{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: PluginName,
+		},
+	}
+	if err := cl.Delete(ctx, cp); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("could not delete console plugin: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package devicewipe
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	fusionv1alpha1 "github.com/openshift-storage-scale/openshift-fusion-access-operator/api/v1alpha1"
+	"github.com/openshift-storage-scale/openshift-fusion-access-operator/internal/common"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// DeviceWipeRequestReconciler checks that the LUN of a DeviceWipeRequest is unused before approving it.
+// The devicefinder daemon of the node then clears the LUN and completes the request
+type DeviceWipeRequestReconciler struct {
+	Client client.Client
+	Scheme *runtime.Scheme
+}
+
+//+kubebuilder:rbac:groups=fusion.storage.openshift.io,resources=devicewiperequests,verbs=get;list;watch
+//+kubebuilder:rbac:groups=fusion.storage.openshift.io,resources=devicewiperequests/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=fusion.storage.openshift.io,resources=localvolumediscoveryresults,verbs=get;list;watch
+//+kubebuilder:rbac:groups=scale.spectrum.ibm.com,resources=localdisks,verbs=get;list;watch
+
+// Reconcile approves or rejects the new DeviceWipeRequests
+func (r *DeviceWipeRequestReconciler) Reconcile(ctx context.Context, request ctrl.Request) (ctrl.Result, error) {
+	wipeRequest := &fusionv1alpha1.DeviceWipeRequest{}
+	if err := r.Client.Get(ctx, request.NamespacedName, wipeRequest); err != nil {
+		if errors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, fmt.Errorf("failed to get DeviceWipeRequest: %w", err)
+	}
+	// The checks are done once, the node owns the request after its approval
+	if wipeRequest.Status.Phase != "" {
+		return ctrl.Result{}, nil
+	}
+	klog.InfoS("Reconciling DeviceWipeRequest", "name", request.Name, "wwn", wipeRequest.Spec.WWN, "node", wipeRequest.Spec.NodeName)
+
+	results := &fusionv1alpha1.LocalVolumeDiscoveryResultList{}
+	if err := r.Client.List(ctx, results); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to list LocalVolumeDiscoveryResults: %w", err)
+	}
+	localDisks, err := r.listLocalDisks(ctx)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	devicePath, reason := CheckWipeRequest(&wipeRequest.Spec, results.Items, localDisks)
+	if reason != "" {
+		klog.InfoS("Rejecting DeviceWipeRequest", "name", request.Name, "reason", reason)
+		wipeRequest.Status.Phase = fusionv1alpha1.DeviceWipeRejected
+		wipeRequest.Status.Message = reason
+	} else {
+		klog.InfoS("Approving DeviceWipeRequest", "name", request.Name, "device", devicePath)
+		now := metav1.Now()
+		wipeRequest.Status.Phase = fusionv1alpha1.DeviceWipeApproved
+		wipeRequest.Status.Message = fmt.Sprintf("LUN is unused, waiting for node %s to wipe %s", wipeRequest.Spec.NodeName, devicePath)
+		wipeRequest.Status.DevicePath = devicePath
+		wipeRequest.Status.ApprovalTime = &now
+	}
+	if err := r.Client.Status().Update(ctx, wipeRequest); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to update DeviceWipeRequest status: %w", err)
+	}
+	return ctrl.Result{}, nil
+}
+
+// listLocalDisks lists the Storage Scale LocalDisks. Until the Storage Scale manifest installed their
+// CRD there are none
+func (r *DeviceWipeRequestReconciler) listLocalDisks(ctx context.Context) ([]unstructured.Unstructured, error) {
+	localDisks := &unstructured.UnstructuredList{}
+	localDisks.SetGroupVersionKind(common.LocalDiskGVK.GroupVersion().WithKind(common.LocalDiskGVK.Kind + "List"))
+	err := r.Client.List(ctx, localDisks, client.InNamespace(common.StorageScaleNamespace))
+	if meta.IsNoMatchError(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list LocalDisks: %w", err)
+	}
+	return localDisks.Items, nil
+}
+
+// CheckWipeRequest checks that the LUN of the request can be wiped: the confirmation matches, the node
+// sees the LUN, no node has it mounted and no LocalDisk uses it. It returns the device of the LUN on the
+// node, or why the request is rejected
+func CheckWipeRequest(
+	spec *fusionv1alpha1.DeviceWipeRequestSpec,
+	results []fusionv1alpha1.LocalVolumeDiscoveryResult,
+	localDisks []unstructured.Unstructured,
+) (string, string) {
+	if spec.WWN == "" || spec.ConfirmWWN != spec.WWN {
+		return "", "confirmWWN does not match the WWN"
+	}
+
+	devicePath := ""
+	namesByNode := map[string][]string{}
+	for idx := range results {
+		result := &results[idx]
+		namesByNode[result.Spec.NodeName] = lunNames(result, spec.WWN)
+		// The devices with signatures are rejected by the discovery, the mounted ones among them
+		for _, device := range result.Status.RejectedDevices {
+			if device.WWN == spec.WWN && device.Reason == fusionv1alpha1.RejectedMounted {
+				return "", fmt.Sprintf("LUN is mounted on node %s: %s", result.Spec.NodeName, device.Message)
+			}
+		}
+		if result.Spec.NodeName != spec.NodeName {
+			continue
+		}
+		if names := namesByNode[result.Spec.NodeName]; len(names) > 0 {
+			devicePath = names[0]
+		}
+	}
+	if devicePath == "" {
+		return "", fmt.Sprintf("node %s did not discover the LUN", spec.NodeName)
+	}
+
+	for idx := range localDisks {
+		if common.LocalDiskUsesLUN(&localDisks[idx], spec.WWN, namesByNode) {
+			return "", fmt.Sprintf("LUN is used by LocalDisk %s", localDisks[idx].GetName())
+		}
+	}
+	return devicePath, ""
+}
+
+// lunNames returns the names of the devices of a LUN on the node of the result, whether the discovery
+// kept or rejected them. The device path comes first
+func lunNames(result *fusionv1alpha1.LocalVolumeDiscoveryResult, wwn string) []string {
+	var names []string
+	for idx := range result.Status.DiscoveredDevices {
+		if device := &result.Status.DiscoveredDevices[idx]; device.WWN == wwn {
+			names = append(names, device.Names()...)
+		}
+	}
+	for _, device := range result.Status.RejectedDevices {
+		if device.WWN != wwn {
+			continue
+		}
+		names = append(names, device.Path, device.DeviceID)
+	}
+	return slices.DeleteFunc(names, func(name string) bool { return name == "" })
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *DeviceWipeRequestReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&fusionv1alpha1.DeviceWipeRequest{}).
+		Complete(r)
+}
@@ -0,0 +1,156 @@
+package devicewipe
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	fusionv1alpha1 "github.com/openshift-storage-scale/openshift-fusion-access-operator/api/v1alpha1"
+	"github.com/openshift-storage-scale/openshift-fusion-access-operator/internal/common"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const wwn = "0x600a098038304437415d4b6a5968624f"
+
+func newResult(node string, discovered []fusionv1alpha1.DiscoveredDevice, rejected []fusionv1alpha1.RejectedDevice) fusionv1alpha1.LocalVolumeDiscoveryResult {
+	return fusionv1alpha1.LocalVolumeDiscoveryResult{
+		ObjectMeta: metav1.ObjectMeta{Name: "discovery-result-" + node, Namespace: "ibm-fusion-access"},
+		Spec:       fusionv1alpha1.LocalVolumeDiscoveryResultSpec{NodeName: node},
+		Status: fusionv1alpha1.LocalVolumeDiscoveryResultStatus{
+			DiscoveredDevices: discovered,
+			RejectedDevices:   rejected,
+		},
+	}
+}
+
+func newLocalDisk(name, node, device string) unstructured.Unstructured {
+	localDisk := unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{"node": node, "device": device},
+	}}
+	localDisk.SetGroupVersionKind(common.LocalDiskGVK)
+	localDisk.SetName(name)
+	localDisk.SetNamespace(common.StorageScaleNamespace)
+	return localDisk
+}
+
+// withSignature is the LUN as rejected by the discovery of a node because of its filesystem
+func withSignature(path string) []fusionv1alpha1.RejectedDevice {
+	return []fusionv1alpha1.RejectedDevice{{
+		Path: path, DeviceID: "/dev/disk/by-id/wwn-" + wwn, WWN: wwn,
+		Reason: fusionv1alpha1.RejectedHasFilesystem, Message: "device with filesystem xfs",
+	}}
+}
+
+var _ = Describe("CheckWipeRequest", func() {
+	spec := fusionv1alpha1.DeviceWipeRequestSpec{WWN: wwn, NodeName: "worker-0", ConfirmWWN: wwn}
+	mounted := []fusionv1alpha1.RejectedDevice{{
+		Path: "/dev/sdc", WWN: wwn, Reason: fusionv1alpha1.RejectedMounted, Message: `device mounted on "/var/data"`,
+	}}
+
+	DescribeTable("checks the LUN",
+		func(spec fusionv1alpha1.DeviceWipeRequestSpec, results []fusionv1alpha1.LocalVolumeDiscoveryResult,
+			localDisks []unstructured.Unstructured, expectedPath, expectedReason string) {
+			devicePath, reason := CheckWipeRequest(&spec, results, localDisks)
+			Expect(devicePath).To(Equal(expectedPath))
+			Expect(reason).To(Equal(expectedReason))
+		},
+		Entry("unused LUN with a signature",
+			spec,
+			[]fusionv1alpha1.LocalVolumeDiscoveryResult{newResult("worker-0", nil, withSignature("/dev/dm-1"))},
+			nil, "/dev/dm-1", ""),
+		Entry("unused free LUN",
+			spec,
+			[]fusionv1alpha1.LocalVolumeDiscoveryResult{
+				newResult("worker-0", []fusionv1alpha1.DiscoveredDevice{{Path: "/dev/sdb", WWN: wwn}}, nil),
+			},
+			nil, "/dev/sdb", ""),
+		Entry("wrong confirmation",
+			fusionv1alpha1.DeviceWipeRequestSpec{WWN: wwn, NodeName: "worker-0", ConfirmWWN: "0x600b"},
+			[]fusionv1alpha1.LocalVolumeDiscoveryResult{newResult("worker-0", nil, withSignature("/dev/dm-1"))},
+			nil, "", "confirmWWN does not match the WWN"),
+		Entry("LUN not seen by the node",
+			spec,
+			[]fusionv1alpha1.LocalVolumeDiscoveryResult{newResult("worker-1", nil, withSignature("/dev/dm-1"))},
+			nil, "", "node worker-0 did not discover the LUN"),
+		Entry("LUN mounted on another node",
+			spec,
+			[]fusionv1alpha1.LocalVolumeDiscoveryResult{
+				newResult("worker-0", nil, withSignature("/dev/dm-1")),
+				newResult("worker-1", nil, mounted),
+			},
+			nil, "", `LUN is mounted on node worker-1: device mounted on "/var/data"`),
+		Entry("LUN used by a LocalDisk of another node",
+			spec,
+			[]fusionv1alpha1.LocalVolumeDiscoveryResult{
+				newResult("worker-0", nil, withSignature("/dev/dm-1")),
+				newResult("worker-1", nil, withSignature("/dev/dm-4")),
+			},
+			[]unstructured.Unstructured{newLocalDisk("nsd1", "worker-0", "/dev/dm-2"), newLocalDisk("nsd2", "worker-1", "/dev/dm-4")},
+			"", "LUN is used by LocalDisk nsd2"),
+		Entry("LUN used by a LocalDisk named after it",
+			spec,
+			[]fusionv1alpha1.LocalVolumeDiscoveryResult{newResult("worker-0", nil, withSignature("/dev/dm-1"))},
+			[]unstructured.Unstructured{newLocalDisk("dm-7-"+wwn, "worker-2", "/dev/dm-7")},
+			"", "LUN is used by LocalDisk dm-7-"+wwn),
+	)
+})
+
+var _ = Describe("DeviceWipeRequestReconciler", func() {
+	newReconciler := func(objects ...runtime.Object) (*DeviceWipeRequestReconciler, *fusionv1alpha1.DeviceWipeRequest) {
+		scheme := runtime.NewScheme()
+		Expect(fusionv1alpha1.AddToScheme(scheme)).To(Succeed())
+		wipeRequest := &fusionv1alpha1.DeviceWipeRequest{
+			ObjectMeta: metav1.ObjectMeta{Name: "reuse-lun"},
+			Spec:       fusionv1alpha1.DeviceWipeRequestSpec{WWN: wwn, NodeName: "worker-0", ConfirmWWN: wwn},
+		}
+		cl := fake.NewClientBuilder().
+			WithScheme(scheme).
+			WithRuntimeObjects(append(objects, wipeRequest)...).
+			WithStatusSubresource(&fusionv1alpha1.DeviceWipeRequest{}).
+			Build()
+		return &DeviceWipeRequestReconciler{Client: cl, Scheme: scheme}, wipeRequest
+	}
+
+	reconcileRequest := func(reconciler *DeviceWipeRequestReconciler, wipeRequest *fusionv1alpha1.DeviceWipeRequest) {
+		request := reconcile.Request{NamespacedName: types.NamespacedName{Name: wipeRequest.Name}}
+		_, err := reconciler.Reconcile(context.Background(), request)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(reconciler.Client.Get(context.Background(), request.NamespacedName, wipeRequest)).To(Succeed())
+	}
+
+	It("approves the request of an unused LUN", func() {
+		result := newResult("worker-0", nil, withSignature("/dev/dm-1"))
+		reconciler, wipeRequest := newReconciler(&result)
+
+		reconcileRequest(reconciler, wipeRequest)
+		Expect(wipeRequest.Status.Phase).To(Equal(fusionv1alpha1.DeviceWipeApproved))
+		Expect(wipeRequest.Status.DevicePath).To(Equal("/dev/dm-1"))
+		Expect(wipeRequest.Status.ApprovalTime).ToNot(BeNil())
+	})
+
+	It("rejects the request of a LUN the node does not see", func() {
+		reconciler, wipeRequest := newReconciler()
+
+		reconcileRequest(reconciler, wipeRequest)
+		Expect(wipeRequest.Status.Phase).To(Equal(fusionv1alpha1.DeviceWipeRejected))
+		Expect(wipeRequest.Status.Message).To(Equal("node worker-0 did not discover the LUN"))
+		Expect(wipeRequest.Status.ApprovalTime).To(BeNil())
+	})
+
+	It("does not check a request again", func() {
+		result := newResult("worker-0", nil, withSignature("/dev/dm-1"))
+		reconciler, wipeRequest := newReconciler(&result)
+		reconcileRequest(reconciler, wipeRequest)
+		wipeRequest.Status.Phase = fusionv1alpha1.DeviceWipeCompleted
+		Expect(reconciler.Client.Status().Update(context.Background(), wipeRequest)).To(Succeed())
+
+		reconcileRequest(reconciler, wipeRequest)
+		Expect(wipeRequest.Status.Phase).To(Equal(fusionv1alpha1.DeviceWipeCompleted))
+	})
+})
@@ -0,0 +1,14 @@
+package devicewipe
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestDeviceWipe(t *testing.T) {
+	RegisterFailHandler(Fail)
+
+	RunSpecs(t, "DeviceWipe Suite")
+}
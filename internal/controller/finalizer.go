@@ -0,0 +1,200 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/api/meta"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	fusionv1alpha1 "github.com/openshift-storage-scale/openshift-fusion-access-operator/api/v1alpha1"
+	"github.com/openshift-storage-scale/openshift-fusion-access-operator/internal/common"
+	"github.com/openshift-storage-scale/openshift-fusion-access-operator/internal/controller/console"
+	"github.com/openshift-storage-scale/openshift-fusion-access-operator/internal/controller/kernelmodule"
+	"github.com/openshift-storage-scale/openshift-fusion-access-operator/internal/controller/localvolumediscovery"
+	"github.com/openshift-storage-scale/openshift-fusion-access-operator/internal/controller/sharedluninventory"
+)
+
+const (
+	storageScaleFinalizer = "fusion.storage.openshift.io/finalizer"
+	// How often we check again while waiting on other operators to clean up
+	teardownRequeueInterval = 15 * time.Second
+
+	// StorageScaleNamespace is where the IBM Storage Scale operator runs the core daemons
+	StorageScaleNamespace = common.StorageScaleNamespace
+	// The core daemon pods have this label set by the IBM Storage Scale operator
+	storageScaleCoreLabelKey   = "app.kubernetes.io/name"
+	storageScaleCoreLabelValue = "core"
+
+	// Condition types reported while tearing down
+	ConditionDeviceDiscoveryRemoved    = "DeviceDiscoveryRemoved"
+	ConditionSharedLUNInventoryRemoved = "SharedLUNInventoryRemoved"
+	ConditionConsolePluginRemoved      = "ConsolePluginRemoved"
+	ConditionStorageScaleDaemonsGone   = "StorageScaleDaemonsRemoved"
+	ConditionKernelModuleRemoved       = "KernelModuleRemoved"
+	ConditionRegistrySecretRemoved     = "RegistrySecretRemoved"
+	ConditionEntitlementSecretsRemoved = "EntitlementSecretsRemoved"
+
+	ReasonTeardownCompleted = "TeardownCompleted"
+	ReasonTeardownPending   = "TeardownPending"
+	ReasonTeardownFailed    = "TeardownFailed"
+)
+
+// teardownStep is a single step of the finalizer. run returns true when the step is done,
+// false when we need to wait (e.g. for another operator to finish its own cleanup)
+type teardownStep struct {
+	conditionType string
+	doneMessage   string
+	run           func(ctx context.Context, cl client.Client, ns string) (bool, string, error)
+}
+
+// teardownSteps returns the ordered list of steps needed to remove everything we created
+// outside of OLM. The order matters:
+// - the shared LUN inventory is only removed once the discovery results it is rebuilt from are gone
+// - the console plugin is disabled in the console before the ConsolePlugin is deleted
+// - the kernel module is only unloaded once the Storage Scale daemons are gone
+// - the secrets are only removed once KMM does not need them anymore to unload the module
+func teardownSteps() []teardownStep {
+	return []teardownStep{
+		{
+			conditionType: ConditionDeviceDiscoveryRemoved,
+			doneMessage:   "Device discovery was removed",
+			run: func(ctx context.Context, cl client.Client, ns string) (bool, string, error) {
+				return true, "", localvolumediscovery.DeleteLocalVolumeDiscovery(ctx, ns, cl)
+			},
+		},
+		{
+			conditionType: ConditionSharedLUNInventoryRemoved,
+			doneMessage:   "Shared LUN inventory was removed",
+			run: func(ctx context.Context, cl client.Client, ns string) (bool, string, error) {
+				results := &fusionv1alpha1.LocalVolumeDiscoveryResultList{}
+				if err := cl.List(ctx, results, client.InNamespace(ns)); err != nil {
+					return false, "", fmt.Errorf("failed to list LocalVolumeDiscoveryResults: %w", err)
+				}
+				if len(results.Items) > 0 {
+					return false, fmt.Sprintf("Waiting for %d discovery result(s) to be removed", len(results.Items)), nil
+				}
+				return true, "", sharedluninventory.DeleteSharedLUNInventory(ctx, cl)
+			},
+		},
+		{
+			conditionType: ConditionConsolePluginRemoved,
+			doneMessage:   "Console plugin was disabled and removed",
+			run: func(ctx context.Context, cl client.Client, _ string) (bool, string, error) {
+				if err := console.DisablePlugin(ctx, cl); err != nil {
+					return false, "", err
+				}
+				return true, "", console.DeletePlugin(ctx, cl)
+			},
+		},
+		{
+			conditionType: ConditionStorageScaleDaemonsGone,
+			doneMessage:   "No Storage Scale daemons are running",
+			run: func(ctx context.Context, cl client.Client, _ string) (bool, string, error) {
+				running, err := countStorageScaleDaemons(ctx, cl)
+				if err != nil {
+					return false, "", err
+				}
+				if running > 0 {
+					return false, fmt.Sprintf("Waiting for %d Storage Scale daemon pod(s) in %s to be removed", running, StorageScaleNamespace), nil
+				}
+				return true, "", nil
+			},
+		},
+		{
+			conditionType: ConditionKernelModuleRemoved,
+			doneMessage:   "Kernel module was unloaded and removed",
+			run: func(ctx context.Context, cl client.Client, ns string) (bool, string, error) {
+				if err := kernelmodule.DeleteKMMPreflight(ctx, cl); err != nil {
+					return false, "", err
+				}
+				if err := kernelmodule.DeleteKMMPrebuild(ctx, cl, ns); err != nil {
+					return false, "", err
+				}
+				gone, err := kernelmodule.DeleteKMMModule(ctx, cl, ns)
+				if err != nil || gone {
+					return gone, "", err
+				}
+				return false, fmt.Sprintf("Waiting for KMM to unload and remove the %s modules", kernelmodule.KMMModuleName), nil
+			},
+		},
+		{
+			conditionType: ConditionRegistrySecretRemoved,
+			doneMessage:   "KMM registry secret was removed",
+			run: func(ctx context.Context, cl client.Client, ns string) (bool, string, error) {
+				return true, "", kernelmodule.DeleteKMMRegistrySecret(ctx, cl, ns)
+			},
+		},
+		{
+			conditionType: ConditionEntitlementSecretsRemoved,
+			doneMessage:   "Entitlement secrets were removed",
+			run: func(ctx context.Context, cl client.Client, ns string) (bool, string, error) {
+				return true, "", deleteEntitlementPullSecrets(ctx, cl, ns)
+			},
+		},
+	}
+}
+
+// finalizeFusionAccess runs the teardown steps in order and reports each of them as a condition.
+// It returns true once all the steps are done and the finalizer can be removed
+func (r *FusionAccessReconciler) finalizeFusionAccess(ctx context.Context, fusionaccess *fusionv1alpha1.FusionAccess, ns string) (bool, error) {
+	fusionaccess.Status.Status = fusionv1alpha1.PhaseDeleting
+	for _, step := range teardownSteps() {
+		done, message, err := step.run(ctx, r.Client, ns)
+		condition := v1.Condition{Type: step.conditionType, Status: v1.ConditionTrue, Reason: ReasonTeardownCompleted, Message: step.doneMessage}
+		switch {
+		case err != nil:
+			log.Log.Error(err, "Teardown step failed", "step", step.conditionType)
+			condition.Status = v1.ConditionFalse
+			condition.Reason = ReasonTeardownFailed
+			condition.Message = err.Error()
+		case !done:
+			log.Log.Info(message, "step", step.conditionType)
+			condition.Status = v1.ConditionFalse
+			condition.Reason = ReasonTeardownPending
+			condition.Message = message
+		}
+		meta.SetStatusCondition(&fusionaccess.Status.Conditions, condition)
+		if serr := r.Status().Update(ctx, fusionaccess); serr != nil {
+			return false, errors.Join(serr, err)
+		}
+		if err != nil || !done {
+			return false, err
+		}
+	}
+	log.Log.Info("Successfully finalized FusionAccess")
+	return true, nil
+}
+
+// countStorageScaleDaemons returns the number of Storage Scale core pods still present.
+// As long as those exist the kernel module is in use and must not be unloaded
+func countStorageScaleDaemons(ctx context.Context, cl client.Client) (int, error) {
+	pods := &corev1.PodList{}
+	if err := cl.List(ctx, pods,
+		client.InNamespace(StorageScaleNamespace),
+		client.MatchingLabels{storageScaleCoreLabelKey: storageScaleCoreLabelValue}); err != nil {
+		return 0, fmt.Errorf("failed to list Storage Scale daemons in countStorageScaleDaemons: %w", err)
+	}
+	return len(pods.Items), nil
+}
@@ -0,0 +1,185 @@
+package controller
+
+import (
+	"context"
+	"os"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	consolev1 "github.com/openshift/api/console/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	kmmv1beta1 "github.com/rh-ecosystem-edge/kernel-module-management/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	fusionv1alpha "github.com/openshift-storage-scale/openshift-fusion-access-operator/api/v1alpha1"
+	"github.com/openshift-storage-scale/openshift-fusion-access-operator/internal/controller/console"
+	"github.com/openshift-storage-scale/openshift-fusion-access-operator/internal/controller/kernelmodule"
+	"github.com/openshift-storage-scale/openshift-fusion-access-operator/internal/controller/localvolumediscovery"
+)
+
+var _ = Describe("FusionAccess finalizer", func() {
+	const ns = "ibm-fusion-access-operator"
+
+	var (
+		ctx        = context.Background()
+		scheme     = createFakeScheme()
+		reconciler *FusionAccessReconciler
+		cl         client.Client
+		fa         *fusionv1alpha.FusionAccess
+		objects    []client.Object
+	)
+
+	newDeletedFusionAccess := func() *fusionv1alpha.FusionAccess {
+		now := metav1.Now()
+		return &fusionv1alpha.FusionAccess{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "fusionaccess",
+				Namespace:         ns,
+				Finalizers:        []string{storageScaleFinalizer},
+				DeletionTimestamp: &now,
+			},
+		}
+	}
+
+	corePod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "compute-0",
+			Namespace: StorageScaleNamespace,
+			Labels:    map[string]string{storageScaleCoreLabelKey: storageScaleCoreLabelValue},
+		},
+	}
+
+	reconcileOnce := func() (reconcile.Result, error) {
+		return reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(fa)})
+	}
+
+	getCondition := func(conditionType string) *metav1.Condition {
+		updated := &fusionv1alpha.FusionAccess{}
+		Expect(cl.Get(ctx, client.ObjectKeyFromObject(fa), updated)).To(Succeed())
+		return meta.FindStatusCondition(updated.Status.Conditions, conditionType)
+	}
+
+	BeforeEach(func() {
+		os.Setenv("DEPLOYMENT_NAMESPACE", ns)
+		fa = newDeletedFusionAccess()
+		objects = []client.Object{
+			fa,
+			localvolumediscovery.NewLocalVolumeDiscovery(ns),
+			&consolev1.ConsolePlugin{ObjectMeta: metav1.ObjectMeta{Name: console.PluginName}},
+			&operatorv1.Console{
+				ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+				Spec:       operatorv1.ConsoleSpec{Plugins: []string{"other-plugin", console.PluginName}},
+			},
+			&kmmv1beta1.Module{ObjectMeta: metav1.ObjectMeta{Name: kernelmodule.KMMModuleName, Namespace: ns}},
+			&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: kernelmodule.KMMRegistryPushPullSecretName, Namespace: ns}},
+			&fusionv1alpha.SharedLUNInventory{ObjectMeta: metav1.ObjectMeta{Name: fusionv1alpha.SharedLUNInventoryName}},
+		}
+		for _, entitlementNs := range IbmEntitlementSecrets(ns) {
+			objects = append(objects, &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: IBMENTITLEMENTNAME, Namespace: entitlementNs}})
+		}
+	})
+
+	AfterEach(func() {
+		os.Unsetenv("DEPLOYMENT_NAMESPACE")
+	})
+
+	buildReconciler := func(extra ...client.Object) {
+		cl = fake.NewClientBuilder().
+			WithScheme(scheme).
+			WithObjects(append(objects, extra...)...).
+			WithStatusSubresource(&fusionv1alpha.FusionAccess{}).
+			Build()
+		reconciler = &FusionAccessReconciler{Client: cl, Scheme: scheme}
+	}
+
+	It("adds the finalizer to a new FusionAccess", func() {
+		fa = &fusionv1alpha.FusionAccess{ObjectMeta: metav1.ObjectMeta{Name: "fusionaccess", Namespace: ns}}
+		objects = []client.Object{fa}
+		buildReconciler()
+
+		// The reconcile fails later on since there is no manifest, but the finalizer is already set
+		_, _ = reconcileOnce()
+		updated := &fusionv1alpha.FusionAccess{}
+		Expect(cl.Get(ctx, client.ObjectKeyFromObject(fa), updated)).To(Succeed())
+		Expect(controllerutil.ContainsFinalizer(updated, storageScaleFinalizer)).To(BeTrue())
+	})
+
+	It("waits for the Storage Scale daemons before removing the kernel module", func() {
+		buildReconciler(corePod)
+
+		result, err := reconcileOnce()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(teardownRequeueInterval))
+
+		By("removing device discovery and the console plugin")
+		err = cl.Get(ctx, client.ObjectKeyFromObject(localvolumediscovery.NewLocalVolumeDiscovery(ns)), &fusionv1alpha.LocalVolumeDiscovery{})
+		Expect(kerrors.IsNotFound(err)).To(BeTrue())
+		err = cl.Get(ctx, client.ObjectKey{Name: console.PluginName}, &consolev1.ConsolePlugin{})
+		Expect(kerrors.IsNotFound(err)).To(BeTrue())
+		consoleObj := &operatorv1.Console{}
+		Expect(cl.Get(ctx, client.ObjectKey{Name: "cluster"}, consoleObj)).To(Succeed())
+		Expect(consoleObj.Spec.Plugins).To(Equal([]string{"other-plugin"}))
+		Expect(getCondition(ConditionConsolePluginRemoved).Status).To(Equal(metav1.ConditionTrue))
+
+		By("keeping the kernel module and the secrets")
+		cond := getCondition(ConditionStorageScaleDaemonsGone)
+		Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+		Expect(cond.Reason).To(Equal(ReasonTeardownPending))
+		Expect(getCondition(ConditionKernelModuleRemoved)).To(BeNil())
+		Expect(cl.Get(ctx, client.ObjectKey{Name: kernelmodule.KMMModuleName, Namespace: ns}, &kmmv1beta1.Module{})).To(Succeed())
+		Expect(cl.Get(ctx, client.ObjectKey{Name: IBMENTITLEMENTNAME, Namespace: StorageScaleNamespace}, &corev1.Secret{})).To(Succeed())
+
+		updated := &fusionv1alpha.FusionAccess{}
+		Expect(cl.Get(ctx, client.ObjectKeyFromObject(fa), updated)).To(Succeed())
+		Expect(controllerutil.ContainsFinalizer(updated, storageScaleFinalizer)).To(BeTrue())
+	})
+
+	It("keeps the shared LUN inventory until the discovery results are garbage collected", func() {
+		buildReconciler(&fusionv1alpha.LocalVolumeDiscoveryResult{
+			ObjectMeta: metav1.ObjectMeta{Name: "discovery-result-worker-0", Namespace: ns},
+		})
+
+		result, err := reconcileOnce()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(teardownRequeueInterval))
+		cond := getCondition(ConditionSharedLUNInventoryRemoved)
+		Expect(cond.Reason).To(Equal(ReasonTeardownPending))
+		Expect(cond.Message).To(Equal("Waiting for 1 discovery result(s) to be removed"))
+		Expect(cl.Get(ctx, client.ObjectKey{Name: fusionv1alpha.SharedLUNInventoryName}, &fusionv1alpha.SharedLUNInventory{})).To(Succeed())
+	})
+
+	It("removes everything and the finalizer once the daemons are gone", func() {
+		buildReconciler()
+
+		By("waiting for KMM to remove the module")
+		result, err := reconcileOnce()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(teardownRequeueInterval))
+		Expect(getCondition(ConditionStorageScaleDaemonsGone).Status).To(Equal(metav1.ConditionTrue))
+		Expect(getCondition(ConditionKernelModuleRemoved).Reason).To(Equal(ReasonTeardownPending))
+
+		By("removing the secrets and the finalizer")
+		result, err = reconcileOnce()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.RequeueAfter).To(BeZero())
+
+		err = cl.Get(ctx, client.ObjectKey{Name: kernelmodule.KMMRegistryPushPullSecretName, Namespace: ns}, &corev1.Secret{})
+		Expect(kerrors.IsNotFound(err)).To(BeTrue())
+		err = cl.Get(ctx, client.ObjectKey{Name: fusionv1alpha.SharedLUNInventoryName}, &fusionv1alpha.SharedLUNInventory{})
+		Expect(kerrors.IsNotFound(err)).To(BeTrue())
+		for _, entitlementNs := range IbmEntitlementSecrets(ns) {
+			err = cl.Get(ctx, client.ObjectKey{Name: IBMENTITLEMENTNAME, Namespace: entitlementNs}, &corev1.Secret{})
+			Expect(kerrors.IsNotFound(err)).To(BeTrue())
+		}
+		// With the finalizer gone the object is deleted
+		err = cl.Get(ctx, client.ObjectKeyFromObject(fa), &fusionv1alpha.FusionAccess{})
+		Expect(kerrors.IsNotFound(err)).To(BeTrue())
+	})
+})
@@ -18,21 +18,28 @@ package controller
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"reflect"
+	"slices"
+	"strings"
+	"sync"
 
 	mfc "github.com/manifestival/controller-runtime-client"
 	"github.com/manifestival/manifestival"
+	configv1 "github.com/openshift/api/config/v1"
+	kmmv1beta1 "github.com/rh-ecosystem-edge/kernel-module-management/api/v1beta1"
+	kmmv1beta2 "github.com/rh-ecosystem-edge/kernel-module-management/api/v1beta2"
 	corev1 "k8s.io/api/core/v1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
-
-	meta "k8s.io/apimachinery/pkg/api/meta"
-	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
@@ -55,6 +62,15 @@ type FusionAccessReconciler struct {
 	Scheme *runtime.Scheme
 	// Need this for mocking when needed
 	CanPullImage CanPullImageFunc
+
+	// Used to add the watches on resources whose CRDs are installed by the manifest
+	controller            controller.Controller
+	cache                 cache.Cache
+	localDiskWatchLock    sync.Mutex
+	localDiskWatchStarted bool
+
+	// Recorder records the events of the kernel module on the FusionAccess
+	Recorder record.EventRecorder
 }
 
 func NewFusionAccessReconciler(
@@ -68,8 +84,6 @@ func NewFusionAccessReconciler(
 	}
 }
 
-// const storageScaleFinalizer = "fusion.storage.openshift.io/finalizer"
-
 // Basic Operator RBACs
 //+kubebuilder:rbac:groups=fusion.storage.openshift.io,resources=fusionaccesses,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=fusion.storage.openshift.io,resources=fusionaccesses/status,verbs=get;update;patch
@@ -281,44 +295,56 @@ func (r *FusionAccessReconciler) Reconcile(
 		return ctrl.Result{}, err
 	}
 
-	// // Check if the FusionAccess instance is marked to be deleted, which is
-	// // indicated by the deletion timestamp being set.
-	// isFusionAccessMarkedToBeDeleted := fusionaccess.GetDeletionTimestamp() != nil
-	// if isFusionAccessMarkedToBeDeleted {
-	// 	if controllerutil.ContainsFinalizer(fusionaccess, storageScaleFinalizer) {
-	// 		// Run finalization logic for storageScaleFinalizer. If the
-	// 		// finalization logic fails, don't remove the finalizer so
-	// 		// that we can retry during the next reconciliation.
-	// 		if err := r.finalizeFusionAccess(log.Log, fusionaccess); err != nil {
-	// 			return ctrl.Result{}, err
-	// 		}
-
-	// 		// Remove memcachedFinalizer. Once all finalizers have been
-	// 		// removed, the object will be deleted.
-	// 		controllerutil.RemoveFinalizer(fusionaccess, storageScaleFinalizer)
-	// 		err := r.Update(ctx, fusionaccess)
-	// 		if err != nil {
-	// 			return ctrl.Result{}, err
-	// 		}
-	// 	}
-	// 	return ctrl.Result{}, nil
-	// }
-	// // Add finalizer for this CR
-	// if !controllerutil.ContainsFinalizer(fusionaccess, storageScaleFinalizer) {
-	// 	controllerutil.AddFinalizer(fusionaccess, storageScaleFinalizer)
-	// 	err = r.Update(ctx, fusionaccess)
-	// 	if err != nil {
-	// 		return ctrl.Result{}, err
-	// 	}
-	// }
 	ns, err := utils.GetDeploymentNamespace()
 	if err != nil {
 		return ctrl.Result{}, err
 	}
 
+	// Check if the FusionAccess instance is marked to be deleted, which is
+	// indicated by the deletion timestamp being set.
+	if !fusionaccess.GetDeletionTimestamp().IsZero() {
+		if controllerutil.ContainsFinalizer(fusionaccess, storageScaleFinalizer) {
+			// Run finalization logic for storageScaleFinalizer. If the
+			// finalization logic fails or is still waiting on other resources,
+			// don't remove the finalizer so that we can retry later.
+			done, err := r.finalizeFusionAccess(ctx, fusionaccess, ns)
+			if err != nil {
+				return ctrl.Result{}, err
+			}
+			if !done {
+				return ctrl.Result{RequeueAfter: teardownRequeueInterval}, nil
+			}
+
+			// Once all finalizers have been removed, the object will be deleted.
+			controllerutil.RemoveFinalizer(fusionaccess, storageScaleFinalizer)
+			if err := r.Update(ctx, fusionaccess); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+		return ctrl.Result{}, nil
+	}
+	// Add finalizer for this CR
+	if !controllerutil.ContainsFinalizer(fusionaccess, storageScaleFinalizer) {
+		controllerutil.AddFinalizer(fusionaccess, storageScaleFinalizer)
+		if err := r.Update(ctx, fusionaccess); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	// The deprecated kmm-image-config ConfigMap is moved to spec.kernelModule once
+	migrated, err := kernelmodule.MigrateKMMImageConfig(ctx, r.Client, ns, fusionaccess)
+	if err != nil {
+		return ctrl.Result{}, r.failComponent(ctx, fusionaccess, ConditionKernelModuleReady, err)
+	}
+	if migrated {
+		if err := r.Update(ctx, fusionaccess); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
 	install_path, err := getIbmManifest(fusionaccess.Spec)
 	if err != nil {
-		return ctrl.Result{}, err
+		return ctrl.Result{}, r.failComponent(ctx, fusionaccess, ConditionManifestApplied, err)
 	}
 
 	installManifest, err := manifestival.NewManifest(
@@ -326,26 +352,38 @@ func (r *FusionAccessReconciler) Reconcile(
 		manifestival.UseClient(mfc.NewClient(r.Client)),
 	)
 	if err != nil {
-		return ctrl.Result{}, err
+		return ctrl.Result{}, r.failComponent(ctx, fusionaccess, ConditionManifestApplied, err)
+	}
+	// When the Storage Scale version changes we only apply the new manifest once
+	// the new images can be pulled and the new kernel modules are built
+	upgrading := isUpgrading(fusionaccess)
+	if upgrading {
+		ready, err := r.runStagedUpgrade(ctx, ns, fusionaccess, installManifest)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if !ready {
+			return ctrl.Result{RequeueAfter: upgradeRequeueInterval}, nil
+		}
 	}
 	log.Log.Info(fmt.Sprintf("Applying manifest from %s", install_path))
 
 	if err := installManifest.Apply(); err != nil {
 		log.Log.Error(err, "Error applying manifest")
-		fusionaccess.Status.Status = "Error"
-		meta.SetStatusCondition(&fusionaccess.Status.Conditions,
-			v1.Condition{Type: "ManifestApply", Status: v1.ConditionFalse, Reason: "ReconcileCompleted", Message: "Storage Scale manifest apply failed"})
-		serr := r.Status().Update(ctx, fusionaccess)
-		if serr != nil {
-			return ctrl.Result{}, errors.Join(serr, err)
+		if upgrading {
+			return ctrl.Result{}, r.failUpgrade(ctx, fusionaccess, fmt.Errorf("failed to apply the manifest: %w", err))
 		}
-		return ctrl.Result{}, err
+		return ctrl.Result{}, r.failComponent(ctx, fusionaccess, ConditionManifestApplied, fmt.Errorf("Storage Scale manifest apply failed: %w", err))
 	}
 	log.Log.Info(fmt.Sprintf("Applied manifest from %s", install_path))
-	meta.SetStatusCondition(&fusionaccess.Status.Conditions,
-		v1.Condition{Type: "ManifestApply", Status: v1.ConditionTrue, Reason: "ReconcileCompleted", Message: "Storage Scale manifest was applied"})
-	serr := r.Status().Update(ctx, fusionaccess)
-	if serr != nil {
+	if upgrading {
+		if err := r.completeUpgrade(ctx, ns, fusionaccess); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	fusionaccess.Status.InstalledStorageScaleVersion = string(fusionaccess.Spec.StorageScaleVersion)
+	setComponentCondition(fusionaccess, ConditionManifestApplied, ReasonSucceeded, "Storage Scale manifest was applied")
+	if serr := r.updateStatus(ctx, fusionaccess); serr != nil {
 		return ctrl.Result{}, serr
 	}
 
@@ -357,98 +395,114 @@ func (r *FusionAccessReconciler) Reconcile(
 		log.Log.Info(
 			"Pull secret not found, skipping entitlement secret creation, we will watch this secret",
 		)
+		notFound := fmt.Sprintf("Secret %s not found, we assume the global pull secret contains the IBM entitlement", FUSIONPULLSECRETNAME)
+		setComponentCondition(fusionaccess, ConditionEntitlementSecretsSynced, ReasonNotRequired, notFound)
+		setComponentCondition(fusionaccess, ConditionRegistryValid, ReasonNotRequired, notFound)
+		setComponentCondition(fusionaccess, ConditionKernelModuleReady, ReasonNotRequired,
+			fmt.Sprintf("The kernel module is only built once secret %s exists", FUSIONPULLSECRETNAME))
 	} else {
 		// Create entitlement secrets
 		err = updateEntitlementPullSecrets(secret, ctx, r.Client, ns)
 		if err != nil {
 			log.Log.Error(err, "Error creating entitlement secrets")
-			return reconcile.Result{}, err
+			return reconcile.Result{}, r.failComponent(ctx, fusionaccess, ConditionEntitlementSecretsSynced, err)
 		}
 		log.Log.Info("Entitlement secrets created")
+		setComponentCondition(fusionaccess, ConditionEntitlementSecretsSynced, ReasonSucceeded, "Entitlement secrets are in sync with "+FUSIONPULLSECRETNAME)
 
 		// Check if we're using the internal image registry and validate its storage configuration
 		usingInternalRegistry, err := imageregistry.IsUsingInternalImageRegistry(ctx, r.Client, ns)
 		if err != nil {
 			log.Log.Error(err, "Failed to check if using internal image registry")
-			return ctrl.Result{}, err
+			return ctrl.Result{}, r.failComponent(ctx, fusionaccess, ConditionRegistryValid, err)
 		}
 
 		if usingInternalRegistry {
 			log.Log.Info("Using internal image registry, validating storage configuration")
 			if err := imageregistry.CheckImageRegistryStorage(ctx, r.Client); err != nil {
-				fusionaccess.Status.Status = "Error"
-				meta.SetStatusCondition(&fusionaccess.Status.Conditions,
-					v1.Condition{Type: "ImageRegistryStorage", Status: v1.ConditionFalse, Reason: "InvalidStorageBackend", Message: err.Error()})
-				serr := r.Status().Update(ctx, fusionaccess)
-				if serr != nil {
-					return ctrl.Result{}, errors.Join(serr, err)
-				}
-				return ctrl.Result{}, err
+				return ctrl.Result{}, r.failComponent(ctx, fusionaccess, ConditionRegistryValid, err)
 			}
 			log.Log.Info("Image registry storage validation passed")
-			meta.SetStatusCondition(&fusionaccess.Status.Conditions,
-				v1.Condition{Type: "ImageRegistryStorage", Status: v1.ConditionTrue, Reason: "ValidStorageBackend", Message: "Image registry is using a supported storage backend"})
-			serr := r.Status().Update(ctx, fusionaccess)
-			if serr != nil {
-				return ctrl.Result{}, serr
-			}
+			setComponentCondition(fusionaccess, ConditionRegistryValid, ReasonSucceeded, "Image registry is using a supported storage backend")
 		} else {
 			log.Log.Info("Using external image registry, skipping storage validation")
+			setComponentCondition(fusionaccess, ConditionRegistryValid, ReasonSucceeded, "Using an external image registry")
+		}
+		if serr := r.updateStatus(ctx, fusionaccess); serr != nil {
+			return ctrl.Result{}, serr
 		}
 
 		// Since the kernel module requires the pull secret, we only create that if the secret is found
 		log.Log.Info("Creating kernel module resources")
 		if err := kernelmodule.CreateOrUpdateKMMResources(ctx, r.Client); err != nil {
-			return ctrl.Result{}, err
+			return ctrl.Result{}, r.failComponent(ctx, fusionaccess, ConditionKernelModuleReady, err)
 		}
-
 		log.Log.Info("Successfully created kernel module resources")
+		if err := r.updateKernelModuleCondition(ctx, ns, fusionaccess); err != nil {
+			return ctrl.Result{}, r.failComponent(ctx, fusionaccess, ConditionKernelModuleReady, err)
+		}
 	}
 	if err := console.CreateOrUpdatePlugin(ctx, r.Client); err != nil {
-		return ctrl.Result{}, err
+		return ctrl.Result{}, r.failComponent(ctx, fusionaccess, ConditionConsolePluginEnabled, err)
 	}
 	log.Log.Info("Successfully created / updated console plugin resources")
 
 	if err := console.EnablePlugin(ctx, r.Client); err != nil {
-		return ctrl.Result{}, err
+		return ctrl.Result{}, r.failComponent(ctx, fusionaccess, ConditionConsolePluginEnabled, err)
 	}
 	log.Log.Info("Successfully enabled console plugin")
+	setComponentCondition(fusionaccess, ConditionConsolePluginEnabled, ReasonSucceeded, "Console plugin is enabled")
 
 	// Check if can pull the image if we have not already or if it failed previously
 	// Only do this check if we have a set cnsa version
 	if fusionaccess.Spec.StorageScaleVersion != "" {
 		err = r.runPullImageCheck(ctx, ns, fusionaccess)
 		if err != nil {
-			fusionaccess.Status.Status = "ErrImagePull"
-			meta.SetStatusCondition(&fusionaccess.Status.Conditions,
-				v1.Condition{Type: "ImagePull", Status: v1.ConditionFalse, Reason: "ImagePullDone", Message: "protected images can't be pulled"})
-			serr := r.Status().Update(ctx, fusionaccess)
-			if serr != nil {
-				return ctrl.Result{}, errors.Join(serr, err)
-			}
-			return ctrl.Result{}, err
-		}
-		meta.SetStatusCondition(&fusionaccess.Status.Conditions,
-			v1.Condition{Type: "ImagePull", Status: v1.ConditionTrue, Reason: "ImagePullDone", Message: "protected images pulled successfully"})
-		serr := r.Status().Update(ctx, fusionaccess)
-		if serr != nil {
-			return ctrl.Result{}, serr
+			return ctrl.Result{}, r.failComponent(ctx, fusionaccess, ConditionImagePullVerified,
+				fmt.Errorf("protected images can't be pulled: %w", err))
 		}
+		setComponentCondition(fusionaccess, ConditionImagePullVerified, ReasonSucceeded, "protected images pulled successfully")
 	} else {
 		log.Log.Info("Skipping image pull check as we are not using a Storage Scale version in the spec")
+		setComponentCondition(fusionaccess, ConditionImagePullVerified, ReasonNotRequired,
+			"Image pull check is only run when a Storage Scale version is set")
+	}
+	if serr := r.updateStatus(ctx, fusionaccess); serr != nil {
+		return ctrl.Result{}, serr
 	}
 
 	if fusionaccess.Spec.LocalVolumeDiscovery.Create {
 		// Create Device discovery
 		lvd := localvolumediscovery.NewLocalVolumeDiscovery(ns)
+		lvd.Spec.DeviceFilter = fusionaccess.Spec.LocalVolumeDiscovery.DeviceFilter
 		if err := localvolumediscovery.CreateOrUpdateLocalVolumeDiscovery(ctx, lvd, r.Client); err != nil {
-			return ctrl.Result{}, err
+			return ctrl.Result{}, r.failComponent(ctx, fusionaccess, ConditionDeviceDiscoveryRunning, err)
+		}
+		if err := r.updateDeviceDiscoveryCondition(ctx, lvd, fusionaccess); err != nil {
+			return ctrl.Result{}, r.failComponent(ctx, fusionaccess, ConditionDeviceDiscoveryRunning, err)
 		}
+	} else {
+		setComponentCondition(fusionaccess, ConditionDeviceDiscoveryRunning, ReasonNotRequired, "Device discovery is disabled")
 	}
 
-	fusionaccess.Status.Status = "Ready"
-	err = r.Status().Update(ctx, fusionaccess)
-	if err != nil {
+	if err := r.updateUpgradeable(ctx, ns, fusionaccess); err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := r.updateKernelModuleUpgradeReady(ctx, ns, fusionaccess); err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := r.updateSecureBootCondition(ctx, ns, fusionaccess); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if err := r.watchLocalDisks(); err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := r.updateProvisionedDeviceCount(ctx, fusionaccess); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if err := r.updateStatus(ctx, fusionaccess); err != nil {
 		return ctrl.Result{}, err
 	}
 	return ctrl.Result{}, nil
@@ -456,7 +510,7 @@ func (r *FusionAccessReconciler) Reconcile(
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *FusionAccessReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
+	c, err := ctrl.NewControllerManagedBy(mgr).
 		For(&fusionv1alpha1.FusionAccess{}).
 		Watches(
 			&corev1.Secret{},
@@ -475,7 +529,71 @@ func (r *FusionAccessReconciler) SetupWithManager(mgr ctrl.Manager) error {
 			didTheKmmConfigMapChange(),
 			builder.OnlyMetadata,
 		).
-		Complete(r)
+		// The kernel module Modules follow the architectures of the storage nodes
+		Watches(
+			&corev1.Node{},
+			handler.EnqueueRequestsFromMapFunc(r.fusionAccessHandler),
+			didAStorageNodeChange(),
+			builder.OnlyMetadata,
+		).
+		// The kernel module builds and their loading are reported in the KernelModuleReady condition
+		Watches(
+			&kmmv1beta1.Module{},
+			handler.EnqueueRequestsFromMapFunc(r.fusionAccessHandler),
+			isItOurKMMResource(),
+		).
+		Watches(
+			&kmmv1beta1.ModuleImagesConfig{},
+			handler.EnqueueRequestsFromMapFunc(r.fusionAccessHandler),
+			isItOurKMMResource(),
+		).
+		Watches(
+			&kmmv1beta1.ModuleBuildSignConfig{},
+			handler.EnqueueRequestsFromMapFunc(r.fusionAccessHandler),
+			isItOurKMMResource(),
+		).
+		Watches(
+			&kmmv1beta2.PreflightValidationOCP{},
+			handler.EnqueueRequestsFromMapFunc(r.fusionAccessHandler),
+			isItOurKMMPreflight(),
+		).
+		Watches(
+			&corev1.Pod{},
+			handler.EnqueueRequestsFromMapFunc(r.fusionAccessHandler),
+			didAKMMBuildPodFinish(),
+		).
+		// The Secure Boot state of the nodes is checked against the signing of the kernel modules
+		Watches(
+			&fusionv1alpha1.NodeFacts{},
+			handler.EnqueueRequestsFromMapFunc(r.fusionAccessHandler),
+			didTheSecureBootStateChange(),
+		).
+		// The OpenShift upgrades are checked against the support matrix of the installed Storage Scale
+		Watches(
+			&configv1.ClusterVersion{},
+			handler.EnqueueRequestsFromMapFunc(r.fusionAccessHandler),
+			didTheClusterVersionChange(),
+		).
+		// Device discovery is reported in the FusionAccess conditions
+		Watches(
+			&fusionv1alpha1.LocalVolumeDiscovery{},
+			handler.EnqueueRequestsFromMapFunc(r.fusionAccessHandler),
+			builder.WithPredicates(predicate.Funcs{
+				UpdateFunc: func(e event.UpdateEvent) bool {
+					oldLvd, okOld := e.ObjectOld.(*fusionv1alpha1.LocalVolumeDiscovery)
+					newLvd, okNew := e.ObjectNew.(*fusionv1alpha1.LocalVolumeDiscovery)
+					return okOld && okNew && !reflect.DeepEqual(oldLvd.Status, newLvd.Status)
+				},
+			}),
+		).
+		Build(r)
+	if err != nil {
+		return err
+	}
+	r.controller = c
+	r.cache = mgr.GetCache()
+	r.Recorder = mgr.GetEventRecorderFor("fusionaccess-controller")
+	return nil
 }
 
 func (r *FusionAccessReconciler) fusionAccessHandler(
@@ -593,15 +711,6 @@ func checkPullSecret(secret *corev1.Secret, ns string) bool {
 	return true
 }
 
-// func (r *FusionAccessReconciler) finalizeFusionAccess(reqLogger logr.Logger, sc *v1alpha1.FusionAccess) error {
-// 	// TODO(user): Add the cleanup steps that the operator
-// 	// needs to do before the CR can be deleted. Examples
-// 	// of finalizers include performing backups and deleting
-// 	// resources that are not owned by this CR, like a PVC.
-// 	reqLogger.Info("Successfully finalized FusionAccess")
-// 	return nil
-// }
-
 // returns true if the registry secret has changed
 func didTheRegistrySecretChange(c client.Client) builder.WatchesOption {
 	ns, _ := utils.GetDeploymentNamespace()
@@ -637,7 +746,7 @@ func didTheRegistrySecretChange(c client.Client) builder.WatchesOption {
 }
 
 // Helper func to determine the current registry secret name which is or will be used by the KMM operator
-// It first checks the KMMImageConfigMap for the registry secret name, and if not found, it falls back to the builder dockercfg secret.
+// It first checks the kernel module configuration for the registry secret name, and if not found, it falls back to the builder dockercfg secret.
 // This secret will be watched by the controller to trigger a reconcile when it changes.
 // This is useful for cases where the registry secret is updated or changed either by the user or by virtue of token expiration consequently roted.
 func getCurrentRegistrySecretName(ctx context.Context, c client.Client, ns string) (string, error) {
@@ -654,6 +763,138 @@ func getCurrentRegistrySecretName(ctx context.Context, c client.Client, ns strin
 	}
 }
 
+// isItOurKMMResource returns true for the gpfs-module Modules of our namespace, and for the KMM resources
+// named after them
+func isItOurKMMResource() builder.WatchesOption {
+	isOurs := func(obj client.Object) bool {
+		ns, err := utils.GetDeploymentNamespace()
+		if err != nil || obj == nil {
+			return false
+		}
+		return obj.GetNamespace() == ns && strings.HasPrefix(obj.GetName(), kernelmodule.KMMModuleName)
+	}
+
+	return builder.WithPredicates(predicate.NewPredicateFuncs(isOurs))
+}
+
+// isItOurKMMPreflight returns true for the PreflightValidationOCP we create ahead of an OpenShift upgrade
+func isItOurKMMPreflight() builder.WatchesOption {
+	return builder.WithPredicates(predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		return obj != nil && obj.GetName() == kernelmodule.KMMPreflightName
+	}))
+}
+
+// PodCacheOptions restricts the Pods held by the cache of the manager to the ones the operator reads: the
+// KMM build and sign pods and the image check pod of its own namespace, and the Storage Scale core pods.
+// The KMM build pods are watched, which would otherwise cache every Pod of the cluster
+func PodCacheOptions(namespace string) cache.ByObject {
+	return cache.ByObject{
+		Namespaces: map[string]cache.Config{
+			namespace: {},
+			StorageScaleNamespace: {
+				LabelSelector: labels.SelectorFromSet(labels.Set{storageScaleCoreLabelKey: storageScaleCoreLabelValue}),
+			},
+		},
+	}
+}
+
+// didAKMMBuildPodFinish returns true when a build or sign pod of our Modules succeeds or fails
+func didAKMMBuildPodFinish() builder.WatchesOption {
+	isBuildPod := func(obj client.Object) bool {
+		ns, err := utils.GetDeploymentNamespace()
+		if err != nil || obj == nil || obj.GetNamespace() != ns {
+			return false
+		}
+		podType := obj.GetLabels()[kernelmodule.KMMPodTypeLabel]
+		return (podType == kernelmodule.KMMPodTypeBuild || podType == kernelmodule.KMMPodTypeSign) &&
+			strings.HasPrefix(obj.GetLabels()[kernelmodule.KMMModuleNameLabel], kernelmodule.KMMModuleName)
+	}
+	isFinished := func(obj client.Object) bool {
+		pod, ok := obj.(*corev1.Pod)
+		return ok && (pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed)
+	}
+
+	return builder.WithPredicates(predicate.Funcs{
+		CreateFunc: func(_ event.CreateEvent) bool { return false },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return isBuildPod(e.ObjectNew) && !isFinished(e.ObjectOld) && isFinished(e.ObjectNew)
+		},
+		DeleteFunc:  func(_ event.DeleteEvent) bool { return false },
+		GenericFunc: func(_ event.GenericEvent) bool { return false },
+	})
+}
+
+// didTheSecureBootStateChange returns true when the Secure Boot state of a node is first reported or changes
+func didTheSecureBootStateChange() builder.WatchesOption {
+	secureBoot := func(obj client.Object) fusionv1alpha1.SecureBootState {
+		nodeFacts, ok := obj.(*fusionv1alpha1.NodeFacts)
+		if !ok {
+			return ""
+		}
+		return nodeFacts.Status.SecureBoot
+	}
+
+	return builder.WithPredicates(predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return secureBoot(e.Object) != ""
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return secureBoot(e.ObjectOld) != secureBoot(e.ObjectNew)
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return secureBoot(e.Object) == fusionv1alpha1.SecureBootEnabled
+		},
+		GenericFunc: func(_ event.GenericEvent) bool { return false },
+	})
+}
+
+// didTheClusterVersionChange returns true when the channel, the desired or the current OpenShift version changes
+func didTheClusterVersionChange() builder.WatchesOption {
+	versions := func(obj client.Object) []string {
+		clusterVersion, ok := obj.(*configv1.ClusterVersion)
+		if !ok {
+			return nil
+		}
+		current := ""
+		if version, err := utils.GetCurrentClusterVersion(clusterVersion); err == nil {
+			current = version.String()
+		}
+		return []string{clusterVersion.Spec.Channel, clusterVersion.Status.Desired.Version, current}
+	}
+
+	return builder.WithPredicates(predicate.Funcs{
+		CreateFunc: func(_ event.CreateEvent) bool {
+			return true
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return !slices.Equal(versions(e.ObjectOld), versions(e.ObjectNew))
+		},
+		DeleteFunc:  func(_ event.DeleteEvent) bool { return false },
+		GenericFunc: func(_ event.GenericEvent) bool { return false },
+	})
+}
+
+// didAStorageNodeChange returns true when a storage node is added or removed, or when its architecture changes
+func didAStorageNodeChange() builder.WatchesOption {
+	isStorageNode := func(obj client.Object) bool {
+		return obj != nil && obj.GetLabels()[kernelmodule.KMMNodeSelectorKey] == kernelmodule.KMMNodeSelectorValue
+	}
+
+	return builder.WithPredicates(predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return isStorageNode(e.Object)
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return isStorageNode(e.ObjectOld) != isStorageNode(e.ObjectNew) ||
+				e.ObjectOld.GetLabels()[corev1.LabelArchStable] != e.ObjectNew.GetLabels()[corev1.LabelArchStable]
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return isStorageNode(e.Object)
+		},
+		GenericFunc: func(_ event.GenericEvent) bool { return false },
+	})
+}
+
 // didTheKmmConfigMapChange returns true if the KMM configmap has changed
 func didTheKmmConfigMapChange() builder.WatchesOption {
 	ns, _ := utils.GetDeploymentNamespace()
@@ -27,7 +27,9 @@ import (
 	configv1 "github.com/openshift/api/config/v1"
 	operatorv1 "github.com/openshift/api/operator/v1"
 	corev1 "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -129,6 +131,44 @@ var _ = Describe("FusionAccess Controller", func() {
 			updated := &fusionv1alpha.FusionAccess{}
 			err = k8sClient.Get(ctx, typeNamespacedName, updated)
 			Expect(err).ToNot(HaveOccurred())
+
+			By("deriving the phase from the component conditions")
+			Expect(updated.Status.Status).To(Equal(fusionv1alpha.PhaseReady))
+			Expect(meta.IsStatusConditionTrue(updated.Status.Conditions, ConditionManifestApplied)).To(BeTrue())
+			Expect(meta.IsStatusConditionTrue(updated.Status.Conditions, ConditionConsolePluginEnabled)).To(BeTrue())
+			Expect(meta.IsStatusConditionTrue(updated.Status.Conditions, ConditionImagePullVerified)).To(BeTrue())
+			// There is no fusion-pullsecret and discovery is disabled
+			Expect(meta.FindStatusCondition(updated.Status.Conditions, ConditionEntitlementSecretsSynced).Reason).To(Equal(ReasonNotRequired))
+			Expect(meta.FindStatusCondition(updated.Status.Conditions, ConditionDeviceDiscoveryRunning).Reason).To(Equal(ReasonNotRequired))
+		})
+
+		It("is degraded when the images cannot be pulled", func() {
+			resource := &fusionv1alpha.FusionAccess{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      resourceName,
+					Namespace: "default",
+				},
+				Spec: fusionv1alpha.FusionAccessSpec{StorageScaleVersion: "v5.2.3.1"},
+			}
+			k8sClient = fakeClientBuilder.WithRuntimeObjects(resource).Build()
+			FusionAccessReconciler := &FusionAccessReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+				CanPullImage: func(ctx context.Context, client client.Client, ns, image, pullSecret string) (bool, error) {
+					return false, fmt.Errorf("unauthorized")
+				},
+			}
+
+			_, err := FusionAccessReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).To(HaveOccurred())
+			updated := &fusionv1alpha.FusionAccess{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, updated)).To(Succeed())
+			Expect(updated.Status.Status).To(Equal(fusionv1alpha.PhaseDegraded))
+			cond := meta.FindStatusCondition(updated.Status.Conditions, ConditionImagePullVerified)
+			Expect(cond.Reason).To(Equal(ReasonFailed))
+			Expect(cond.Message).To(ContainSubstring("unauthorized"))
 		})
 	})
 })
@@ -355,3 +395,17 @@ var _ = Describe("getCurrentRegistrySecretName", func() {
 		Expect(err.Error()).To(ContainSubstring("dockercfg secret not found"))
 	})
 })
+
+var _ = Describe("PodCacheOptions", func() {
+	It("only caches the Pods of the operator namespace and the Storage Scale core pods", func() {
+		options := PodCacheOptions("ibm-fusion-access")
+		Expect(options.Namespaces).To(HaveLen(2))
+		Expect(options.Namespaces).To(HaveKey("ibm-fusion-access"))
+		Expect(options.Namespaces["ibm-fusion-access"].LabelSelector).To(BeNil())
+
+		selector := options.Namespaces[StorageScaleNamespace].LabelSelector
+		Expect(selector).ToNot(BeNil())
+		Expect(selector.Matches(labels.Set{"app.kubernetes.io/name": "core"})).To(BeTrue())
+		Expect(selector.Matches(labels.Set{"app.kubernetes.io/name": "gui"})).To(BeFalse())
+	})
+})
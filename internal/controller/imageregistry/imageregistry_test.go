@@ -13,6 +13,7 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
+	fusionv1alpha1 "github.com/openshift-storage-scale/openshift-fusion-access-operator/api/v1alpha1"
 	"github.com/openshift-storage-scale/openshift-fusion-access-operator/internal/controller/kernelmodule"
 )
 
@@ -172,6 +173,7 @@ var _ = Describe("Image Registry Validation", func() {
 		BeforeEach(func() {
 			scheme = runtime.NewScheme()
 			_ = corev1.AddToScheme(scheme)
+			_ = fusionv1alpha1.AddToScheme(scheme)
 			ctx = context.Background()
 		})
 
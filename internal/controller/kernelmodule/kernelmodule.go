@@ -21,9 +21,11 @@ import (
 	"fmt"
 	"reflect"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 
+	"github.com/openshift-storage-scale/openshift-fusion-access-operator/api/v1alpha1"
 	"github.com/openshift-storage-scale/openshift-fusion-access-operator/internal/kubeutils"
 	"github.com/openshift-storage-scale/openshift-fusion-access-operator/internal/utils"
 
@@ -41,12 +43,14 @@ import (
 const (
 	// ServiceAccountName is the name of the service account that will be used for the DS to load the kernel module
 	// this will be the same as the operator service account for now
-	ServiceAccountName                  = "fusion-access-operator-controller-manager"
-	ConfigMapName                       = "kmm-dockerfile"
-	KMMModuleName                       = "gpfs-module"
-	IBMENTITLEMENTNAME                  = "ibm-entitlement-key"
-	SecureBootKey                       = "secureboot-signing-key"
-	SecureBootKeyPub                    = "secureboot-signing-key-pub"
+	ServiceAccountName = "fusion-access-operator-controller-manager"
+	ConfigMapName      = "kmm-dockerfile"
+	KMMModuleName      = "gpfs-module"
+	IBMENTITLEMENTNAME = "ibm-entitlement-key"
+	SecureBootKey      = "secureboot-signing-key"
+	SecureBootKeyPub   = "secureboot-signing-key-pub"
+	// KMMImageConfigMapName is the deprecated ConfigMap of the kernel module image settings, replaced by
+	// the kernelModule section of the FusionAccess
 	KMMImageConfigMapName               = "kmm-image-config"
 	KMMImageConfigKeyRegistryURL        = "kmm_image_registry_url"
 	KMMImageConfigKeyRepo               = "kmm_image_repo"
@@ -59,8 +63,46 @@ const (
 	// Do not change this without also implementing a solution for upgrade of existing clusters using the current value.
 	KMMNodeSelectorKey   = "scale.spectrum.ibm.com/role"
 	KMMNodeSelectorValue = "storage"
+
+	// The IBM Storage Scale operator configmap containing the images it deploys
+	IBMManagerConfigNamespace = "ibm-spectrum-scale-operator"
+	IBMManagerConfigName      = "ibm-spectrum-scale-manager-config"
 )
 
+// defaultArchitecture is the architecture of the original gpfs-module Module
+const defaultArchitecture = "amd64"
+
+// kmmArchitecture is a node architecture the kernel module is built for
+type kmmArchitecture struct {
+	// Arch is the value of the kubernetes.io/arch label of the nodes
+	Arch string
+	// KernelSuffix ends the kernel releases of the architecture
+	KernelSuffix string
+	// BuildArgs are passed to the builds of the architecture, on top of IBM_SCALE
+	BuildArgs []kmmv1beta1.BuildArg
+}
+
+// kmmArchitectures are the architectures supported by IBM Storage Scale Container Native, see the
+// architecture list in files/<version>/metadata.yaml. The core image is a manifest list, TARGET_ARCH
+// makes sure the sources of the built architecture are copied
+var kmmArchitectures = []kmmArchitecture{
+	{
+		Arch:         "amd64",
+		KernelSuffix: "x86_64",
+		BuildArgs:    []kmmv1beta1.BuildArg{{Name: "TARGET_ARCH", Value: "amd64"}},
+	},
+	{
+		Arch:         "ppc64le",
+		KernelSuffix: "ppc64le",
+		BuildArgs:    []kmmv1beta1.BuildArg{{Name: "TARGET_ARCH", Value: "ppc64le"}},
+	},
+	{
+		Arch:         "s390x",
+		KernelSuffix: "s390x",
+		BuildArgs:    []kmmv1beta1.BuildArg{{Name: "TARGET_ARCH", Value: "s390x"}},
+	},
+}
+
 // CreateOrUpdateKMMResources creates or updates the resources needed for the kernel module builds
 // HEADS UP: consider cleanup of old resources in case of name changes or removals!
 func CreateOrUpdateKMMResources(ctx context.Context, cl client.Client) error {
@@ -98,18 +140,152 @@ func CreateOrUpdateKMMResources(ctx context.Context, cl client.Client) error {
 	if err != nil {
 		return fmt.Errorf("failed to get coreImage in CreateOrUpdateKMMResources: %w", err)
 	}
-	signModules := doSigningSecretsExist(ctx, cl, ns)
+	if err := EnsureSigningKeypair(ctx, cl, ns, &KMMImageConfig); err != nil {
+		return fmt.Errorf("failed to generate the signing secrets in CreateOrUpdateKMMResources: %w", err)
+	}
+	signModules, err := shouldSignModules(ctx, cl, ns, &KMMImageConfig)
+	if err != nil {
+		return fmt.Errorf("failed to check the signing secrets in CreateOrUpdateKMMResources: %w", err)
+	}
+
+	architectures, err := getStorageNodeArchitectures(ctx, cl)
+	if err != nil {
+		return fmt.Errorf("failed to get storage node architectures in CreateOrUpdateKMMResources: %w", err)
+	}
+	// Until the storage nodes are labeled, the Module is created for the default architecture
+	if len(architectures) == 0 {
+		architectures = []string{defaultArchitecture}
+	}
+	for _, arch := range kmmArchitectures {
+		if !slices.Contains(architectures, arch.Arch) {
+			// No storage node has this architecture anymore, so the module is not loaded anywhere
+			if err := deleteKMMModule(ctx, cl, ns, KMMModuleNameFor(arch.Arch)); err != nil {
+				return fmt.Errorf("failed to delete kernelModule in CreateOrUpdateKMMResources: %w", err)
+			}
+			continue
+		}
+		kernelModule := NewKMMModule(ns, arch.Arch, ibmScaleImage, signModules, &KMMImageConfig)
+		if err := kubeutils.CreateOrUpdateResource(ctx, cl, kernelModule, mutateKMMModule); err != nil {
+			return fmt.Errorf("failed to update kernelModule in CreateOrUpdateKMMResources: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// KMMModuleNameFor returns the name of the Module of an architecture. The amd64 Module keeps the name it
+// had before the other architectures were supported, so that it is not recreated on existing clusters
+func KMMModuleNameFor(arch string) string {
+	if arch == defaultArchitecture {
+		return KMMModuleName
+	}
+	return KMMModuleName + "-" + arch
+}
+
+// ListKMMModules returns the Modules of all the architectures that exist
+func ListKMMModules(ctx context.Context, cl client.Client, namespace string) ([]kmmv1beta1.Module, error) {
+	modules := []kmmv1beta1.Module{}
+	for _, arch := range kmmArchitectures {
+		module := kmmv1beta1.Module{}
+		name := KMMModuleNameFor(arch.Arch)
+		if err := cl.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, &module); err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to get module %s in ListKMMModules: %w", name, err)
+		}
+		modules = append(modules, module)
+	}
+	return modules, nil
+}
+
+// getStorageNodeArchitectures returns the supported architectures of the storage nodes, in the order of
+// kmmArchitectures
+func getStorageNodeArchitectures(ctx context.Context, cl client.Client) ([]string, error) {
+	nodes := &corev1.NodeList{}
+	if err := cl.List(ctx, nodes, client.MatchingLabels{KMMNodeSelectorKey: KMMNodeSelectorValue}); err != nil {
+		return nil, err
+	}
+	present := map[string]bool{}
+	for _, node := range nodes.Items {
+		arch := node.Labels[corev1.LabelArchStable]
+		if getKMMArchitecture(arch) == nil {
+			log.Log.Info("Storage node has an unsupported architecture, the kernel module is not built for it",
+				"node", node.Name, "architecture", arch)
+			continue
+		}
+		present[arch] = true
+	}
+	architectures := []string{}
+	for _, arch := range kmmArchitectures {
+		if present[arch.Arch] {
+			architectures = append(architectures, arch.Arch)
+		}
+	}
+	return architectures, nil
+}
+
+// DeleteKMMModule deletes the gpfs-module Modules of all the architectures and returns true once they are
+// gone. KMM keeps a Module around until the kernel modules have been unloaded from the nodes,
+// so callers need to retry until this returns true
+func DeleteKMMModule(ctx context.Context, cl client.Client, namespace string) (bool, error) {
+	modules, err := ListKMMModules(ctx, cl, namespace)
+	if err != nil {
+		return false, fmt.Errorf("failed to list modules in DeleteKMMModule: %w", err)
+	}
+	for idx := range modules {
+		if err := deleteKMMModule(ctx, cl, namespace, modules[idx].Name); err != nil {
+			return false, fmt.Errorf("failed to delete module in DeleteKMMModule: %w", err)
+		}
+	}
+	return len(modules) == 0, nil
+}
 
-	kernelModule := NewKMMModule(ns, ibmScaleImage, signModules, &KMMImageConfig)
-	if err := kubeutils.CreateOrUpdateResource(ctx, cl, kernelModule, mutateKMMModule); err != nil {
-		return fmt.Errorf("failed to update kernelModule in CreateOrUpdateKMMResources: %w", err)
+// deleteKMMModule deletes a Module, unless it does not exist or is already being deleted
+func deleteKMMModule(ctx context.Context, cl client.Client, namespace, name string) error {
+	module := &kmmv1beta1.Module{}
+	if err := cl.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, module); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to get module %s: %w", name, err)
+	}
+	if module.DeletionTimestamp.IsZero() {
+		if err := cl.Delete(ctx, module); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete module %s: %w", name, err)
+		}
 	}
+	return nil
+}
 
+// DeleteKMMRegistrySecret deletes the registry push/pull secret used by KMM
+func DeleteKMMRegistrySecret(ctx context.Context, cl client.Client, namespace string) error {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      KMMRegistryPushPullSecretName,
+			Namespace: namespace,
+		},
+	}
+	if err := cl.Delete(ctx, secret); err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete secret %s in DeleteKMMRegistrySecret: %w", KMMRegistryPushPullSecretName, err)
+	}
 	return nil
 }
 
-func doSigningSecretsExist(ctx context.Context, cl client.Client, namespace string) bool {
-	secretNames := []string{SecureBootKey, SecureBootKeyPub}
+// shouldSignModules returns true when the kernel modules are signed. Signing configured in the FusionAccess
+// requires its secrets, otherwise the modules are signed when the default secrets exist
+func shouldSignModules(ctx context.Context, cl client.Client, namespace string, kmmImageConfig *KMMImageConfig) (bool, error) {
+	if !doSigningSecretsExist(ctx, cl, namespace, kmmImageConfig.SigningKeySecret, kmmImageConfig.SigningCertSecret) {
+		if kmmImageConfig.SigningRequired {
+			return false, fmt.Errorf("signing secrets %s and %s are required to sign the kernel modules",
+				kmmImageConfig.SigningKeySecret, kmmImageConfig.SigningCertSecret)
+		}
+		return false, nil
+	}
+	return true, nil
+}
+
+func doSigningSecretsExist(ctx context.Context, cl client.Client, namespace string, secretNames ...string) bool {
 	for _, name := range secretNames {
 		err := cl.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, &corev1.Secret{})
 		if err != nil {
@@ -131,42 +307,14 @@ func mutateKMMModule(existing, desired *kmmv1beta1.Module) error {
 	return nil
 }
 
-func NewKMMModule(namespace, ibmScaleImage string, sign bool, kmmImageConfig *KMMImageConfig) *kmmv1beta1.Module {
-	var signing *kmmv1beta1.Sign
-	var selector map[string]string
-
-	ibmImageHash := getIBMCoreImageHash(ibmScaleImage)
-
-	// We need to truncate the image hash so the module image tag fits into 128 chars, otherwise it is an invalid docker reference
-	const maxHashLength = 32
-	if len(ibmImageHash) > maxHashLength {
-		ibmImageHash = ibmImageHash[:maxHashLength]
-	}
-
-	selector = map[string]string{
-		"kubernetes.io/arch": "amd64",
-		KMMNodeSelectorKey:   KMMNodeSelectorValue,
-	}
-
-	// See https://docs.redhat.com/en/documentation/openshift_container_platform/4.18/html/specialized_hardware_and_driver_enablement/
-	//     kernel-module-management-operator#kmm-adding-the-keys-for-secureboot_kernel-module-management-operator
-	if sign {
-		signing = &kmmv1beta1.Sign{
-			FilesToSign: []string{
-				"/opt/lib/modules/${KERNEL_FULL_VERSION}/mmfslinux.ko",
-				"/opt/lib/modules/${KERNEL_FULL_VERSION}/mmfs26.ko",
-				"/opt/lib/modules/${KERNEL_FULL_VERSION}/tracedev.ko",
-			},
-			KeySecret:  &corev1.LocalObjectReference{Name: SecureBootKey},
-			CertSecret: &corev1.LocalObjectReference{Name: SecureBootKeyPub},
-		}
-	} else {
-		signing = nil
-	}
+// NewKMMModule returns the Module loading the kernel module on the storage nodes of an architecture. The
+// build pods run on the selected nodes, so each architecture builds its own images
+func NewKMMModule(namespace, arch, ibmScaleImage string, sign bool, kmmImageConfig *KMMImageConfig) *kmmv1beta1.Module {
+	selector := kmmNodeSelector(arch)
 
 	return &kmmv1beta1.Module{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      KMMModuleName,
+			Name:      KMMModuleNameFor(arch),
 			Namespace: namespace,
 		},
 		Spec: kmmv1beta1.ModuleSpec{
@@ -192,23 +340,7 @@ func NewKMMModule(namespace, ibmScaleImage string, sign bool, kmmImageConfig *KM
 						InsecureSkipTLSVerify: kmmImageConfig.TLSSkipVerify,
 					},
 
-					KernelMappings: []kmmv1beta1.KernelMapping{{
-						Regexp:         "^.*\\.x86_64$",
-						ContainerImage: fmt.Sprintf("%s/%s:${KERNEL_FULL_VERSION}-%s", kmmImageConfig.RegistryURL, kmmImageConfig.Repo, ibmImageHash),
-						Build: &kmmv1beta1.Build{
-							DockerfileConfigMap: &corev1.LocalObjectReference{
-								Name: ConfigMapName,
-							},
-							BuildArgs: []kmmv1beta1.BuildArg{
-								{
-									Name:  "IBM_SCALE",
-									Value: ibmScaleImage,
-								},
-							},
-						},
-						Sign: signing,
-					},
-					},
+					KernelMappings: []kmmv1beta1.KernelMapping{newKMMKernelMapping(arch, ibmScaleImage, sign, kmmImageConfig)},
 				},
 				ServiceAccountName: ServiceAccountName,
 			},
@@ -220,6 +352,96 @@ func NewKMMModule(namespace, ibmScaleImage string, sign bool, kmmImageConfig *KM
 	}
 }
 
+func kmmNodeSelector(arch string) map[string]string {
+	return map[string]string{
+		corev1.LabelArchStable: arch,
+		KMMNodeSelectorKey:     KMMNodeSelectorValue,
+	}
+}
+
+// newKMMKernelMapping returns the kernel mapping of an architecture, matching the kernels of its suffix.
+// The image tag embeds the kernel version, and so the architecture
+func newKMMKernelMapping(arch, ibmScaleImage string, sign bool, kmmImageConfig *KMMImageConfig) kmmv1beta1.KernelMapping {
+	return kmmv1beta1.KernelMapping{
+		Regexp:         fmt.Sprintf("^.*\\.%s$", regexp.QuoteMeta(getKMMArchitecture(arch).KernelSuffix)),
+		ContainerImage: kmmImage(kmmImageConfig, ibmScaleImage, "${KERNEL_FULL_VERSION}"),
+		Build:          newKMMBuild(arch, ibmScaleImage, kmmImageConfig),
+		Sign:           newKMMSign(sign, kmmImageConfig),
+	}
+}
+
+// getKMMArchitecture returns the supported architecture of the given kubernetes.io/arch label, or nil
+func getKMMArchitecture(arch string) *kmmArchitecture {
+	for idx := range kmmArchitectures {
+		if kmmArchitectures[idx].Arch == arch {
+			return &kmmArchitectures[idx]
+		}
+	}
+	return nil
+}
+
+// getKernelArchitecture returns the architecture of a kernel release from its suffix, like 5.14.0-570.el9.s390x
+func getKernelArchitecture(kernel string) string {
+	for _, arch := range kmmArchitectures {
+		if strings.HasSuffix(kernel, "."+arch.KernelSuffix) {
+			return arch.Arch
+		}
+	}
+	return ""
+}
+
+// kmmImage returns the kernel module image for the given core image and kernel version
+func kmmImage(kmmImageConfig *KMMImageConfig, ibmScaleImage, kernelVersion string) string {
+	ibmImageHash := getIBMCoreImageHash(ibmScaleImage)
+
+	// We need to truncate the image hash so the module image tag fits into 128 chars, otherwise it is an invalid docker reference
+	const maxHashLength = 32
+	if len(ibmImageHash) > maxHashLength {
+		ibmImageHash = ibmImageHash[:maxHashLength]
+	}
+	return fmt.Sprintf("%s/%s:%s-%s", kmmImageConfig.RegistryURL, kmmImageConfig.Repo, kernelVersion, ibmImageHash)
+}
+
+// newKMMBuild returns the build of an architecture. The base images are only passed when they are
+// overridden, the Dockerfile defaults to the Driver Toolkit and UBI images
+func newKMMBuild(arch, ibmScaleImage string, kmmImageConfig *KMMImageConfig) *kmmv1beta1.Build {
+	buildArgs := append([]kmmv1beta1.BuildArg{
+		{
+			Name:  "IBM_SCALE",
+			Value: ibmScaleImage,
+		},
+	}, getKMMArchitecture(arch).BuildArgs...)
+	if kmmImageConfig.BuilderImage != "" {
+		buildArgs = append(buildArgs, kmmv1beta1.BuildArg{Name: "BUILDER_IMAGE", Value: kmmImageConfig.BuilderImage})
+	}
+	if kmmImageConfig.RuntimeImage != "" {
+		buildArgs = append(buildArgs, kmmv1beta1.BuildArg{Name: "RUNTIME_IMAGE", Value: kmmImageConfig.RuntimeImage})
+	}
+	return &kmmv1beta1.Build{
+		DockerfileConfigMap: &corev1.LocalObjectReference{
+			Name: ConfigMapName,
+		},
+		BuildArgs: buildArgs,
+	}
+}
+
+func newKMMSign(sign bool, kmmImageConfig *KMMImageConfig) *kmmv1beta1.Sign {
+	// See https://docs.redhat.com/en/documentation/openshift_container_platform/4.18/html/specialized_hardware_and_driver_enablement/
+	//     kernel-module-management-operator#kmm-adding-the-keys-for-secureboot_kernel-module-management-operator
+	if !sign {
+		return nil
+	}
+	return &kmmv1beta1.Sign{
+		FilesToSign: []string{
+			"/opt/lib/modules/${KERNEL_FULL_VERSION}/mmfslinux.ko",
+			"/opt/lib/modules/${KERNEL_FULL_VERSION}/mmfs26.ko",
+			"/opt/lib/modules/${KERNEL_FULL_VERSION}/tracedev.ko",
+		},
+		KeySecret:  &corev1.LocalObjectReference{Name: kmmImageConfig.SigningKeySecret},
+		CertSecret: &corev1.LocalObjectReference{Name: kmmImageConfig.SigningCertSecret},
+	}
+}
+
 // Struct to hold image config
 type KMMImageConfig struct {
 	RegistryURL        string
@@ -227,49 +449,144 @@ type KMMImageConfig struct {
 	TLSInsecure        bool
 	TLSSkipVerify      bool
 	RegistrySecretName string
+	// SigningKeySecret and SigningCertSecret are the secrets the kernel modules are signed with.
+	// SigningRequired is set when signing is configured explicitly, so the secrets have to exist.
+	// SigningGenerate creates the signing secrets when neither exists
+	SigningKeySecret  string
+	SigningCertSecret string
+	SigningRequired   bool
+	SigningGenerate   bool
+	// BuilderImage and RuntimeImage override the base images of the builds when set
+	BuilderImage string
+	RuntimeImage string
 }
 
-// Public function to get KMMImageConfig from ConfigMap held in var GetKMMImageConfig
-var GetKMMImageConfig = func(ctx context.Context, cl client.Client, namespace string) (KMMImageConfig, error) {
+// NewKMMImageConfig returns the image config of the kernelModule section of the FusionAccess, with the
+// defaults of the unset fields
+func NewKMMImageConfig(namespace string, kernelModule *v1alpha1.KernelModuleConfig) KMMImageConfig {
 	config := KMMImageConfig{
-		RegistryURL:        "image-registry.openshift-image-registry.svc:5000",
-		Repo:               fmt.Sprintf("%s/gpfs_compat_kmod", namespace),
-		TLSInsecure:        false,
-		TLSSkipVerify:      false,
-		RegistrySecretName: "",
+		RegistryURL:       "image-registry.openshift-image-registry.svc:5000",
+		Repo:              fmt.Sprintf("%s/gpfs_compat_kmod", namespace),
+		SigningKeySecret:  SecureBootKey,
+		SigningCertSecret: SecureBootKeyPub,
+	}
+	if kernelModule == nil {
+		return config
+	}
+	if kernelModule.Registry != "" {
+		config.RegistryURL = kernelModule.Registry
+	}
+	if kernelModule.Repository != "" {
+		config.Repo = kernelModule.Repository
+	}
+	config.TLSInsecure = kernelModule.TLS.Insecure
+	config.TLSSkipVerify = kernelModule.TLS.InsecureSkipVerify
+	if kernelModule.RegistrySecret != nil {
+		config.RegistrySecretName = kernelModule.RegistrySecret.Name
+	}
+	if kernelModule.Signing != nil {
+		config.SigningRequired = true
+		config.SigningGenerate = kernelModule.Signing.Generate
+		if kernelModule.Signing.KeySecret != "" {
+			config.SigningKeySecret = kernelModule.Signing.KeySecret
+		}
+		if kernelModule.Signing.CertSecret != "" {
+			config.SigningCertSecret = kernelModule.Signing.CertSecret
+		}
+	}
+	if kernelModule.BaseImages != nil {
+		config.BuilderImage = kernelModule.BaseImages.Builder
+		config.RuntimeImage = kernelModule.BaseImages.Runtime
 	}
+	return config
+}
+
+// Public function to get KMMImageConfig held in var GetKMMImageConfig. It is read from the kernelModule
+// section of the FusionAccess, or from the deprecated kmm-image-config ConfigMap until it is migrated
+var GetKMMImageConfig = func(ctx context.Context, cl client.Client, namespace string) (KMMImageConfig, error) {
+	fusionAccesses := &v1alpha1.FusionAccessList{}
+	if err := cl.List(ctx, fusionAccesses); err != nil {
+		return KMMImageConfig{}, fmt.Errorf("failed to list FusionAccess resources in GetKMMImageConfig: %w", err)
+	}
+	for idx := range fusionAccesses.Items {
+		if kernelModule := fusionAccesses.Items[idx].Spec.KernelModule; kernelModule != nil {
+			return NewKMMImageConfig(namespace, kernelModule), nil
+		}
+	}
+
+	kernelModule, err := getDeprecatedKernelModuleConfig(ctx, cl, namespace)
+	if err != nil {
+		return KMMImageConfig{}, fmt.Errorf("failed to read deprecated configmap in GetKMMImageConfig: %w", err)
+	}
+	if kernelModule == nil {
+		log.Log.Info(fmt.Sprintf("No kernelModule configuration and configmap %s not found, using default values", KMMImageConfigMapName))
+	}
+	return NewKMMImageConfig(namespace, kernelModule), nil
+}
+
+// MigrateKMMImageConfig copies the deprecated kmm-image-config ConfigMap to the kernelModule section of the
+// FusionAccess, unless it is already set. It returns true when the FusionAccess was changed and needs
+// to be updated. The ConfigMap is ignored from then on
+func MigrateKMMImageConfig(ctx context.Context, cl client.Client, namespace string, fusionAccess *v1alpha1.FusionAccess) (bool, error) {
+	if fusionAccess.Spec.KernelModule != nil {
+		return false, nil
+	}
+	kernelModule, err := getDeprecatedKernelModuleConfig(ctx, cl, namespace)
+	if err != nil {
+		return false, fmt.Errorf("failed to read deprecated configmap in MigrateKMMImageConfig: %w", err)
+	}
+	if kernelModule == nil {
+		return false, nil
+	}
+	if err := kernelModule.Validate(); err != nil {
+		return false, fmt.Errorf("failed to migrate configmap %s: %w", KMMImageConfigMapName, err)
+	}
+	log.Log.Info(fmt.Sprintf("Migrating deprecated configmap %s to spec.kernelModule of the FusionAccess, "+
+		"the configmap is not used anymore and can be deleted", KMMImageConfigMapName))
+	fusionAccess.Spec.KernelModule = kernelModule
+	return true, nil
+}
+
+// getDeprecatedKernelModuleConfig returns the kernel module configuration of the deprecated kmm-image-config
+// ConfigMap, or nil when it does not exist
+func getDeprecatedKernelModuleConfig(ctx context.Context, cl client.Client, namespace string) (*v1alpha1.KernelModuleConfig, error) {
 	cm := &corev1.ConfigMap{}
 	if err := cl.Get(ctx, types.NamespacedName{Namespace: namespace, Name: KMMImageConfigMapName}, cm); err != nil {
 		if errors.IsNotFound(err) {
-			log.Log.Info(fmt.Sprintf("Configmap %s not found, using default values", KMMImageConfigMapName))
-			return config, nil
+			return nil, nil
 		}
-		return config, fmt.Errorf("failed to get configmap %s in GetKMMImageConfig: %w", KMMImageConfigMapName, err)
+		return nil, fmt.Errorf("failed to get configmap %s: %w", KMMImageConfigMapName, err)
 	}
 	data := cm.Data
 
-	// Override values if present
-	if val, ok := data[KMMImageConfigKeyRegistryURL]; ok {
-		config.RegistryURL = val
+	kernelModule := &v1alpha1.KernelModuleConfig{
+		Registry:   data[KMMImageConfigKeyRegistryURL],
+		Repository: data[KMMImageConfigKeyRepo],
 	}
-	if val, ok := data[KMMImageConfigKeyRepo]; ok {
-		config.Repo = val
-	}
-	if val, ok := data[KMMImageConfigKeyTLSInsecure]; ok {
-		if parsed, err := strconv.ParseBool(val); err == nil {
-			config.TLSInsecure = parsed
-		}
+	var err error
+	if kernelModule.TLS.Insecure, err = parseConfigMapBool(data, KMMImageConfigKeyTLSInsecure); err != nil {
+		return nil, err
 	}
-	if val, ok := data[KMMImageConfigKeyTLSSkipVerify]; ok {
-		if parsed, err := strconv.ParseBool(val); err == nil {
-			config.TLSSkipVerify = parsed
-		}
+	if kernelModule.TLS.InsecureSkipVerify, err = parseConfigMapBool(data, KMMImageConfigKeyTLSSkipVerify); err != nil {
+		return nil, err
 	}
-	if val, ok := data[KMMImageConfigKeyRegistrySecretName]; ok {
-		config.RegistrySecretName = val
+	if val := data[KMMImageConfigKeyRegistrySecretName]; val != "" {
+		kernelModule.RegistrySecret = &corev1.LocalObjectReference{Name: val}
 	}
+	return kernelModule, nil
+}
 
-	return config, nil
+// parseConfigMapBool parses a boolean of the deprecated ConfigMap, false when the key is not set
+func parseConfigMapBool(data map[string]string, key string) (bool, error) {
+	val, ok := data[key]
+	if !ok {
+		return false, nil
+	}
+	parsed, err := strconv.ParseBool(val)
+	if err != nil {
+		return false, fmt.Errorf("invalid boolean %q for %s in configmap %s: %w", val, key, KMMImageConfigMapName, err)
+	}
+	return parsed, nil
 }
 
 // getMergedRegistrySecret will return the merged secret (registry used for kmm and core images)
@@ -325,15 +642,28 @@ func getMergedRegistrySecret(ctx context.Context, cl client.Client, namespace st
 // getIBMCoreImage gets the core init image with the source code in them
 func getIBMCoreImage(ctx context.Context, cl client.Client) (string, error) {
 	cm := &corev1.ConfigMap{}
-	err := cl.Get(ctx, types.NamespacedName{Namespace: "ibm-spectrum-scale-operator", Name: "ibm-spectrum-scale-manager-config"}, cm)
+	err := cl.Get(ctx, types.NamespacedName{Namespace: IBMManagerConfigNamespace, Name: IBMManagerConfigName}, cm)
 	if err != nil {
 		return "", err
 	}
+	return ParseIBMCoreImage(cm)
+}
+
+// ParseIBMCoreImage extracts the core init image from the IBM Storage Scale manager configmap
+func ParseIBMCoreImage(cm *corev1.ConfigMap) (string, error) {
 	var objmap map[string]any
 	if err := yaml.Unmarshal([]byte(cm.Data["controller_manager_config.yaml"]), &objmap); err != nil {
 		return "", err
 	}
-	return objmap["images"].(map[string]any)["coreInit"].(string), nil
+	images, ok := objmap["images"].(map[string]any)
+	if !ok {
+		return "", fmt.Errorf("no images found in configmap %s", cm.Name)
+	}
+	coreInit, ok := images["coreInit"].(string)
+	if !ok {
+		return "", fmt.Errorf("no coreInit image found in configmap %s", cm.Name)
+	}
+	return coreInit, nil
 }
 
 func getIBMCoreImageHash(image string) string {
@@ -360,15 +690,18 @@ func NewDockerConfigmap(namespace string) *corev1.ConfigMap {
 	dockerFileValue := `ARG IBM_SCALE
 ARG DTK_AUTO
 ARG KERNEL_FULL_VERSION
-FROM ${IBM_SCALE} as src_image
-FROM ${DTK_AUTO} as builder
+ARG TARGET_ARCH=amd64
+ARG BUILDER_IMAGE=${DTK_AUTO}
+ARG RUNTIME_IMAGE=registry.redhat.io/ubi9/ubi-minimal
+FROM --platform=linux/${TARGET_ARCH} ${IBM_SCALE} as src_image
+FROM ${BUILDER_IMAGE} as builder
 ARG KERNEL_FULL_VERSION
 COPY --from=src_image /usr/lpp/mmfs /usr/lpp/mmfs
 RUN /usr/lpp/mmfs/bin/mmbuildgpl
 RUN mkdir -p /opt/lib/modules/${KERNEL_FULL_VERSION}/
 RUN cp -avf /lib/modules/${KERNEL_FULL_VERSION}/extra/*.ko /opt/lib/modules/${KERNEL_FULL_VERSION}/
 RUN depmod -b /opt
-FROM registry.redhat.io/ubi9/ubi-minimal
+FROM ${RUNTIME_IMAGE}
 ARG KERNEL_FULL_VERSION
 RUN mkdir -p /opt/lib/modules/${KERNEL_FULL_VERSION}/ /opt/lxtrace/
 COPY --from=builder /opt/lib/modules/${KERNEL_FULL_VERSION}/*.ko /opt/lib/modules/${KERNEL_FULL_VERSION}/
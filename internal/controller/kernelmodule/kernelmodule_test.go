@@ -1,14 +1,25 @@
 package kernelmodule
 
 import (
+	"context"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
 	"strings"
 	"testing"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/openshift-storage-scale/openshift-fusion-access-operator/api/v1alpha1"
 	kmmv1beta1 "github.com/rh-ecosystem-edge/kernel-module-management/api/v1beta1"
+	kmmv1beta2 "github.com/rh-ecosystem-edge/kernel-module-management/api/v1beta2"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 var _ = Describe("ExtractImageVersion", func() {
@@ -320,7 +331,514 @@ var _ = Describe("mutateKMMModule", func() {
 	})
 })
 
+var _ = Describe("Kernel module architectures", func() {
+	const (
+		namespace = "ibm-fusion-access"
+		coreImage = "cp.icr.io/cp/gpfs/ibm-spectrum-scale-core-init@sha256:abc123"
+	)
+	config := &KMMImageConfig{RegistryURL: "registry.example.com", Repo: "gpfs_compat_kmod"}
+
+	newNode := func(name, arch string, storage bool) *corev1.Node {
+		labels := map[string]string{corev1.LabelArchStable: arch}
+		if storage {
+			labels[KMMNodeSelectorKey] = KMMNodeSelectorValue
+		}
+		return &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+	}
+
+	newClient := func(objects ...client.Object) client.Client {
+		scheme := runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		Expect(kmmv1beta1.AddToScheme(scheme)).To(Succeed())
+		return fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+	}
+
+	DescribeTable("generates the Module of each architecture",
+		func(arch, name, regexp string) {
+			module := NewKMMModule(namespace, arch, coreImage, false, config)
+			Expect(module.Name).To(Equal(name))
+			Expect(module.Spec.Selector).To(Equal(map[string]string{
+				corev1.LabelArchStable: arch,
+				KMMNodeSelectorKey:     KMMNodeSelectorValue,
+			}))
+			mappings := module.Spec.ModuleLoader.Container.KernelMappings
+			Expect(mappings).To(HaveLen(1))
+			Expect(mappings[0].Regexp).To(Equal(regexp))
+			Expect(mappings[0].ContainerImage).To(Equal("registry.example.com/gpfs_compat_kmod:${KERNEL_FULL_VERSION}-abc123"))
+			Expect(mappings[0].Build.BuildArgs).To(Equal([]kmmv1beta1.BuildArg{
+				{Name: "IBM_SCALE", Value: coreImage},
+				{Name: "TARGET_ARCH", Value: arch},
+			}))
+		},
+		Entry("amd64 keeps the original name", "amd64", "gpfs-module", `^.*\.x86_64$`),
+		Entry("ppc64le", "ppc64le", "gpfs-module-ppc64le", `^.*\.ppc64le$`),
+		Entry("s390x", "s390x", "gpfs-module-s390x", `^.*\.s390x$`),
+	)
+
+	DescribeTable("finds the architecture of a kernel",
+		func(kernel, expected string) {
+			Expect(getKernelArchitecture(kernel)).To(Equal(expected))
+		},
+		Entry("x86_64", "5.14.0-570.el9.x86_64", "amd64"),
+		Entry("ppc64le", "5.14.0-570.el9.ppc64le", "ppc64le"),
+		Entry("s390x", "5.14.0-570.el9.s390x", "s390x"),
+		Entry("aarch64", "5.14.0-570.el9.aarch64", ""),
+	)
+
+	It("lists the supported architectures of the storage nodes", func() {
+		cl := newClient(
+			newNode("worker-0", "s390x", true),
+			newNode("worker-1", "amd64", true),
+			newNode("worker-2", "s390x", true),
+			newNode("worker-3", "arm64", true),
+			newNode("worker-4", "ppc64le", false),
+		)
+		architectures, err := getStorageNodeArchitectures(context.TODO(), cl)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(architectures).To(Equal([]string{"amd64", "s390x"}))
+	})
+
+	It("pre-builds the kernels of every architecture with their build arguments", func() {
+		amd64Node := newNode("worker-0", "amd64", true)
+		amd64Node.Status.NodeInfo.KernelVersion = "5.14.0-570.el9.x86_64"
+		s390xNode := newNode("worker-1", "s390x", true)
+		s390xNode.Status.NodeInfo.KernelVersion = "5.14.0-570.el9.s390x"
+		kernels, err := getStorageNodeKernels(context.TODO(), newClient(amd64Node, s390xNode))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(kernels).To(Equal([]string{"5.14.0-570.el9.s390x", "5.14.0-570.el9.x86_64"}))
+
+		module := NewKMMPrebuild(namespace, "s390x", coreImage, "v5.2.3.1", false, config)
+		Expect(module.Name).To(Equal("gpfs-module-prebuild-s390x"))
+		Expect(module.Spec.Selector).To(Equal(kmmNodeSelector("s390x")))
+		Expect(module.Spec.ModuleLoader.Container.Version).To(Equal("v5.2.3.1"))
+		Expect(module.Spec.ModuleLoader.Container.KernelMappings[0].Build.BuildArgs).
+			To(ContainElement(kmmv1beta1.BuildArg{Name: "TARGET_ARCH", Value: "s390x"}))
+		Expect(NewKMMPrebuild(namespace, "amd64", coreImage, "v5.2.3.1", false, config).Name).To(Equal(KMMPrebuildName))
+	})
+
+	It("deletes the Modules of all the architectures", func() {
+		cl := newClient(
+			&kmmv1beta1.Module{ObjectMeta: metav1.ObjectMeta{Name: "gpfs-module", Namespace: namespace}},
+			&kmmv1beta1.Module{ObjectMeta: metav1.ObjectMeta{Name: "gpfs-module-ppc64le", Namespace: namespace}},
+		)
+		gone, err := DeleteKMMModule(context.TODO(), cl, namespace)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(gone).To(BeFalse())
+
+		modules, err := ListKMMModules(context.TODO(), cl, namespace)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(modules).To(BeEmpty())
+		gone, err = DeleteKMMModule(context.TODO(), cl, namespace)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(gone).To(BeTrue())
+	})
+})
+
+var _ = Describe("Kernel module configuration", func() {
+	const namespace = "ibm-fusion-access"
+
+	newClient := func(objects ...client.Object) client.Client {
+		scheme := runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		Expect(v1alpha1.AddToScheme(scheme)).To(Succeed())
+		return fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+	}
+
+	newConfigMap := func(data map[string]string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: KMMImageConfigMapName, Namespace: namespace},
+			Data:       data,
+		}
+	}
+
+	newFusionAccess := func(kernelModule *v1alpha1.KernelModuleConfig) *v1alpha1.FusionAccess {
+		return &v1alpha1.FusionAccess{
+			ObjectMeta: metav1.ObjectMeta{Name: "fusionaccess-object", Namespace: namespace},
+			Spec:       v1alpha1.FusionAccessSpec{KernelModule: kernelModule},
+		}
+	}
+
+	It("uses the defaults without any configuration", func() {
+		config, err := GetKMMImageConfig(context.TODO(), newClient(), namespace)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(config).To(Equal(KMMImageConfig{
+			RegistryURL:       "image-registry.openshift-image-registry.svc:5000",
+			Repo:              "ibm-fusion-access/gpfs_compat_kmod",
+			SigningKeySecret:  SecureBootKey,
+			SigningCertSecret: SecureBootKeyPub,
+		}))
+	})
+
+	It("prefers the kernelModule section of the FusionAccess over the deprecated configmap", func() {
+		cl := newClient(
+			newConfigMap(map[string]string{KMMImageConfigKeyRegistryURL: "quay.io"}),
+			newFusionAccess(&v1alpha1.KernelModuleConfig{
+				Registry:       "registry.example.com:5000",
+				Repository:     "fusion/gpfs_compat_kmod",
+				TLS:            v1alpha1.KernelModuleRegistryTLS{InsecureSkipVerify: true},
+				RegistrySecret: &corev1.LocalObjectReference{Name: "registry-secret"},
+				Signing:        &v1alpha1.KernelModuleSigning{KeySecret: "signing-key", CertSecret: "signing-cert"},
+				BaseImages:     &v1alpha1.KernelModuleBaseImages{Runtime: "mirror.example.com/ubi9/ubi-minimal"},
+			}),
+		)
+		config, err := GetKMMImageConfig(context.TODO(), cl, namespace)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(config).To(Equal(KMMImageConfig{
+			RegistryURL:        "registry.example.com:5000",
+			Repo:               "fusion/gpfs_compat_kmod",
+			TLSSkipVerify:      true,
+			RegistrySecretName: "registry-secret",
+			SigningKeySecret:   "signing-key",
+			SigningCertSecret:  "signing-cert",
+			SigningRequired:    true,
+			RuntimeImage:       "mirror.example.com/ubi9/ubi-minimal",
+		}))
+	})
+
+	It("falls back to the deprecated configmap", func() {
+		cl := newClient(newConfigMap(map[string]string{
+			KMMImageConfigKeyRegistryURL:        "quay.io",
+			KMMImageConfigKeyTLSInsecure:        "true",
+			KMMImageConfigKeyRegistrySecretName: "quay-secret",
+		}))
+		config, err := GetKMMImageConfig(context.TODO(), cl, namespace)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(config.RegistryURL).To(Equal("quay.io"))
+		Expect(config.Repo).To(Equal("ibm-fusion-access/gpfs_compat_kmod"))
+		Expect(config.TLSInsecure).To(BeTrue())
+		Expect(config.RegistrySecretName).To(Equal("quay-secret"))
+		Expect(config.SigningRequired).To(BeFalse())
+	})
+
+	It("rejects a boolean of the deprecated configmap that cannot be parsed", func() {
+		cl := newClient(newConfigMap(map[string]string{KMMImageConfigKeyTLSSkipVerify: "yes"}))
+		_, err := GetKMMImageConfig(context.TODO(), cl, namespace)
+		Expect(err).To(MatchError(ContainSubstring(`invalid boolean "yes" for kmm_tls_skip_verify`)))
+	})
+
+	It("migrates the deprecated configmap to the FusionAccess", func() {
+		cl := newClient(newConfigMap(map[string]string{
+			KMMImageConfigKeyRegistryURL:   "quay.io",
+			KMMImageConfigKeyRepo:          "fusion/gpfs_compat_kmod",
+			KMMImageConfigKeyTLSSkipVerify: "true",
+		}))
+		fusionAccess := newFusionAccess(nil)
+		migrated, err := MigrateKMMImageConfig(context.TODO(), cl, namespace, fusionAccess)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(migrated).To(BeTrue())
+		Expect(fusionAccess.Spec.KernelModule).To(Equal(&v1alpha1.KernelModuleConfig{
+			Registry:   "quay.io",
+			Repository: "fusion/gpfs_compat_kmod",
+			TLS:        v1alpha1.KernelModuleRegistryTLS{InsecureSkipVerify: true},
+		}))
+
+		migrated, err = MigrateKMMImageConfig(context.TODO(), cl, namespace, fusionAccess)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(migrated).To(BeFalse())
+	})
+
+	It("does not migrate anything without the deprecated configmap", func() {
+		fusionAccess := newFusionAccess(nil)
+		migrated, err := MigrateKMMImageConfig(context.TODO(), newClient(), namespace, fusionAccess)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(migrated).To(BeFalse())
+		Expect(fusionAccess.Spec.KernelModule).To(BeNil())
+	})
+
+	It("does not migrate an invalid deprecated configmap", func() {
+		cl := newClient(newConfigMap(map[string]string{KMMImageConfigKeyRegistryURL: "https://quay.io"}))
+		_, err := MigrateKMMImageConfig(context.TODO(), cl, namespace, newFusionAccess(nil))
+		Expect(err).To(MatchError(ContainSubstring("invalid kernelModule registry")))
+	})
+
+	DescribeTable("decides whether the kernel modules are signed",
+		func(kernelModule *v1alpha1.KernelModuleConfig, secrets []string, sign bool, expectedErr string) {
+			objects := []client.Object{}
+			for _, name := range secrets {
+				objects = append(objects, &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}})
+			}
+			config := NewKMMImageConfig(namespace, kernelModule)
+			signModules, err := shouldSignModules(context.TODO(), newClient(objects...), namespace, &config)
+			if expectedErr != "" {
+				Expect(err).To(MatchError(ContainSubstring(expectedErr)))
+				return
+			}
+			Expect(err).ToNot(HaveOccurred())
+			Expect(signModules).To(Equal(sign))
+		},
+		Entry("default secrets exist", nil, []string{SecureBootKey, SecureBootKeyPub}, true, ""),
+		Entry("default secrets missing", nil, []string{SecureBootKey}, false, ""),
+		Entry("configured secrets exist", &v1alpha1.KernelModuleConfig{
+			Signing: &v1alpha1.KernelModuleSigning{KeySecret: "signing-key", CertSecret: "signing-cert"},
+		}, []string{"signing-key", "signing-cert"}, true, ""),
+		Entry("configured secrets missing", &v1alpha1.KernelModuleConfig{
+			Signing: &v1alpha1.KernelModuleSigning{KeySecret: "signing-key", CertSecret: "signing-cert"},
+		}, []string{SecureBootKey, SecureBootKeyPub}, false, "signing secrets signing-key and signing-cert are required"),
+	)
+
+	It("signs the modules with the configured secrets and builds them from the overridden base images", func() {
+		config := NewKMMImageConfig(namespace, &v1alpha1.KernelModuleConfig{
+			Signing: &v1alpha1.KernelModuleSigning{KeySecret: "signing-key", CertSecret: "signing-cert"},
+			BaseImages: &v1alpha1.KernelModuleBaseImages{
+				Builder: "mirror.example.com/openshift/driver-toolkit:latest",
+				Runtime: "mirror.example.com/ubi9/ubi-minimal:latest",
+			},
+		})
+		module := NewKMMModule(namespace, "amd64", "cp.icr.io/cp/gpfs/core-init:5.2.3.1", true, &config)
+		mapping := module.Spec.ModuleLoader.Container.KernelMappings[0]
+		Expect(mapping.Sign.KeySecret.Name).To(Equal("signing-key"))
+		Expect(mapping.Sign.CertSecret.Name).To(Equal("signing-cert"))
+		Expect(mapping.Build.BuildArgs).To(ContainElements(
+			kmmv1beta1.BuildArg{Name: "BUILDER_IMAGE", Value: "mirror.example.com/openshift/driver-toolkit:latest"},
+			kmmv1beta1.BuildArg{Name: "RUNTIME_IMAGE", Value: "mirror.example.com/ubi9/ubi-minimal:latest"},
+		))
+	})
+})
+
+var _ = Describe("Kernel module builds", func() {
+	const (
+		namespace = "ibm-fusion-access"
+		image     = "registry.example.com/gpfs_compat_kmod:5.14.0-570.el9.x86_64-abc123"
+	)
+
+	DescribeTable("maps the KMM image states",
+		func(imageState kmmv1beta1.ImageState, action kmmv1beta1.BuildOrSignAction, actionStatus kmmv1beta1.BuildOrSignStatus,
+			expected v1alpha1.KernelModuleBuildState) {
+			mic := &kmmv1beta1.ModuleImagesConfig{}
+			if imageState != "" {
+				mic.Status.ImagesStates = []kmmv1beta1.ModuleImageState{{Image: image, Status: imageState}}
+			}
+			mbsc := &kmmv1beta1.ModuleBuildSignConfig{}
+			if actionStatus != "" {
+				mbsc.Status.Images = []kmmv1beta1.BuildSignImageState{{Image: image, Action: action, Status: actionStatus}}
+			}
+			Expect(getImageBuildState(image, mic, mbsc)).To(Equal(expected))
+		},
+		Entry("not checked yet", kmmv1beta1.ImageState(""), kmmv1beta1.BuildImage, kmmv1beta1.BuildOrSignStatus(""),
+			v1alpha1.KernelModuleBuildPending),
+		Entry("building", kmmv1beta1.ImageNeedsBuilding, kmmv1beta1.BuildImage, kmmv1beta1.BuildOrSignStatus(""),
+			v1alpha1.KernelModuleBuildBuilding),
+		Entry("signing after a successful build", kmmv1beta1.ImageNeedsSigning, kmmv1beta1.BuildImage, kmmv1beta1.ActionSuccess,
+			v1alpha1.KernelModuleBuildSigning),
+		Entry("build failed", kmmv1beta1.ImageNeedsBuilding, kmmv1beta1.BuildImage, kmmv1beta1.ActionFailure,
+			v1alpha1.KernelModuleBuildBuildFailed),
+		Entry("sign failed", kmmv1beta1.ImageNeedsSigning, kmmv1beta1.SignImage, kmmv1beta1.ActionFailure,
+			v1alpha1.KernelModuleBuildSignFailed),
+		Entry("cannot be built", kmmv1beta1.ImageDoesNotExist, kmmv1beta1.BuildImage, kmmv1beta1.BuildOrSignStatus(""),
+			v1alpha1.KernelModuleBuildMissing),
+		Entry("built after a failure", kmmv1beta1.ImageExists, kmmv1beta1.BuildImage, kmmv1beta1.ActionFailure,
+			v1alpha1.KernelModuleBuildReady),
+	)
+
+	It("reports the log of the last failed build pod of the kernel", func() {
+		newPod := func(name, kernel string, phase corev1.PodPhase, created int64) *corev1.Pod {
+			return &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              name,
+					Namespace:         namespace,
+					CreationTimestamp: metav1.Unix(created, 0),
+					Labels: map[string]string{
+						KMMModuleNameLabel:   KMMModuleName,
+						KMMPodTypeLabel:      KMMPodTypeBuild,
+						KMMTargetKernelLabel: kernel,
+					},
+				},
+				Status: corev1.PodStatus{Phase: phase},
+			}
+		}
+		scheme := runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			newPod("gpfs-module-build-aaaaa", "5.14.0-570.el9.x86_64", corev1.PodFailed, 100),
+			newPod("gpfs-module-build-bbbbb", "5.14.0-570.el9.x86_64", corev1.PodFailed, 200),
+			newPod("gpfs-module-build-ccccc", "5.14.0-570.el9.x86_64", corev1.PodRunning, 300),
+			newPod("gpfs-module-build-ddddd", "5.14.0-427.el9.x86_64", corev1.PodFailed, 400),
+		).Build()
+
+		originalGetPodLogTail := GetPodLogTail
+		DeferCleanup(func() { GetPodLogTail = originalGetPodLogTail })
+		var requested string
+		GetPodLogTail = func(_ context.Context, _, name string, lines int64) (string, error) {
+			requested = name
+			Expect(lines).To(Equal(int64(failedPodLogLines)))
+			return strings.Repeat("x", maxFailedPodLogLength) + "make: *** [modules] Error 2\n", nil
+		}
+
+		message, err := getFailedPodLog(context.TODO(), cl, namespace, KMMModuleName, "5.14.0-570.el9.x86_64", KMMPodTypeBuild)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(requested).To(Equal("gpfs-module-build-bbbbb"))
+		Expect(message).To(HavePrefix("build pod gpfs-module-build-bbbbb failed: x"))
+		Expect(message).To(HaveSuffix("make: *** [modules] Error 2"))
+		Expect(len(message)).To(BeNumerically("<=", len("build pod gpfs-module-build-bbbbb failed: ")+maxFailedPodLogLength))
+
+		message, err = getFailedPodLog(context.TODO(), cl, namespace, KMMModuleName, "5.14.0-611.el9.x86_64", KMMPodTypeBuild)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(message).To(BeEmpty())
+	})
+})
+
 func TestGetIBMCoreImageHash(t *testing.T) {
 	RegisterFailHandler(Fail)
 	RunSpecs(t, "getIBMCoreImageHash Suite")
 }
+
+var _ = Describe("Kernel module upgrade preflight", func() {
+	const kernel = "5.14.0-570.12.1.el9_6.x86_64"
+
+	preflight := &v1alpha1.KernelModuleUpgradePreflight{
+		KernelVersion: kernel,
+		DTKImage:      "quay.io/openshift-release-dev/ocp-v4.0-art-dev@sha256:def456",
+	}
+
+	newClient := func(objects ...client.Object) client.Client {
+		scheme := runtime.NewScheme()
+		Expect(kmmv1beta2.AddToScheme(scheme)).To(Succeed())
+		return fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+	}
+
+	DescribeTable("returns the Module of the architecture of a kernel",
+		func(kernel, expected string) {
+			Expect(KMMModuleNameForKernel(kernel)).To(Equal(expected))
+		},
+		Entry("amd64", "5.14.0-570.12.1.el9_6.x86_64", KMMModuleName),
+		Entry("s390x", "5.14.0-570.12.1.el9_6.s390x", KMMModuleName+"-s390x"),
+		Entry("unknown", "5.14.0-570.12.1.el9_6", ""),
+	)
+
+	It("builds and pushes the images of the target release", func() {
+		pfvo := NewKMMPreflight(preflight, 3)
+		Expect(pfvo.Name).To(Equal(KMMPreflightName))
+		Expect(pfvo.Namespace).To(BeEmpty())
+		Expect(pfvo.Annotations).To(HaveKeyWithValue(kmmPreflightModuleGenerationAnnotation, "3"))
+		Expect(pfvo.Spec).To(Equal(kmmv1beta2.PreflightValidationOCPSpec{
+			KernelVersion:  kernel,
+			DTKImage:       preflight.DTKImage,
+			PushBuiltImage: true,
+		}))
+	})
+
+	It("creates the preflight validation and returns it while it matches", func() {
+		ctx := context.Background()
+		cl := newClient()
+
+		current, err := CreateOrUpdateKMMPreflight(ctx, cl, NewKMMPreflight(preflight, 1))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(current).To(BeNil())
+
+		current, err = CreateOrUpdateKMMPreflight(ctx, cl, NewKMMPreflight(preflight, 1))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(current).ToNot(BeNil())
+		Expect(current.Spec.KernelVersion).To(Equal(kernel))
+	})
+
+	DescribeTable("restarts the preflight validation when it is outdated",
+		func(desired *kmmv1beta2.PreflightValidationOCP) {
+			ctx := context.Background()
+			cl := newClient(NewKMMPreflight(preflight, 1))
+
+			current, err := CreateOrUpdateKMMPreflight(ctx, cl, desired)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(current).To(BeNil())
+			Expect(errors.IsNotFound(cl.Get(ctx, types.NamespacedName{Name: KMMPreflightName}, &kmmv1beta2.PreflightValidationOCP{}))).To(BeTrue())
+
+			_, err = CreateOrUpdateKMMPreflight(ctx, cl, desired)
+			Expect(err).ToNot(HaveOccurred())
+			created := &kmmv1beta2.PreflightValidationOCP{}
+			Expect(cl.Get(ctx, types.NamespacedName{Name: KMMPreflightName}, created)).To(Succeed())
+			Expect(created.Spec).To(Equal(desired.Spec))
+			Expect(created.Annotations).To(Equal(desired.Annotations))
+		},
+		Entry("another release", NewKMMPreflight(&v1alpha1.KernelModuleUpgradePreflight{
+			KernelVersion: "5.14.0-611.5.1.el9_7.x86_64",
+			DTKImage:      "quay.io/openshift-release-dev/ocp-v4.0-art-dev@sha256:0f1e2d",
+		}, 1)),
+		Entry("a changed Module", NewKMMPreflight(preflight, 2)),
+	)
+
+	It("deletes the preflight validation", func() {
+		ctx := context.Background()
+		cl := newClient(NewKMMPreflight(preflight, 1))
+		Expect(DeleteKMMPreflight(ctx, cl)).To(Succeed())
+		Expect(errors.IsNotFound(cl.Get(ctx, types.NamespacedName{Name: KMMPreflightName}, &kmmv1beta2.PreflightValidationOCP{}))).To(BeTrue())
+		Expect(DeleteKMMPreflight(ctx, cl)).To(Succeed())
+	})
+
+	It("returns the result of our Module", func() {
+		pfvo := NewKMMPreflight(preflight, 1)
+		pfvo.Status.Modules = []kmmv1beta2.PreflightValidationModuleStatus{
+			{Name: KMMModuleName, Namespace: "other", CRBaseStatus: kmmv1beta2.CRBaseStatus{VerificationStatus: kmmv1beta2.VerificationFailure}},
+			{Name: KMMModuleName, Namespace: "ibm-fusion-access", CRBaseStatus: kmmv1beta2.CRBaseStatus{VerificationStatus: kmmv1beta2.VerificationSuccess}},
+		}
+		status := GetKMMPreflightModuleStatus(pfvo, "ibm-fusion-access", KMMModuleName)
+		Expect(status).ToNot(BeNil())
+		Expect(status.VerificationStatus).To(Equal(kmmv1beta2.VerificationSuccess))
+		Expect(GetKMMPreflightModuleStatus(pfvo, "ibm-fusion-access", KMMModuleName+"-s390x")).To(BeNil())
+		Expect(GetKMMPreflightModuleStatus(nil, "ibm-fusion-access", KMMModuleName)).To(BeNil())
+	})
+})
+
+var _ = Describe("Kernel module signing keypair", func() {
+	const ns = "ibm-fusion-access"
+
+	newClient := func(objects ...client.Object) client.Client {
+		scheme := runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		return fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+	}
+
+	generateConfig := func() KMMImageConfig {
+		return NewKMMImageConfig(ns, &v1alpha1.KernelModuleConfig{
+			Signing: &v1alpha1.KernelModuleSigning{KeySecret: SecureBootKey, CertSecret: SecureBootKeyPub, Generate: true},
+		})
+	}
+
+	getSecret := func(cl client.Client, name string) *corev1.Secret {
+		secret := &corev1.Secret{}
+		Expect(cl.Get(context.Background(), types.NamespacedName{Namespace: ns, Name: name}, secret)).To(Succeed())
+		return secret
+	}
+
+	It("generates a key and a DER certificate for module signing", func() {
+		ctx := context.Background()
+		cl := newClient()
+		config := generateConfig()
+		Expect(config.SigningGenerate).To(BeTrue())
+		Expect(EnsureSigningKeypair(ctx, cl, ns, &config)).To(Succeed())
+		Expect(SigningSecretsExist(ctx, cl, ns, &config)).To(BeTrue())
+
+		block, _ := pem.Decode(getSecret(cl, SecureBootKey).Data[SigningKeyEntry])
+		Expect(block).ToNot(BeNil())
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		Expect(err).ToNot(HaveOccurred())
+
+		cert, err := x509.ParseCertificate(getSecret(cl, SecureBootKeyPub).Data[SigningCertEntry])
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cert.Subject.CommonName).To(Equal(signingCommonName))
+		Expect(cert.ExtKeyUsage).To(ConsistOf(x509.ExtKeyUsageCodeSigning))
+		Expect(cert.IsCA).To(BeFalse())
+		Expect(cert.CheckSignature(cert.SignatureAlgorithm, cert.RawTBSCertificate, cert.Signature)).To(Succeed())
+		Expect(key.(*rsa.PrivateKey).PublicKey.Equal(cert.PublicKey)).To(BeTrue())
+
+		// The keypair is never replaced, since it may already be enrolled on the nodes
+		Expect(EnsureSigningKeypair(ctx, cl, ns, &config)).To(Succeed())
+		Expect(getSecret(cl, SecureBootKeyPub).Data[SigningCertEntry]).To(Equal(cert.Raw))
+	})
+
+	It("does not complete a keypair of which one secret is missing", func() {
+		ctx := context.Background()
+		cl := newClient(newSigningSecret(ns, SecureBootKey, SigningKeyEntry, []byte("key")))
+		config := generateConfig()
+		Expect(EnsureSigningKeypair(ctx, cl, ns, &config)).To(MatchError(ContainSubstring("only one of the signing secrets")))
+		Expect(SigningSecretsExist(ctx, cl, ns, &config)).To(BeFalse())
+	})
+
+	It("only generates the keypair when asked to", func() {
+		ctx := context.Background()
+		cl := newClient()
+		config := NewKMMImageConfig(ns, &v1alpha1.KernelModuleConfig{Signing: &v1alpha1.KernelModuleSigning{
+			KeySecret: SecureBootKey, CertSecret: SecureBootKeyPub,
+		}})
+		Expect(EnsureSigningKeypair(ctx, cl, ns, &config)).To(Succeed())
+		Expect(SigningSecretsExist(ctx, cl, ns, &config)).To(BeFalse())
+	})
+})
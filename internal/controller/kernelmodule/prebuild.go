@@ -0,0 +1,135 @@
+package kernelmodule
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/openshift-storage-scale/openshift-fusion-access-operator/api/v1alpha1"
+	"github.com/openshift-storage-scale/openshift-fusion-access-operator/internal/kubeutils"
+
+	kmmv1beta1 "github.com/rh-ecosystem-edge/kernel-module-management/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// KMMPrebuildName is the name of the Module used to build the kernel modules for a new core image
+// before the Storage Scale upgrade is started
+const KMMPrebuildName = KMMModuleName + "-prebuild"
+
+// KMMPrebuildNameFor returns the name of the pre-build Module of an architecture
+func KMMPrebuildNameFor(arch string) string {
+	if arch == defaultArchitecture {
+		return KMMPrebuildName
+	}
+	return KMMPrebuildName + "-" + arch
+}
+
+// PrebuildKMMImages asks KMM to build (and push) the kernel module images for the given core image,
+// for every kernel currently running on the storage nodes. This is done through a Module per architecture
+// with the version of the Storage Scale upgrade: KMM builds the images of a versioned Module for all the
+// selected nodes, but only loads it on the nodes labeled with its version, which we never set, so
+// nothing gets loaded on the nodes. It returns true once all the images exist in the registry
+func PrebuildKMMImages(ctx context.Context, cl client.Client, namespace, ibmScaleImage, version string) (bool, error) {
+	KMMImageConfig, err := GetKMMImageConfig(ctx, cl, namespace)
+	if err != nil {
+		return false, fmt.Errorf("failed to get KMMImageConfigmap in PrebuildKMMImages: %w", err)
+	}
+	kernels, err := getStorageNodeKernels(ctx, cl)
+	if err != nil {
+		return false, fmt.Errorf("failed to get storage node kernels in PrebuildKMMImages: %w", err)
+	}
+	if len(kernels) == 0 {
+		log.Log.Info("No storage nodes found, nothing to pre-build")
+		return true, nil
+	}
+
+	if err := EnsureSigningKeypair(ctx, cl, namespace, &KMMImageConfig); err != nil {
+		return false, fmt.Errorf("failed to generate the signing secrets in PrebuildKMMImages: %w", err)
+	}
+	signModules, err := shouldSignModules(ctx, cl, namespace, &KMMImageConfig)
+	if err != nil {
+		return false, fmt.Errorf("failed to check the signing secrets in PrebuildKMMImages: %w", err)
+	}
+
+	ready := true
+	for _, arch := range kmmArchitectures {
+		name := KMMPrebuildNameFor(arch.Arch)
+		archKernels := slices.DeleteFunc(slices.Clone(kernels), func(kernel string) bool {
+			return getKernelArchitecture(kernel) != arch.Arch
+		})
+		if len(archKernels) == 0 {
+			if err := deleteKMMModule(ctx, cl, namespace, name); err != nil {
+				return false, fmt.Errorf("failed to delete pre-build module in PrebuildKMMImages: %w", err)
+			}
+			continue
+		}
+		module := NewKMMPrebuild(namespace, arch.Arch, ibmScaleImage, version, signModules, &KMMImageConfig)
+		if err := kubeutils.CreateOrUpdateResource(ctx, cl, module, mutateKMMModule); err != nil {
+			return false, fmt.Errorf("failed to update pre-build module in PrebuildKMMImages: %w", err)
+		}
+		builds, err := getModuleBuildStatus(ctx, cl, namespace, name)
+		if err != nil {
+			return false, fmt.Errorf("failed to get the builds of module %s in PrebuildKMMImages: %w", name, err)
+		}
+		if !arePrebuiltImagesReady(&KMMImageConfig, ibmScaleImage, archKernels, builds) {
+			ready = false
+		}
+	}
+	return ready, nil
+}
+
+// arePrebuiltImagesReady returns true when KMM reports the image of every kernel as existing
+func arePrebuiltImagesReady(kmmImageConfig *KMMImageConfig, ibmScaleImage string, kernels []string, builds []v1alpha1.KernelModuleBuild) bool {
+	for _, kernel := range kernels {
+		image := kmmImage(kmmImageConfig, ibmScaleImage, kernel)
+		if !slices.ContainsFunc(builds, func(build v1alpha1.KernelModuleBuild) bool {
+			return build.Image == image && build.State == v1alpha1.KernelModuleBuildReady
+		}) {
+			return false
+		}
+	}
+	return true
+}
+
+// DeleteKMMPrebuild removes the pre-build Modules once the upgrade is done. KMM removes their
+// ModuleImagesConfigs with them. The built images stay in the registry and are picked up by the
+// gpfs-module Modules
+func DeleteKMMPrebuild(ctx context.Context, cl client.Client, namespace string) error {
+	for _, arch := range kmmArchitectures {
+		if err := deleteKMMModule(ctx, cl, namespace, KMMPrebuildNameFor(arch.Arch)); err != nil {
+			return fmt.Errorf("failed to delete pre-build module in DeleteKMMPrebuild: %w", err)
+		}
+	}
+	return nil
+}
+
+// NewKMMPrebuild returns the pre-build Module of an architecture. It is the gpfs-module Module for the new
+// core image, with the version of the upgrade so that it is never loaded
+func NewKMMPrebuild(namespace, arch, ibmScaleImage, version string, sign bool, kmmImageConfig *KMMImageConfig) *kmmv1beta1.Module {
+	module := NewKMMModule(namespace, arch, ibmScaleImage, sign, kmmImageConfig)
+	module.Name = KMMPrebuildNameFor(arch)
+	module.Spec.ModuleLoader.Container.Version = version
+	return module
+}
+
+// getStorageNodeKernels returns the sorted list of kernels running on the nodes selected by the gpfs-module Modules
+func getStorageNodeKernels(ctx context.Context, cl client.Client) ([]string, error) {
+	nodes := &corev1.NodeList{}
+	if err := cl.List(ctx, nodes, client.MatchingLabels{KMMNodeSelectorKey: KMMNodeSelectorValue}); err != nil {
+		return nil, err
+	}
+	kernels := []string{}
+	for _, node := range nodes.Items {
+		kernel := node.Status.NodeInfo.KernelVersion
+		if getKMMArchitecture(node.Labels[corev1.LabelArchStable]) == nil || getKernelArchitecture(kernel) == "" {
+			continue
+		}
+		if !slices.Contains(kernels, kernel) {
+			kernels = append(kernels, kernel)
+		}
+	}
+	slices.Sort(kernels)
+	return kernels, nil
+}
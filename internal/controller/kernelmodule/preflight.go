@@ -0,0 +1,125 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kernelmodule
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	kmmv1beta2 "github.com/rh-ecosystem-edge/kernel-module-management/api/v1beta2"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/openshift-storage-scale/openshift-fusion-access-operator/api/v1alpha1"
+)
+
+const (
+	// KMMPreflightName is the name of the cluster scoped PreflightValidationOCP we create ahead of an OpenShift upgrade
+	KMMPreflightName = "gpfs-module-preflight"
+	// kmmPreflightModuleGenerationAnnotation records the generation of the Module a preflight validation was
+	// started for, since KMM does not validate the Module again when it changes
+	kmmPreflightModuleGenerationAnnotation = "fusion.storage.openshift.io/module-generation"
+)
+
+// +kubebuilder:rbac:groups=kmm.sigs.x-k8s.io,resources=preflightvalidationsocp,verbs=get;list;watch;create;update;patch;delete
+
+// KMMModuleNameForKernel returns the name of the Module building the kernel module for a kernel release,
+// or an empty string when the architecture of the kernel is not supported
+func KMMModuleNameForKernel(kernel string) string {
+	arch := getKernelArchitecture(kernel)
+	if arch == "" {
+		return ""
+	}
+	return KMMModuleNameFor(arch)
+}
+
+// NewKMMPreflight returns the PreflightValidationOCP building and pushing the kernel module images for the
+// kernel of the target OpenShift release
+func NewKMMPreflight(preflight *v1alpha1.KernelModuleUpgradePreflight, moduleGeneration int64) *kmmv1beta2.PreflightValidationOCP {
+	return &kmmv1beta2.PreflightValidationOCP{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: KMMPreflightName,
+			Annotations: map[string]string{
+				kmmPreflightModuleGenerationAnnotation: strconv.FormatInt(moduleGeneration, 10),
+			},
+		},
+		Spec: kmmv1beta2.PreflightValidationOCPSpec{
+			KernelVersion:  preflight.KernelVersion,
+			DTKImage:       preflight.DTKImage,
+			PushBuiltImage: true,
+		},
+	}
+}
+
+// CreateOrUpdateKMMPreflight creates the PreflightValidationOCP and returns it once it matches the desired one.
+// KMM only runs a preflight validation once, so an existing one that was started for another release or
+// another generation of the Module is deleted, and nil is returned until it is created again on a later reconcile
+func CreateOrUpdateKMMPreflight(ctx context.Context, cl client.Client, desired *kmmv1beta2.PreflightValidationOCP) (*kmmv1beta2.PreflightValidationOCP, error) {
+	existing := &kmmv1beta2.PreflightValidationOCP{}
+	if err := cl.Get(ctx, types.NamespacedName{Name: desired.Name}, existing); err != nil {
+		if !errors.IsNotFound(err) {
+			return nil, fmt.Errorf("failed to get PreflightValidationOCP %s in CreateOrUpdateKMMPreflight: %w", desired.Name, err)
+		}
+		if err := cl.Create(ctx, desired); err != nil {
+			return nil, fmt.Errorf("failed to create PreflightValidationOCP %s in CreateOrUpdateKMMPreflight: %w", desired.Name, err)
+		}
+		log.Log.Info("Created the KMM preflight validation for the target OpenShift release", "kernel", desired.Spec.KernelVersion)
+		return nil, nil
+	}
+	if !existing.DeletionTimestamp.IsZero() {
+		return nil, nil
+	}
+	if existing.Spec == desired.Spec &&
+		existing.Annotations[kmmPreflightModuleGenerationAnnotation] == desired.Annotations[kmmPreflightModuleGenerationAnnotation] {
+		return existing, nil
+	}
+	log.Log.Info("Restarting the KMM preflight validation", "kernel", desired.Spec.KernelVersion)
+	if err := cl.Delete(ctx, existing); err != nil && !errors.IsNotFound(err) {
+		return nil, fmt.Errorf("failed to delete PreflightValidationOCP %s in CreateOrUpdateKMMPreflight: %w", desired.Name, err)
+	}
+	return nil, nil
+}
+
+// DeleteKMMPreflight deletes the PreflightValidationOCP, if it exists
+func DeleteKMMPreflight(ctx context.Context, cl client.Client) error {
+	preflight := &kmmv1beta2.PreflightValidationOCP{
+		ObjectMeta: metav1.ObjectMeta{Name: KMMPreflightName},
+	}
+	if err := cl.Delete(ctx, preflight); err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete PreflightValidationOCP %s in DeleteKMMPreflight: %w", KMMPreflightName, err)
+	}
+	return nil
+}
+
+// GetKMMPreflightModuleStatus returns the result of the preflight validation of a Module, or nil when KMM
+// has not started validating it
+func GetKMMPreflightModuleStatus(preflight *kmmv1beta2.PreflightValidationOCP, namespace, name string) *kmmv1beta2.PreflightValidationModuleStatus {
+	if preflight == nil {
+		return nil
+	}
+	for idx := range preflight.Status.Modules {
+		status := &preflight.Status.Modules[idx]
+		if status.Namespace == namespace && status.Name == name {
+			return status
+		}
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kernelmodule
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// SigningKeyEntry and SigningCertEntry are the entries of the signing secrets KMM reads. The key is PEM
+	// encoded, the certificate is DER encoded so it can be enrolled with mokutil as is
+	SigningKeyEntry  = "key"
+	SigningCertEntry = "cert"
+	// signingCommonName is the subject of the generated certificate, shown by mokutil --list-enrolled
+	signingCommonName = "Fusion Access kernel module signing key"
+	// signingKeyBits is the size of the generated RSA key
+	signingKeyBits = 4096
+	// signingCertValidity is how long the generated certificate is valid. The kernel does not check it
+	// when loading a module, but some firmware refuses to enroll an expired certificate
+	signingCertValidity = 10 * 365 * 24 * time.Hour
+)
+
+// EnsureSigningKeypair creates the signing secrets with a new key and self-signed certificate when the kernel
+// module configuration asks for it and neither secret exists. A single existing secret is an error, since
+// replacing it would break the key that may already be enrolled on the nodes
+func EnsureSigningKeypair(ctx context.Context, cl client.Client, namespace string, kmmImageConfig *KMMImageConfig) error {
+	if !kmmImageConfig.SigningGenerate {
+		return nil
+	}
+	keyExists, err := doesSecretExist(ctx, cl, namespace, kmmImageConfig.SigningKeySecret)
+	if err != nil {
+		return err
+	}
+	certExists, err := doesSecretExist(ctx, cl, namespace, kmmImageConfig.SigningCertSecret)
+	if err != nil {
+		return err
+	}
+	if keyExists && certExists {
+		return nil
+	}
+	if keyExists || certExists {
+		return fmt.Errorf("only one of the signing secrets %s and %s exists, delete it to generate a new keypair or create the other one",
+			kmmImageConfig.SigningKeySecret, kmmImageConfig.SigningCertSecret)
+	}
+
+	keyPEM, certDER, err := generateSigningKeypair(time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to generate the signing keypair in EnsureSigningKeypair: %w", err)
+	}
+	// The certificate is created last, so that a failure in between is reported instead of leaving
+	// a certificate whose key is lost
+	if err := cl.Create(ctx, newSigningSecret(namespace, kmmImageConfig.SigningKeySecret, SigningKeyEntry, keyPEM)); err != nil {
+		return fmt.Errorf("failed to create secret %s in EnsureSigningKeypair: %w", kmmImageConfig.SigningKeySecret, err)
+	}
+	if err := cl.Create(ctx, newSigningSecret(namespace, kmmImageConfig.SigningCertSecret, SigningCertEntry, certDER)); err != nil {
+		return fmt.Errorf("failed to create secret %s in EnsureSigningKeypair: %w", kmmImageConfig.SigningCertSecret, err)
+	}
+	log.Log.Info("Generated the kernel module signing keypair, its certificate needs to be enrolled in the MOK database of the Secure Boot nodes",
+		"keySecret", kmmImageConfig.SigningKeySecret, "certSecret", kmmImageConfig.SigningCertSecret)
+	return nil
+}
+
+// SigningSecretsExist returns true when the kernel modules are signed, i.e. when both signing secrets exist
+func SigningSecretsExist(ctx context.Context, cl client.Client, namespace string, kmmImageConfig *KMMImageConfig) bool {
+	return doSigningSecretsExist(ctx, cl, namespace, kmmImageConfig.SigningKeySecret, kmmImageConfig.SigningCertSecret)
+}
+
+// doesSecretExist returns true when the secret exists
+func doesSecretExist(ctx context.Context, cl client.Client, namespace, name string) (bool, error) {
+	if err := cl.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, &corev1.Secret{}); err != nil {
+		if errors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to get secret %s: %w", name, err)
+	}
+	return true, nil
+}
+
+// newSigningSecret returns a signing secret with a single entry
+func newSigningSecret(namespace, name, entry string, data []byte) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: map[string][]byte{entry: data},
+	}
+}
+
+// generateSigningKeypair returns a PEM encoded RSA key and a DER encoded self-signed certificate usable
+// to sign kernel modules, with the same usages as the kernel's own signing certificate
+func generateSigningKeypair(now time.Time) ([]byte, []byte, error) {
+	key, err := rsa.GenerateKey(rand.Reader, signingKeyBits)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate the key: %w", err)
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate the serial number: %w", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: signingCommonName},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(signingCertValidity),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+		BasicConstraintsValid: true,
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create the certificate: %w", err)
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode the key: %w", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}), certDER, nil
+}
@@ -0,0 +1,212 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kernelmodule
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	kmmv1beta1 "github.com/rh-ecosystem-edge/kernel-module-management/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+
+	"github.com/openshift-storage-scale/openshift-fusion-access-operator/api/v1alpha1"
+)
+
+// Labels KMM sets on the pods building and signing the kernel module images
+const (
+	KMMModuleNameLabel   = "kmm.node.kubernetes.io/module.name"
+	KMMTargetKernelLabel = "kmm.node.kubernetes.io/target-kernel"
+	KMMPodTypeLabel      = "kmm.node.kubernetes.io/pod-type"
+	KMMPodTypeBuild      = "build"
+	KMMPodTypeSign       = "sign"
+)
+
+const (
+	// failedPodLogLines is the number of lines of the log of a failed build or sign pod that are reported
+	failedPodLogLines = 20
+	// maxFailedPodLogLength keeps the reported log short enough for the FusionAccess conditions
+	maxFailedPodLogLength = 2048
+)
+
+// +kubebuilder:rbac:groups=kmm.sigs.x-k8s.io,resources=moduleimagesconfigs,verbs=get;list;watch
+// +kubebuilder:rbac:groups=kmm.sigs.x-k8s.io,resources=modulebuildsignconfigs,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=pods/log,verbs=get
+
+// GetKMMBuildStatus returns the state of the kernel module image of each kernel of the gpfs-module Modules.
+// KMM lists the images of a Module in the ModuleImagesConfig of the same name, and reports the failed builds
+// and signs in the ModuleBuildSignConfig of the same name
+func GetKMMBuildStatus(ctx context.Context, cl client.Client, namespace string) ([]v1alpha1.KernelModuleBuild, error) {
+	modules, err := ListKMMModules(ctx, cl, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list modules in GetKMMBuildStatus: %w", err)
+	}
+	builds := []v1alpha1.KernelModuleBuild{}
+	for idx := range modules {
+		moduleBuilds, err := getModuleBuildStatus(ctx, cl, namespace, modules[idx].Name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the builds of module %s in GetKMMBuildStatus: %w", modules[idx].Name, err)
+		}
+		builds = append(builds, moduleBuilds...)
+	}
+	return builds, nil
+}
+
+// getModuleBuildStatus returns the state of the kernel module images of a Module
+func getModuleBuildStatus(ctx context.Context, cl client.Client, namespace, name string) ([]v1alpha1.KernelModuleBuild, error) {
+	key := types.NamespacedName{Namespace: namespace, Name: name}
+	mic := &kmmv1beta1.ModuleImagesConfig{}
+	if err := cl.Get(ctx, key, mic); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get ModuleImagesConfig %s: %w", name, err)
+	}
+	mbsc := &kmmv1beta1.ModuleBuildSignConfig{}
+	if err := cl.Get(ctx, key, mbsc); err != nil && !errors.IsNotFound(err) {
+		return nil, fmt.Errorf("failed to get ModuleBuildSignConfig %s: %w", name, err)
+	}
+
+	builds := make([]v1alpha1.KernelModuleBuild, 0, len(mic.Spec.Images))
+	for _, image := range mic.Spec.Images {
+		build := v1alpha1.KernelModuleBuild{
+			KernelVersion: image.KernelVersion,
+			Module:        name,
+			Image:         image.Image,
+			State:         getImageBuildState(image.Image, mic, mbsc),
+		}
+		if build.State == v1alpha1.KernelModuleBuildBuildFailed || build.State == v1alpha1.KernelModuleBuildSignFailed {
+			podType := KMMPodTypeBuild
+			if build.State == v1alpha1.KernelModuleBuildSignFailed {
+				podType = KMMPodTypeSign
+			}
+			message, err := getFailedPodLog(ctx, cl, namespace, name, image.KernelVersion, podType)
+			if err != nil {
+				return nil, err
+			}
+			build.Message = message
+		}
+		builds = append(builds, build)
+	}
+	slices.SortFunc(builds, func(a, b v1alpha1.KernelModuleBuild) int {
+		return strings.Compare(a.KernelVersion, b.KernelVersion)
+	})
+	return builds, nil
+}
+
+// getImageBuildState returns the state of an image. An image that exists is ready even if an earlier
+// build failed, since KMM does not clear the failures of the ModuleBuildSignConfig
+func getImageBuildState(image string, mic *kmmv1beta1.ModuleImagesConfig, mbsc *kmmv1beta1.ModuleBuildSignConfig) v1alpha1.KernelModuleBuildState {
+	idx := slices.IndexFunc(mic.Status.ImagesStates, func(state kmmv1beta1.ModuleImageState) bool {
+		return state.Image == image
+	})
+	if idx != -1 && mic.Status.ImagesStates[idx].Status == kmmv1beta1.ImageExists {
+		return v1alpha1.KernelModuleBuildReady
+	}
+	for _, state := range mbsc.Status.Images {
+		if state.Image != image || state.Status != kmmv1beta1.ActionFailure {
+			continue
+		}
+		if state.Action == kmmv1beta1.SignImage {
+			return v1alpha1.KernelModuleBuildSignFailed
+		}
+		return v1alpha1.KernelModuleBuildBuildFailed
+	}
+	if idx == -1 {
+		return v1alpha1.KernelModuleBuildPending
+	}
+	switch mic.Status.ImagesStates[idx].Status {
+	case kmmv1beta1.ImageNeedsBuilding:
+		return v1alpha1.KernelModuleBuildBuilding
+	case kmmv1beta1.ImageNeedsSigning:
+		return v1alpha1.KernelModuleBuildSigning
+	case kmmv1beta1.ImageDoesNotExist:
+		return v1alpha1.KernelModuleBuildMissing
+	default:
+		return v1alpha1.KernelModuleBuildPending
+	}
+}
+
+// getFailedPodLog returns the tail of the log of the last failed build or sign pod of a kernel, or an
+// empty string when KMM already removed the pod
+func getFailedPodLog(ctx context.Context, cl client.Client, namespace, module, kernel, podType string) (string, error) {
+	pods := &corev1.PodList{}
+	if err := cl.List(ctx, pods, client.InNamespace(namespace), client.MatchingLabels{
+		KMMModuleNameLabel: module,
+		KMMPodTypeLabel:    podType,
+	}); err != nil {
+		return "", fmt.Errorf("failed to list %s pods of module %s: %w", podType, module, err)
+	}
+	var failed *corev1.Pod
+	for idx := range pods.Items {
+		pod := &pods.Items[idx]
+		if pod.Status.Phase != corev1.PodFailed || pod.Labels[KMMTargetKernelLabel] != kmmKernelLabel(kernel) {
+			continue
+		}
+		if failed == nil || failed.CreationTimestamp.Before(&pod.CreationTimestamp) {
+			failed = pod
+		}
+	}
+	if failed == nil {
+		return "", nil
+	}
+	logs, err := GetPodLogTail(ctx, namespace, failed.Name, failedPodLogLines)
+	if err != nil {
+		// The state of the build is still worth reporting without its log
+		return fmt.Sprintf("%s pod %s failed, its log cannot be read: %v", podType, failed.Name, err), nil
+	}
+	logs = strings.TrimSpace(logs)
+	if len(logs) > maxFailedPodLogLength {
+		logs = logs[len(logs)-maxFailedPodLogLength:]
+	}
+	return fmt.Sprintf("%s pod %s failed: %s", podType, failed.Name, logs), nil
+}
+
+// kmmKernelLabel returns the value of the target kernel label KMM sets for a kernel. Label values cannot
+// contain the + found in some kernel releases
+func kmmKernelLabel(kernel string) string {
+	return strings.ReplaceAll(kernel, "+", "_")
+}
+
+// GetPodLogTail returns the last lines of the log of a pod. It is a variable so it can be mocked
+var GetPodLogTail = func(ctx context.Context, namespace, name string, lines int64) (string, error) {
+	restConfig, err := config.GetConfig()
+	if err != nil {
+		return "", fmt.Errorf("failed to get the client config in GetPodLogTail: %w", err)
+	}
+	clientset, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return "", fmt.Errorf("failed to create the clientset in GetPodLogTail: %w", err)
+	}
+	stream, err := clientset.CoreV1().Pods(namespace).GetLogs(name, &corev1.PodLogOptions{TailLines: &lines}).Stream(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to get the log of pod %s in GetPodLogTail: %w", name, err)
+	}
+	defer stream.Close()
+	logs, err := io.ReadAll(stream)
+	if err != nil {
+		return "", fmt.Errorf("failed to read the log of pod %s in GetPodLogTail: %w", name, err)
+	}
+	return string(logs), nil
+}
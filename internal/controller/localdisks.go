@@ -0,0 +1,127 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	meta "k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	fusionv1alpha1 "github.com/openshift-storage-scale/openshift-fusion-access-operator/api/v1alpha1"
+	"github.com/openshift-storage-scale/openshift-fusion-access-operator/internal/common"
+)
+
+// LocalDiskGVK is the kind of the IBM Storage Scale LocalDisk objects, which are read as unstructured objects
+var LocalDiskGVK = common.LocalDiskGVK
+
+// countProvisionedDevices counts the LocalDisks created in the Storage Scale namespace, in total, per node
+// and per filesystem. The lists are sorted by name so that the status only changes when the counts do
+func countProvisionedDevices(ctx context.Context, cl client.Client) (int32, []fusionv1alpha1.ProvisionedDeviceCount, []fusionv1alpha1.ProvisionedDeviceCount, error) {
+	localDisks := &unstructured.UnstructuredList{}
+	localDisks.SetGroupVersionKind(LocalDiskGVK.GroupVersion().WithKind(LocalDiskGVK.Kind + "List"))
+	if err := cl.List(ctx, localDisks, client.InNamespace(StorageScaleNamespace)); err != nil {
+		return 0, nil, nil, err
+	}
+
+	byNode := map[string]int32{}
+	byFilesystem := map[string]int32{}
+	for _, localDisk := range localDisks.Items {
+		if node, _, _ := unstructured.NestedString(localDisk.Object, "spec", "node"); node != "" {
+			byNode[node]++
+		}
+		if filesystem, _, _ := unstructured.NestedString(localDisk.Object, "status", "filesystem"); filesystem != "" {
+			byFilesystem[filesystem]++
+		}
+	}
+	return int32(len(localDisks.Items)), toProvisionedDeviceCounts(byNode), toProvisionedDeviceCounts(byFilesystem), nil
+}
+
+func toProvisionedDeviceCounts(counts map[string]int32) []fusionv1alpha1.ProvisionedDeviceCount {
+	result := make([]fusionv1alpha1.ProvisionedDeviceCount, 0, len(counts))
+	for name, count := range counts {
+		result = append(result, fusionv1alpha1.ProvisionedDeviceCount{Name: name, Count: count})
+	}
+	slices.SortFunc(result, func(a, b fusionv1alpha1.ProvisionedDeviceCount) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return result
+}
+
+// updateProvisionedDeviceCount sets the provisioned device counts in the status. Until the manifest
+// installed the LocalDisk CRD there is nothing to count. The caller is responsible for updating the status
+func (r *FusionAccessReconciler) updateProvisionedDeviceCount(ctx context.Context, fusionaccess *fusionv1alpha1.FusionAccess) error {
+	total, byNode, byFilesystem, err := countProvisionedDevices(ctx, r.Client)
+	if meta.IsNoMatchError(err) {
+		log.Log.Info("LocalDisk CRD not installed yet, skipping provisioned device count")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to list LocalDisks in updateProvisionedDeviceCount: %w", err)
+	}
+	fusionaccess.Status.TotalProvisionedDeviceCount = &total
+	fusionaccess.Status.ProvisionedDevicesByNode = byNode
+	fusionaccess.Status.ProvisionedDevicesByFilesystem = byFilesystem
+	return nil
+}
+
+// watchLocalDisks starts watching the LocalDisks once the manifest installed their CRD. This cannot
+// be done in SetupWithManager since the CRD does not exist when the operator is first installed
+func (r *FusionAccessReconciler) watchLocalDisks() error {
+	r.localDiskWatchLock.Lock()
+	defer r.localDiskWatchLock.Unlock()
+	if r.localDiskWatchStarted || r.controller == nil {
+		return nil
+	}
+	localDisk := &unstructured.Unstructured{}
+	localDisk.SetGroupVersionKind(LocalDiskGVK)
+	src := source.Kind(r.cache, client.Object(localDisk),
+		handler.EnqueueRequestsFromMapFunc(r.fusionAccessHandler), didTheLocalDiskCountChange())
+	if err := r.controller.Watch(src); err != nil {
+		return fmt.Errorf("failed to watch LocalDisks in watchLocalDisks: %w", err)
+	}
+	r.localDiskWatchStarted = true
+	log.Log.Info("Watching LocalDisks")
+	return nil
+}
+
+// didTheLocalDiskCountChange ignores the LocalDisk updates that do not change the counts, like condition updates
+func didTheLocalDiskCountChange() predicate.Predicate {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldDisk, okOld := e.ObjectOld.(*unstructured.Unstructured)
+			newDisk, okNew := e.ObjectNew.(*unstructured.Unstructured)
+			if !okOld || !okNew {
+				return false
+			}
+			oldNode, _, _ := unstructured.NestedString(oldDisk.Object, "spec", "node")
+			newNode, _, _ := unstructured.NestedString(newDisk.Object, "spec", "node")
+			oldFilesystem, _, _ := unstructured.NestedString(oldDisk.Object, "status", "filesystem")
+			newFilesystem, _, _ := unstructured.NestedString(newDisk.Object, "status", "filesystem")
+			return oldNode != newNode || oldFilesystem != newFilesystem
+		},
+	}
+}
@@ -0,0 +1,86 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	meta "k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	fusionv1alpha "github.com/openshift-storage-scale/openshift-fusion-access-operator/api/v1alpha1"
+)
+
+func newLocalDisk(name, node, filesystem string) *unstructured.Unstructured {
+	localDisk := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{"node": node, "device": "/dev/sdb"},
+	}}
+	localDisk.SetGroupVersionKind(LocalDiskGVK)
+	localDisk.SetName(name)
+	localDisk.SetNamespace(StorageScaleNamespace)
+	if filesystem != "" {
+		Expect(unstructured.SetNestedField(localDisk.Object, filesystem, "status", "filesystem")).To(Succeed())
+	}
+	return localDisk
+}
+
+var _ = Describe("Provisioned device count", func() {
+	var ctx = context.Background()
+
+	It("counts the LocalDisks in total, per node and per filesystem", func() {
+		scheme := createFakeScheme()
+		scheme.AddKnownTypeWithName(LocalDiskGVK, &unstructured.Unstructured{})
+		scheme.AddKnownTypeWithName(LocalDiskGVK.GroupVersion().WithKind("LocalDiskList"), &unstructured.UnstructuredList{})
+		cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			newLocalDisk("disk-a", "worker-1", "fs1"),
+			newLocalDisk("disk-b", "worker-0", "fs1"),
+			newLocalDisk("disk-c", "worker-0", "fs2"),
+			newLocalDisk("disk-d", "worker-0", ""),
+		).Build()
+		reconciler := &FusionAccessReconciler{Client: cl, Scheme: scheme}
+
+		fa := &fusionv1alpha.FusionAccess{}
+		Expect(reconciler.updateProvisionedDeviceCount(ctx, fa)).To(Succeed())
+		Expect(*fa.Status.TotalProvisionedDeviceCount).To(Equal(int32(4)))
+		Expect(fa.Status.ProvisionedDevicesByNode).To(Equal([]fusionv1alpha.ProvisionedDeviceCount{
+			{Name: "worker-0", Count: 3},
+			{Name: "worker-1", Count: 1},
+		}))
+		Expect(fa.Status.ProvisionedDevicesByFilesystem).To(Equal([]fusionv1alpha.ProvisionedDeviceCount{
+			{Name: "fs1", Count: 2},
+			{Name: "fs2", Count: 1},
+		}))
+	})
+
+	It("leaves the count unset until the LocalDisk CRD is installed", func() {
+		cl := fake.NewClientBuilder().WithScheme(createFakeScheme()).WithInterceptorFuncs(interceptor.Funcs{
+			List: func(_ context.Context, _ client.WithWatch, _ client.ObjectList, _ ...client.ListOption) error {
+				return &meta.NoKindMatchError{GroupKind: LocalDiskGVK.GroupKind()}
+			},
+		}).Build()
+		reconciler := &FusionAccessReconciler{Client: cl}
+
+		fa := &fusionv1alpha.FusionAccess{}
+		Expect(reconciler.updateProvisionedDeviceCount(ctx, fa)).To(Succeed())
+		Expect(fa.Status.TotalProvisionedDeviceCount).To(BeNil())
+	})
+
+	DescribeTable("only reconciles when the counts can change",
+		func(mutate func(localDisk *unstructured.Unstructured), expected bool) {
+			oldDisk := newLocalDisk("disk-a", "worker-0", "")
+			newDisk := oldDisk.DeepCopy()
+			mutate(newDisk)
+			Expect(didTheLocalDiskCountChange().Update(event.UpdateEvent{ObjectOld: oldDisk, ObjectNew: newDisk})).To(Equal(expected))
+		},
+		Entry("added to a filesystem", func(localDisk *unstructured.Unstructured) {
+			Expect(unstructured.SetNestedField(localDisk.Object, "fs1", "status", "filesystem")).To(Succeed())
+		}, true),
+		Entry("condition update", func(localDisk *unstructured.Unstructured) {
+			Expect(unstructured.SetNestedField(localDisk.Object, "shared", "status", "type")).To(Succeed())
+		}, false),
+	)
+})
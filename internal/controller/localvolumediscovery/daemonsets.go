@@ -120,7 +120,7 @@ func MutateAggregatedSpec(
 
 	// setting maxUnavailable as a percentage
 	ds.Spec.UpdateStrategy = dsTemplate.Spec.UpdateStrategy
-	// to read /proc/1/mountinfo
+	// to read the processes and the root of the host in /proc
 	ds.Spec.Template.Spec.HostPID = dsTemplate.Spec.Template.Spec.HostPID
 }
 
@@ -19,6 +19,7 @@ func NewLocalVolumeDiscovery(namespace string) *fusionv1alpha.LocalVolumeDiscove
 		},
 	}
 }
+
 func CreateOrUpdateLocalVolumeDiscovery(ctx context.Context, devicefinder *fusionv1alpha.LocalVolumeDiscovery, cl client.Client) error {
 	oldCP := &fusionv1alpha.LocalVolumeDiscovery{}
 	if err := cl.Get(ctx, client.ObjectKeyFromObject(devicefinder), oldCP); apierrors.IsNotFound(err) {
@@ -36,3 +37,12 @@ func CreateOrUpdateLocalVolumeDiscovery(ctx context.Context, devicefinder *fusio
 	}
 	return nil
 }
+
+// DeleteLocalVolumeDiscovery deletes the LocalVolumeDiscovery created by the operator.
+// The device finder daemonset and the discovery results are owned by it and get garbage collected
+func DeleteLocalVolumeDiscovery(ctx context.Context, namespace string, cl client.Client) error {
+	if err := cl.Delete(ctx, NewLocalVolumeDiscovery(namespace)); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("could not delete device finder: %w", err)
+	}
+	return nil
+}
@@ -62,6 +62,8 @@ type LocalVolumeDiscoveryReconciler struct {
 // This is needed for the binary running in the containers (daemonset) to sync the results
 //+kubebuilder:rbac:groups=fusion.storage.openshift.io,resources=localvolumediscoveryresults,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=fusion.storage.openshift.io,resources=localvolumediscoveryresults/status,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=fusion.storage.openshift.io,resources=nodefacts,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=fusion.storage.openshift.io,resources=nodefacts/status,verbs=get;list;watch;create;update;patch;delete
 
 // Reconcile reads that state of the cluster for a LocalVolumeDiscovery object and makes changes based on the state read
 // and what is in the LocalVolumeDiscovery.Spec
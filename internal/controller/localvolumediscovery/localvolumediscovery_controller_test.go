@@ -270,4 +270,17 @@ var _ = Describe("LocalVolumeDiscoveryReconciler", func() {
 			Expect(results.Items).To(BeEmpty())
 		})
 	})
+
+	Context("discovery DaemonSet", func() {
+		// The host facts of the NodeFacts, like multipathd running or the Secure Boot state read through
+		// /proc/1/root, are only the ones of the host in its PID namespace
+		It("runs the discovery daemon in the PID namespace of the host", func() {
+			fakeReconciler := newFakeLocalVolumeDiscoveryReconciler()
+			req := ctrl.Request{NamespacedName: types.NamespacedName{Name: name, Namespace: namespace}}
+			ds := &appsv1.DaemonSet{}
+			mutate := getDeviceFinderDiscoveryDSMutateFn(req, nil, nil, nil, nil, fakeReconciler.Client.Scheme())
+			Expect(mutate(ds)).To(Succeed())
+			Expect(ds.Spec.Template.Spec.HostPID).To(BeTrue())
+		})
+	})
 })
@@ -0,0 +1,138 @@
+package operatorcondition
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+
+	operatorsv2 "github.com/operator-framework/api/pkg/operators/v2"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// OperatorConditionNameEnv is injected by OLM into the operator deployment and contains
+// the name of the OperatorCondition of our CSV
+const OperatorConditionNameEnv = "OPERATOR_CONDITION_NAME"
+
+// +kubebuilder:rbac:groups=operators.coreos.com,resources=operatorconditions,verbs=get;list;watch;update;patch
+
+// SetUpgradeable sets the Upgradeable condition on the OperatorCondition OLM created for us.
+// When the operator is not deployed through OLM there is no OperatorCondition and this is a no-op
+func SetUpgradeable(ctx context.Context, cl client.Client, namespace string, status metav1.ConditionStatus, reason, message string) error {
+	name := os.Getenv(OperatorConditionNameEnv)
+	if name == "" {
+		log.Log.V(1).Info("Not running through OLM, skipping OperatorCondition update", "env", OperatorConditionNameEnv)
+		return nil
+	}
+
+	operatorCondition := &operatorsv2.OperatorCondition{}
+	if err := cl.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, operatorCondition); err != nil {
+		if kerrors.IsNotFound(err) {
+			log.Log.Info("OperatorCondition not found, skipping update", "name", name)
+			return nil
+		}
+		return fmt.Errorf("failed to get OperatorCondition %s in SetUpgradeable: %w", name, err)
+	}
+
+	changed := meta.SetStatusCondition(&operatorCondition.Spec.Conditions, metav1.Condition{
+		Type:               operatorsv2.Upgradeable,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: operatorCondition.Generation,
+	})
+	if !changed {
+		return nil
+	}
+	if err := cl.Update(ctx, operatorCondition); err != nil {
+		return fmt.Errorf("failed to update OperatorCondition %s in SetUpgradeable: %w", name, err)
+	}
+	return nil
+}
+
+const (
+	// csvPropertiesAnnotation holds the properties OLM reads from a ClusterServiceVersion
+	csvPropertiesAnnotation = "operatorframework.io/properties"
+	// MaxOpenShiftVersionProperty is the CSV property OLM uses to block OpenShift minor upgrades
+	MaxOpenShiftVersionProperty = "olm.maxOpenShiftVersion"
+)
+
+// csvProperties is the content of the operatorframework.io/properties annotation
+type csvProperties struct {
+	Properties []csvProperty `json:"properties"`
+}
+
+type csvProperty struct {
+	Type  string          `json:"type"`
+	Value json.RawMessage `json:"value"`
+}
+
+// +kubebuilder:rbac:groups=operators.coreos.com,resources=clusterserviceversions,verbs=get;list;watch;update;patch
+
+// SetMaxOpenShiftVersion publishes the olm.maxOpenShiftVersion property on our CSV, which is what makes
+// the cluster version operator refuse to move past that OpenShift minor. An empty version removes it.
+// The CSV has the name of the OperatorCondition, when the operator is not deployed through OLM this is a no-op
+func SetMaxOpenShiftVersion(ctx context.Context, cl client.Client, namespace, version string) error {
+	name := os.Getenv(OperatorConditionNameEnv)
+	if name == "" {
+		log.Log.V(1).Info("Not running through OLM, skipping ClusterServiceVersion update", "env", OperatorConditionNameEnv)
+		return nil
+	}
+
+	csv := &unstructured.Unstructured{}
+	csv.SetGroupVersionKind(schema.GroupVersionKind{Group: "operators.coreos.com", Version: "v1alpha1", Kind: "ClusterServiceVersion"})
+	if err := cl.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, csv); err != nil {
+		if kerrors.IsNotFound(err) {
+			log.Log.Info("ClusterServiceVersion not found, skipping update", "name", name)
+			return nil
+		}
+		return fmt.Errorf("failed to get ClusterServiceVersion %s in SetMaxOpenShiftVersion: %w", name, err)
+	}
+
+	annotations := csv.GetAnnotations()
+	properties := csvProperties{}
+	if raw := annotations[csvPropertiesAnnotation]; raw != "" {
+		if err := json.Unmarshal([]byte(raw), &properties); err != nil {
+			return fmt.Errorf("failed to parse the properties of ClusterServiceVersion %s: %w", name, err)
+		}
+	}
+	updated := csvProperties{Properties: []csvProperty{}}
+	for _, property := range properties.Properties {
+		if property.Type != MaxOpenShiftVersionProperty {
+			updated.Properties = append(updated.Properties, property)
+		}
+	}
+	if version != "" {
+		value, err := json.Marshal(version)
+		if err != nil {
+			return err
+		}
+		updated.Properties = append(updated.Properties, csvProperty{Type: MaxOpenShiftVersionProperty, Value: value})
+	}
+	if reflect.DeepEqual(properties.Properties, updated.Properties) ||
+		(len(properties.Properties) == 0 && len(updated.Properties) == 0) {
+		return nil
+	}
+
+	raw, err := json.Marshal(updated)
+	if err != nil {
+		return err
+	}
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[csvPropertiesAnnotation] = string(raw)
+	csv.SetAnnotations(annotations)
+	if err := cl.Update(ctx, csv); err != nil {
+		return fmt.Errorf("failed to update ClusterServiceVersion %s in SetMaxOpenShiftVersion: %w", name, err)
+	}
+	return nil
+}
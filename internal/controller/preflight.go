@@ -0,0 +1,85 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	kmmv1beta1 "github.com/rh-ecosystem-edge/kernel-module-management/api/v1beta1"
+	kmmv1beta2 "github.com/rh-ecosystem-edge/kernel-module-management/api/v1beta2"
+	"k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
+
+	fusionv1alpha1 "github.com/openshift-storage-scale/openshift-fusion-access-operator/api/v1alpha1"
+	"github.com/openshift-storage-scale/openshift-fusion-access-operator/internal/controller/kernelmodule"
+)
+
+// ConditionKernelModuleUpgradeReady reports whether the kernel module images for the kernel of the target
+// OpenShift release of spec.kernelModule.upgradePreflight are built. It is only set while an upgrade
+// preflight is requested and is not part of the phase
+const ConditionKernelModuleUpgradeReady = "KernelModuleUpgradeReady"
+
+// updateKernelModuleUpgradeReady runs the KMM preflight validation of the target OpenShift release, which
+// builds and pushes its kernel module images, and reports its result. The caller is responsible for updating the status
+func (r *FusionAccessReconciler) updateKernelModuleUpgradeReady(ctx context.Context, ns string, fusionaccess *fusionv1alpha1.FusionAccess) error {
+	var preflight *fusionv1alpha1.KernelModuleUpgradePreflight
+	if fusionaccess.Spec.KernelModule != nil {
+		preflight = fusionaccess.Spec.KernelModule.UpgradePreflight
+	}
+	if preflight == nil {
+		meta.RemoveStatusCondition(&fusionaccess.Status.Conditions, ConditionKernelModuleUpgradeReady)
+		return kernelmodule.DeleteKMMPreflight(ctx, r.Client)
+	}
+
+	kernel := preflight.KernelVersion
+	moduleName := kernelmodule.KMMModuleNameForKernel(kernel)
+	if moduleName == "" {
+		setComponentCondition(fusionaccess, ConditionKernelModuleUpgradeReady, ReasonFailed,
+			fmt.Sprintf("The architecture of kernel %s of the target OpenShift release is not supported", kernel))
+		return kernelmodule.DeleteKMMPreflight(ctx, r.Client)
+	}
+	module := &kmmv1beta1.Module{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: ns, Name: moduleName}, module); err != nil {
+		if !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to get module %s in updateKernelModuleUpgradeReady: %w", moduleName, err)
+		}
+		setComponentCondition(fusionaccess, ConditionKernelModuleUpgradeReady, ReasonNotRequired,
+			fmt.Sprintf("No kernel module is built for the architecture of kernel %s", kernel))
+		return kernelmodule.DeleteKMMPreflight(ctx, r.Client)
+	}
+
+	current, err := kernelmodule.CreateOrUpdateKMMPreflight(ctx, r.Client, kernelmodule.NewKMMPreflight(preflight, module.Generation))
+	if err != nil {
+		return err
+	}
+	status := kernelmodule.GetKMMPreflightModuleStatus(current, ns, moduleName)
+	switch {
+	case status == nil || status.VerificationStatus == kmmv1beta2.VerificationInProgress:
+		setComponentCondition(fusionaccess, ConditionKernelModuleUpgradeReady, ReasonInProgress,
+			fmt.Sprintf("Building the kernel module image for kernel %s of the target OpenShift release", kernel))
+	case status.VerificationStatus == kmmv1beta2.VerificationSuccess:
+		setComponentCondition(fusionaccess, ConditionKernelModuleUpgradeReady, ReasonSucceeded,
+			fmt.Sprintf("The kernel module image for kernel %s is ready, the cluster can be upgraded to the target OpenShift release", kernel))
+	default:
+		setComponentCondition(fusionaccess, ConditionKernelModuleUpgradeReady, ReasonFailed,
+			fmt.Sprintf("The kernel module image for kernel %s cannot be built, do not upgrade the cluster to the target OpenShift release: %s",
+				kernel, status.StatusReason))
+	}
+	return nil
+}
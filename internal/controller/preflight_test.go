@@ -0,0 +1,117 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	kmmv1beta1 "github.com/rh-ecosystem-edge/kernel-module-management/api/v1beta1"
+	kmmv1beta2 "github.com/rh-ecosystem-edge/kernel-module-management/api/v1beta2"
+	"k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	fusionv1alpha "github.com/openshift-storage-scale/openshift-fusion-access-operator/api/v1alpha1"
+	"github.com/openshift-storage-scale/openshift-fusion-access-operator/internal/controller/kernelmodule"
+)
+
+var _ = Describe("KernelModuleUpgradeReady condition", func() {
+	const (
+		ns     = "ibm-fusion-access"
+		kernel = "5.14.0-570.12.1.el9_6.x86_64"
+	)
+
+	var (
+		ctx       = context.Background()
+		preflight = &fusionv1alpha.KernelModuleUpgradePreflight{
+			KernelVersion: kernel,
+			DTKImage:      "quay.io/openshift-release-dev/ocp-v4.0-art-dev@sha256:def456",
+		}
+		module = &kmmv1beta1.Module{
+			ObjectMeta: metav1.ObjectMeta{Name: kernelmodule.KMMModuleName, Namespace: ns, Generation: 1},
+		}
+	)
+
+	newFusionAccess := func(preflight *fusionv1alpha.KernelModuleUpgradePreflight) *fusionv1alpha.FusionAccess {
+		return &fusionv1alpha.FusionAccess{
+			ObjectMeta: metav1.ObjectMeta{Name: "fusionaccess-object", Namespace: ns},
+			Spec: fusionv1alpha.FusionAccessSpec{
+				KernelModule: &fusionv1alpha.KernelModuleConfig{UpgradePreflight: preflight},
+			},
+		}
+	}
+
+	update := func(cl client.Client, fa *fusionv1alpha.FusionAccess) *metav1.Condition {
+		reconciler := &FusionAccessReconciler{Client: cl}
+		Expect(reconciler.updateKernelModuleUpgradeReady(ctx, ns, fa)).To(Succeed())
+		return meta.FindStatusCondition(fa.Status.Conditions, ConditionKernelModuleUpgradeReady)
+	}
+
+	withResult := func(status, reason string) *kmmv1beta2.PreflightValidationOCP {
+		pfvo := kernelmodule.NewKMMPreflight(preflight, module.Generation)
+		pfvo.Status.Modules = []kmmv1beta2.PreflightValidationModuleStatus{{
+			Name:         kernelmodule.KMMModuleName,
+			Namespace:    ns,
+			CRBaseStatus: kmmv1beta2.CRBaseStatus{VerificationStatus: status, StatusReason: reason},
+		}}
+		return pfvo
+	}
+
+	It("starts the preflight validation of the target release", func() {
+		cl := fake.NewClientBuilder().WithScheme(createFakeScheme()).WithObjects(module).Build()
+		condition := update(cl, newFusionAccess(preflight))
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		Expect(condition.Reason).To(Equal(ReasonInProgress))
+		Expect(condition.Message).To(ContainSubstring(kernel))
+
+		pfvo := &kmmv1beta2.PreflightValidationOCP{}
+		Expect(cl.Get(ctx, types.NamespacedName{Name: kernelmodule.KMMPreflightName}, pfvo)).To(Succeed())
+		Expect(pfvo.Spec.KernelVersion).To(Equal(kernel))
+		Expect(pfvo.Spec.PushBuiltImage).To(BeTrue())
+	})
+
+	DescribeTable("reports the result of the preflight validation",
+		func(pfvo *kmmv1beta2.PreflightValidationOCP, status metav1.ConditionStatus, reason, message string) {
+			cl := fake.NewClientBuilder().WithScheme(createFakeScheme()).WithObjects(module, pfvo).Build()
+			condition := update(cl, newFusionAccess(preflight))
+			Expect(condition.Status).To(Equal(status))
+			Expect(condition.Reason).To(Equal(reason))
+			Expect(condition.Message).To(ContainSubstring(message))
+		},
+		Entry("still building", withResult(kmmv1beta2.VerificationInProgress, ""), metav1.ConditionFalse, ReasonInProgress,
+			"Building the kernel module image"),
+		Entry("image pushed", withResult(kmmv1beta2.VerificationSuccess, ""), metav1.ConditionTrue, ReasonSucceeded,
+			"the cluster can be upgraded"),
+		Entry("build failed", withResult(kmmv1beta2.VerificationFailure, "Failed to verify build for module gpfs-module"),
+			metav1.ConditionFalse, ReasonFailed, "do not upgrade the cluster to the target OpenShift release: Failed to verify build"),
+	)
+
+	It("does not validate a kernel without a Module for its architecture", func() {
+		s390x := &fusionv1alpha.KernelModuleUpgradePreflight{KernelVersion: "5.14.0-570.12.1.el9_6.s390x", DTKImage: preflight.DTKImage}
+		cl := fake.NewClientBuilder().WithScheme(createFakeScheme()).WithObjects(module).Build()
+		condition := update(cl, newFusionAccess(s390x))
+		Expect(condition.Reason).To(Equal(ReasonNotRequired))
+		Expect(errors.IsNotFound(cl.Get(ctx, types.NamespacedName{Name: kernelmodule.KMMPreflightName},
+			&kmmv1beta2.PreflightValidationOCP{}))).To(BeTrue())
+
+		condition = update(cl, newFusionAccess(&fusionv1alpha.KernelModuleUpgradePreflight{
+			KernelVersion: "5.14.0-570.12.1.el9_6", DTKImage: preflight.DTKImage,
+		}))
+		Expect(condition.Reason).To(Equal(ReasonFailed))
+	})
+
+	It("removes the preflight validation and the condition once the upgrade preflight is unset", func() {
+		cl := fake.NewClientBuilder().WithScheme(createFakeScheme()).
+			WithObjects(module, withResult(kmmv1beta2.VerificationSuccess, "")).Build()
+		fa := newFusionAccess(preflight)
+		Expect(update(cl, fa)).ToNot(BeNil())
+
+		fa.Spec.KernelModule = nil
+		Expect(update(cl, fa)).To(BeNil())
+		Expect(errors.IsNotFound(cl.Get(ctx, types.NamespacedName{Name: kernelmodule.KMMPreflightName},
+			&kmmv1beta2.PreflightValidationOCP{}))).To(BeTrue())
+	})
+})
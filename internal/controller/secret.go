@@ -11,6 +11,7 @@ import (
 	"github.com/openshift-storage-scale/openshift-fusion-access-operator/internal/kubeutils"
 	"github.com/openshift-storage-scale/openshift-fusion-access-operator/internal/utils"
 	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/log"
@@ -130,3 +131,14 @@ func updateEntitlementPullSecrets(secret []byte, ctx context.Context, cl client.
 	}
 	return nil
 }
+
+// deleteEntitlementPullSecrets removes the entitlement secrets from all the namespaces we created them in
+func deleteEntitlementPullSecrets(ctx context.Context, cl client.Client, ns string) error {
+	for _, destNamespace := range IbmEntitlementSecrets(ns) {
+		secret := newSecret(IBMENTITLEMENTNAME, destNamespace, nil, corev1.SecretTypeDockerConfigJson, nil)
+		if err := cl.Delete(ctx, secret); err != nil && !kerrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete secret in %s in deleteEntitlementPullSecrets: %w", destNamespace, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	meta "k8s.io/apimachinery/pkg/api/meta"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	fusionv1alpha1 "github.com/openshift-storage-scale/openshift-fusion-access-operator/api/v1alpha1"
+	"github.com/openshift-storage-scale/openshift-fusion-access-operator/internal/controller/kernelmodule"
+)
+
+const (
+	// ConditionSecureBootReady reports whether the kernel modules are signed when Secure Boot is enabled on
+	// storage nodes, as reported by their NodeFacts. It is not part of the phase
+	ConditionSecureBootReady = "SecureBootReady"
+
+	ReasonModulesSigned        = "ModulesSigned"
+	ReasonNoSecureBootNodes    = "NoSecureBootNodes"
+	ReasonSigningNotConfigured = "SigningNotConfigured"
+)
+
+// getSecureBootNodes returns the sorted names of the nodes whose NodeFacts report Secure Boot as enabled
+func getSecureBootNodes(ctx context.Context, cl client.Client, ns string) ([]string, error) {
+	nodeFacts := &fusionv1alpha1.NodeFactsList{}
+	if err := cl.List(ctx, nodeFacts, client.InNamespace(ns)); err != nil {
+		return nil, fmt.Errorf("failed to list NodeFacts in getSecureBootNodes: %w", err)
+	}
+	nodes := []string{}
+	for _, facts := range nodeFacts.Items {
+		if facts.Status.SecureBoot == fusionv1alpha1.SecureBootEnabled {
+			nodes = append(nodes, facts.Spec.NodeName)
+		}
+	}
+	slices.Sort(nodes)
+	return nodes, nil
+}
+
+// updateSecureBootCondition warns when Secure Boot is enabled on storage nodes but the kernel modules are
+// not signed, since they cannot be loaded there. The caller is responsible for updating the status
+func (r *FusionAccessReconciler) updateSecureBootCondition(ctx context.Context, ns string, fusionaccess *fusionv1alpha1.FusionAccess) error {
+	kmmImageConfig, err := kernelmodule.GetKMMImageConfig(ctx, r.Client, ns)
+	if err != nil {
+		return fmt.Errorf("failed to get the kernel module configuration in updateSecureBootCondition: %w", err)
+	}
+	nodes, err := getSecureBootNodes(ctx, r.Client, ns)
+	if err != nil {
+		return err
+	}
+
+	condition := v1.Condition{Type: ConditionSecureBootReady, Status: v1.ConditionTrue}
+	switch {
+	case kernelmodule.SigningSecretsExist(ctx, r.Client, ns, &kmmImageConfig):
+		condition.Reason = ReasonModulesSigned
+		condition.Message = fmt.Sprintf("The kernel modules are signed with the key of secret %s. The certificate in the %q entry "+
+			"of secret %s must be enrolled on the Secure Boot nodes with mokutil --import",
+			kmmImageConfig.SigningKeySecret, kernelmodule.SigningCertEntry, kmmImageConfig.SigningCertSecret)
+	case len(nodes) == 0:
+		condition.Reason = ReasonNoSecureBootNodes
+		condition.Message = "Secure Boot is not enabled on any storage node, the kernel modules do not need to be signed"
+	default:
+		condition.Status = v1.ConditionFalse
+		condition.Reason = ReasonSigningNotConfigured
+		condition.Message = fmt.Sprintf("Secure Boot is enabled on storage nodes %s but the kernel modules are not signed and "+
+			"cannot be loaded there. Set spec.kernelModule.signing.generate to generate a signing key, or create the secrets %s and %s",
+			strings.Join(nodes, ", "), kmmImageConfig.SigningKeySecret, kmmImageConfig.SigningCertSecret)
+		if !meta.IsStatusConditionFalse(fusionaccess.Status.Conditions, ConditionSecureBootReady) {
+			log.Log.Info("Kernel modules are not signed for the Secure Boot nodes", "nodes", nodes)
+		}
+	}
+	meta.SetStatusCondition(&fusionaccess.Status.Conditions, condition)
+	return nil
+}
@@ -0,0 +1,60 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	fusionv1alpha "github.com/openshift-storage-scale/openshift-fusion-access-operator/api/v1alpha1"
+	"github.com/openshift-storage-scale/openshift-fusion-access-operator/internal/controller/kernelmodule"
+)
+
+var _ = Describe("SecureBootReady condition", func() {
+	const ns = "ibm-fusion-access"
+
+	newNodeFacts := func(node string, secureBoot fusionv1alpha.SecureBootState) *fusionv1alpha.NodeFacts {
+		return &fusionv1alpha.NodeFacts{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-facts-" + node, Namespace: ns},
+			Spec:       fusionv1alpha.NodeFactsSpec{NodeName: node},
+			Status:     fusionv1alpha.NodeFactsStatus{SecureBoot: secureBoot},
+		}
+	}
+	newSecret := func(name string) *corev1.Secret {
+		return &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: ns}}
+	}
+
+	DescribeTable("checks the signing of the kernel modules against the Secure Boot nodes",
+		func(objects []client.Object, status metav1.ConditionStatus, reason, message string) {
+			fa := &fusionv1alpha.FusionAccess{ObjectMeta: metav1.ObjectMeta{Name: "fusionaccess-object", Namespace: ns}}
+			cl := fake.NewClientBuilder().WithScheme(createFakeScheme()).WithObjects(append(objects, fa)...).Build()
+			reconciler := &FusionAccessReconciler{Client: cl}
+			Expect(reconciler.updateSecureBootCondition(context.Background(), ns, fa)).To(Succeed())
+
+			condition := meta.FindStatusCondition(fa.Status.Conditions, ConditionSecureBootReady)
+			Expect(condition).ToNot(BeNil())
+			Expect(condition.Status).To(Equal(status))
+			Expect(condition.Reason).To(Equal(reason))
+			Expect(condition.Message).To(ContainSubstring(message))
+		},
+		Entry("no Secure Boot node", []client.Object{
+			newNodeFacts("worker-0", fusionv1alpha.SecureBootDisabled),
+			newNodeFacts("worker-1", fusionv1alpha.SecureBootUnsupported),
+		}, metav1.ConditionTrue, ReasonNoSecureBootNodes, "Secure Boot is not enabled on any storage node"),
+		Entry("Secure Boot nodes without signing", []client.Object{
+			newNodeFacts("worker-1", fusionv1alpha.SecureBootEnabled),
+			newNodeFacts("worker-0", fusionv1alpha.SecureBootEnabled),
+			newNodeFacts("worker-2", fusionv1alpha.SecureBootDisabled),
+		}, metav1.ConditionFalse, ReasonSigningNotConfigured, "Secure Boot is enabled on storage nodes worker-0, worker-1 but"),
+		Entry("Secure Boot nodes with signing", []client.Object{
+			newNodeFacts("worker-0", fusionv1alpha.SecureBootEnabled),
+			newSecret(kernelmodule.SecureBootKey),
+			newSecret(kernelmodule.SecureBootKeyPub),
+		}, metav1.ConditionTrue, ReasonModulesSigned, "must be enrolled on the Secure Boot nodes with mokutil --import"),
+	)
+})
@@ -0,0 +1,383 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sharedluninventory
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+
+	fusionv1alpha1 "github.com/openshift-storage-scale/openshift-fusion-access-operator/api/v1alpha1"
+	"github.com/openshift-storage-scale/openshift-fusion-access-operator/internal/common"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// SharedLUNInventoryReconciler aggregates the LocalVolumeDiscoveryResults of all the nodes into the
+// cluster scoped SharedLUNInventory
+type SharedLUNInventoryReconciler struct {
+	Client client.Client
+	Scheme *runtime.Scheme
+
+	controller            controller.Controller
+	cache                 cache.Cache
+	localDiskWatchLock    sync.Mutex
+	localDiskWatchStarted bool
+}
+
+//+kubebuilder:rbac:groups=fusion.storage.openshift.io,resources=sharedluninventories,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=fusion.storage.openshift.io,resources=sharedluninventories/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=fusion.storage.openshift.io,resources=localvolumediscoveryresults,verbs=get;list;watch
+//+kubebuilder:rbac:groups=scale.spectrum.ibm.com,resources=localdisks,verbs=get;list;watch
+
+// Reconcile rebuilds the SharedLUNInventory from the current LocalVolumeDiscoveryResults and LocalDisks. The
+// results are only written by the device finder, the usage of the devices is only kept in the inventory
+func (r *SharedLUNInventoryReconciler) Reconcile(ctx context.Context, request ctrl.Request) (ctrl.Result, error) {
+	klog.InfoS("Reconciling SharedLUNInventory", "name", request.Name)
+
+	results := &fusionv1alpha1.LocalVolumeDiscoveryResultList{}
+	if err := r.Client.List(ctx, results); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to list LocalVolumeDiscoveryResults: %w", err)
+	}
+	localDisks, err := r.listLocalDisks(ctx)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	desired := BuildInventory(results.Items, localDisks)
+
+	inventory := &fusionv1alpha1.SharedLUNInventory{}
+	err = r.Client.Get(ctx, types.NamespacedName{Name: fusionv1alpha1.SharedLUNInventoryName}, inventory)
+	if errors.IsNotFound(err) && len(results.Items) == 0 {
+		// Nothing was discovered, or device discovery was removed together with the FusionAccess
+		return ctrl.Result{}, nil
+	}
+	if errors.IsNotFound(err) {
+		inventory = &fusionv1alpha1.SharedLUNInventory{
+			ObjectMeta: metav1.ObjectMeta{Name: fusionv1alpha1.SharedLUNInventoryName},
+		}
+		if err := r.Client.Create(ctx, inventory); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to create SharedLUNInventory: %w", err)
+		}
+	} else if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to get SharedLUNInventory: %w", err)
+	}
+
+	// Only update when the content changes, LastUpdated would otherwise trigger endless updates
+	desired.LastUpdated = inventory.Status.LastUpdated
+	if inventory.Status.LastUpdated != nil && reflect.DeepEqual(inventory.Status, desired) {
+		return ctrl.Result{}, nil
+	}
+	now := metav1.Now()
+	desired.LastUpdated = &now
+	inventory.Status = desired
+	if err := r.Client.Status().Update(ctx, inventory); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to update SharedLUNInventory status: %w", err)
+	}
+	if desired.MismatchedLUNCount > 0 {
+		klog.InfoS("Some LUNs are reported differently by the storage nodes", "count", desired.MismatchedLUNCount)
+	}
+	return ctrl.Result{}, nil
+}
+
+// DeleteSharedLUNInventory deletes the SharedLUNInventory. It is cluster scoped, so it cannot be owned by
+// the FusionAccess and garbage collected with it
+func DeleteSharedLUNInventory(ctx context.Context, cl client.Client) error {
+	inventory := &fusionv1alpha1.SharedLUNInventory{
+		ObjectMeta: metav1.ObjectMeta{Name: fusionv1alpha1.SharedLUNInventoryName},
+	}
+	if err := cl.Delete(ctx, inventory); err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete SharedLUNInventory: %w", err)
+	}
+	return nil
+}
+
+// listLocalDisks lists the Storage Scale LocalDisks, and starts watching them. Until the Storage Scale
+// manifest installed their CRD there are none
+func (r *SharedLUNInventoryReconciler) listLocalDisks(ctx context.Context) ([]unstructured.Unstructured, error) {
+	localDisks := &unstructured.UnstructuredList{}
+	localDisks.SetGroupVersionKind(common.LocalDiskGVK.GroupVersion().WithKind(common.LocalDiskGVK.Kind + "List"))
+	err := r.Client.List(ctx, localDisks, client.InNamespace(common.StorageScaleNamespace))
+	if meta.IsNoMatchError(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list LocalDisks: %w", err)
+	}
+	if err := r.watchLocalDisks(); err != nil {
+		return nil, err
+	}
+	return localDisks.Items, nil
+}
+
+// watchLocalDisks starts watching the LocalDisks once their CRD exists. This cannot be done in
+// SetupWithManager since the CRD does not exist when the operator is first installed
+func (r *SharedLUNInventoryReconciler) watchLocalDisks() error {
+	r.localDiskWatchLock.Lock()
+	defer r.localDiskWatchLock.Unlock()
+	if r.localDiskWatchStarted || r.controller == nil {
+		return nil
+	}
+	localDisk := &unstructured.Unstructured{}
+	localDisk.SetGroupVersionKind(common.LocalDiskGVK)
+	if err := r.controller.Watch(source.Kind(r.cache, client.Object(localDisk), toInventory)); err != nil {
+		return fmt.Errorf("failed to watch LocalDisks: %w", err)
+	}
+	r.localDiskWatchStarted = true
+	klog.Info("Watching LocalDisks")
+	return nil
+}
+
+// BuildInventory groups the discovered devices of all the nodes by WWN. When a node reports the same WWN
+// more than once, e.g. a multipath device and one of its paths, the multipath device is kept. The LUNs are
+// marked with the LocalDisk using them. Devices without a WWN cannot be shared, they are listed per node
+// with the LocalDisk of their node whose device is one of their names
+func BuildInventory(
+	results []fusionv1alpha1.LocalVolumeDiscoveryResult,
+	localDisks []unstructured.Unstructured,
+) fusionv1alpha1.SharedLUNInventoryStatus {
+	storageNodes, devicesByWWN := groupDevicesByWWN(results)
+	usages := localDiskUsages(localDisks, devicesByWWN)
+	status := fusionv1alpha1.SharedLUNInventoryStatus{StorageNodes: storageNodes}
+	for wwn, devices := range devicesByWWN {
+		lun := newSharedLUN(wwn, devices, len(storageNodes))
+		if lun.SizeMismatch || lun.VendorMismatch {
+			status.MismatchedLUNCount++
+		}
+		usage := usages[wwn]
+		lun.Usage = usage.usage()
+		if lun.Usage == fusionv1alpha1.LUNAvailable {
+			status.AvailableLUNCount++
+		}
+		lun.LocalDisk = usage.localDisk
+		lun.Filesystem = usage.filesystem
+		status.LUNs = append(status.LUNs, lun)
+	}
+	slices.SortFunc(status.LUNs, func(a, b fusionv1alpha1.SharedLUN) int {
+		return strings.Compare(a.WWN, b.WWN)
+	})
+	status.NodeLocalDevices = nodeLocalDevices(results, localDisks)
+	return status
+}
+
+// nodeLocalDevices returns the devices without a WWN of every node, marked with their usage
+func nodeLocalDevices(
+	results []fusionv1alpha1.LocalVolumeDiscoveryResult,
+	localDisks []unstructured.Unstructured,
+) []fusionv1alpha1.NodeLocalDevice {
+	var devices []fusionv1alpha1.NodeLocalDevice
+	for _, result := range results {
+		node := result.Spec.NodeName
+		if node == "" {
+			continue
+		}
+		for idx := range result.Status.DiscoveredDevices {
+			device := &result.Status.DiscoveredDevices[idx]
+			if device.WWN != "" {
+				continue
+			}
+			usage := nodeDeviceUsage(localDisks, node, device)
+			devices = append(devices, fusionv1alpha1.NodeLocalDevice{
+				NodeName:   node,
+				DeviceID:   device.DeviceID,
+				Path:       device.Path,
+				Size:       device.Size,
+				Usage:      usage.usage(),
+				LocalDisk:  usage.localDisk,
+				Filesystem: usage.filesystem,
+			})
+		}
+	}
+	slices.SortFunc(devices, func(a, b fusionv1alpha1.NodeLocalDevice) int {
+		if c := strings.Compare(a.NodeName, b.NodeName); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Path, b.Path)
+	})
+	return devices
+}
+
+// groupDevicesByWWN returns the sorted storage nodes and the device of every node for each WWN
+func groupDevicesByWWN(
+	results []fusionv1alpha1.LocalVolumeDiscoveryResult,
+) ([]string, map[string]map[string]fusionv1alpha1.DiscoveredDevice) {
+	storageNodes := []string{}
+	devicesByWWN := map[string]map[string]fusionv1alpha1.DiscoveredDevice{}
+	for _, result := range results {
+		node := result.Spec.NodeName
+		if node == "" {
+			continue
+		}
+		if !slices.Contains(storageNodes, node) {
+			storageNodes = append(storageNodes, node)
+		}
+		for _, device := range result.Status.DiscoveredDevices {
+			if device.WWN == "" {
+				continue
+			}
+			if devicesByWWN[device.WWN] == nil {
+				devicesByWWN[device.WWN] = map[string]fusionv1alpha1.DiscoveredDevice{}
+			}
+			existing, found := devicesByWWN[device.WWN][node]
+			if !found || (existing.Type != fusionv1alpha1.MultiPathType && device.Type == fusionv1alpha1.MultiPathType) {
+				devicesByWWN[device.WWN][node] = device
+			}
+		}
+	}
+	slices.Sort(storageNodes)
+	return storageNodes, devicesByWWN
+}
+
+// localDiskUsage is the LocalDisk using a LUN
+type localDiskUsage struct {
+	localDisk  string
+	filesystem string
+}
+
+// usage classifies the LUN from the LocalDisk using it, if any
+func (u localDiskUsage) usage() fusionv1alpha1.LUNUsage {
+	switch {
+	case u.localDisk == "":
+		return fusionv1alpha1.LUNAvailable
+	case u.filesystem != "":
+		return fusionv1alpha1.LUNInFilesystem
+	default:
+		return fusionv1alpha1.LUNClaimedByLocalDisk
+	}
+}
+
+// nodeDeviceUsage returns the LocalDisk of the node whose device is one of the names of the device. When
+// several match, the first one by name is reported like for the LUNs
+func nodeDeviceUsage(
+	localDisks []unstructured.Unstructured,
+	node string,
+	device *fusionv1alpha1.DiscoveredDevice,
+) localDiskUsage {
+	return firstLocalDiskUsage(localDisks, "", map[string][]string{node: device.Names()})
+}
+
+// localDiskUsages maps the WWNs to the LocalDisk using them, see common.LocalDiskUsesLUN
+func localDiskUsages(
+	localDisks []unstructured.Unstructured,
+	devicesByWWN map[string]map[string]fusionv1alpha1.DiscoveredDevice,
+) map[string]localDiskUsage {
+	usages := map[string]localDiskUsage{}
+	for wwn, devices := range devicesByWWN {
+		namesByNode := map[string][]string{}
+		for node, device := range devices {
+			namesByNode[node] = device.Names()
+		}
+		if usage := firstLocalDiskUsage(localDisks, wwn, namesByNode); usage.localDisk != "" {
+			usages[wwn] = usage
+		}
+	}
+	return usages
+}
+
+// firstLocalDiskUsage returns the LocalDisk using the LUN. When several use it, the first one by name
+// is reported so that the inventory does not change between reconciles
+func firstLocalDiskUsage(localDisks []unstructured.Unstructured, wwn string, namesByNode map[string][]string) localDiskUsage {
+	usage := localDiskUsage{}
+	for idx := range localDisks {
+		localDisk := &localDisks[idx]
+		if !common.LocalDiskUsesLUN(localDisk, wwn, namesByNode) {
+			continue
+		}
+		if usage.localDisk != "" && usage.localDisk < localDisk.GetName() {
+			continue
+		}
+		filesystem, _, _ := unstructured.NestedString(localDisk.Object, "status", "filesystem")
+		usage = localDiskUsage{localDisk: localDisk.GetName(), filesystem: filesystem}
+	}
+	return usage
+}
+
+func newSharedLUN(wwn string, devices map[string]fusionv1alpha1.DiscoveredDevice, storageNodeCount int) fusionv1alpha1.SharedLUN {
+	nodes := make([]fusionv1alpha1.LUNNodeDevice, 0, len(devices))
+	for node, device := range devices {
+		nodes = append(nodes, fusionv1alpha1.LUNNodeDevice{
+			NodeName: node,
+			DeviceID: device.DeviceID,
+			Path:     device.Path,
+			Size:     device.Size,
+			Vendor:   device.Vendor,
+			Model:    device.Model,
+		})
+	}
+	slices.SortFunc(nodes, func(a, b fusionv1alpha1.LUNNodeDevice) int {
+		return strings.Compare(a.NodeName, b.NodeName)
+	})
+
+	lun := fusionv1alpha1.SharedLUN{
+		WWN:    wwn,
+		Size:   nodes[0].Size,
+		Vendor: nodes[0].Vendor,
+		Model:  nodes[0].Model,
+		Nodes:  nodes,
+	}
+	for _, node := range nodes[1:] {
+		if node.Size != lun.Size {
+			lun.SizeMismatch = true
+		}
+		if !strings.EqualFold(strings.TrimSpace(node.Vendor), strings.TrimSpace(lun.Vendor)) {
+			lun.VendorMismatch = true
+		}
+	}
+
+	switch {
+	case len(nodes) == storageNodeCount:
+		lun.Sharing = fusionv1alpha1.LUNSharedByAll
+	case len(nodes) == 1:
+		lun.Sharing = fusionv1alpha1.LUNNodeLocal
+	default:
+		lun.Sharing = fusionv1alpha1.LUNPartiallyShared
+	}
+	return lun
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *SharedLUNInventoryReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	c, err := ctrl.NewControllerManagedBy(mgr).
+		For(&fusionv1alpha1.SharedLUNInventory{}).
+		Watches(&fusionv1alpha1.LocalVolumeDiscoveryResult{}, toInventory).
+		Build(r)
+	if err != nil {
+		return err
+	}
+	r.controller = c
+	r.cache = mgr.GetCache()
+	return nil
+}
+
+// toInventory enqueues the inventory, every discovery result and LocalDisk feeds the same one
+var toInventory = handler.EnqueueRequestsFromMapFunc(func(_ context.Context, _ client.Object) []reconcile.Request {
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: fusionv1alpha1.SharedLUNInventoryName}}}
+})
@@ -0,0 +1,235 @@
+package sharedluninventory
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	fusionv1alpha1 "github.com/openshift-storage-scale/openshift-fusion-access-operator/api/v1alpha1"
+	"github.com/openshift-storage-scale/openshift-fusion-access-operator/internal/common"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const namespace = "ibm-fusion-access"
+
+func newResult(node string, devices ...fusionv1alpha1.DiscoveredDevice) fusionv1alpha1.LocalVolumeDiscoveryResult {
+	return fusionv1alpha1.LocalVolumeDiscoveryResult{
+		ObjectMeta: metav1.ObjectMeta{Name: "discovery-result-" + node, Namespace: namespace},
+		Spec:       fusionv1alpha1.LocalVolumeDiscoveryResultSpec{NodeName: node},
+		Status:     fusionv1alpha1.LocalVolumeDiscoveryResultStatus{DiscoveredDevices: devices},
+	}
+}
+
+func newLocalDisk(name, node, device, filesystem string) unstructured.Unstructured {
+	localDisk := unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{"node": node, "device": device},
+	}}
+	localDisk.SetGroupVersionKind(common.LocalDiskGVK)
+	localDisk.SetName(name)
+	localDisk.SetNamespace(common.StorageScaleNamespace)
+	if filesystem != "" {
+		Expect(unstructured.SetNestedField(localDisk.Object, filesystem, "status", "filesystem")).To(Succeed())
+	}
+	return localDisk
+}
+
+func newDevice(wwn, path string, size int64, vendor string) fusionv1alpha1.DiscoveredDevice {
+	return fusionv1alpha1.DiscoveredDevice{
+		DeviceID: "/dev/disk/by-id/wwn-" + wwn,
+		Path:     path,
+		Type:     fusionv1alpha1.DiskType,
+		Size:     size,
+		Vendor:   vendor,
+		Model:    "FlashSystem",
+		WWN:      wwn,
+	}
+}
+
+var _ = Describe("BuildInventory", func() {
+	It("groups the devices by WWN and classifies the sharing", func() {
+		status := BuildInventory([]fusionv1alpha1.LocalVolumeDiscoveryResult{
+			newResult("worker-0",
+				newDevice("0x600a", "/dev/sdb", 100, "IBM"),
+				newDevice("0x600b", "/dev/sdc", 200, "IBM"),
+				newDevice("0x600c", "/dev/sdd", 300, "IBM"),
+			),
+			newResult("worker-1",
+				newDevice("0x600a", "/dev/sdc", 100, "IBM"),
+				newDevice("0x600b", "/dev/sdb", 200, "IBM"),
+			),
+			newResult("worker-2",
+				newDevice("0x600a", "/dev/sdb", 100, "IBM"),
+			),
+		}, nil)
+
+		Expect(status.StorageNodes).To(Equal([]string{"worker-0", "worker-1", "worker-2"}))
+		Expect(status.LUNs).To(HaveLen(3))
+		Expect(status.LUNs[0].WWN).To(Equal("0x600a"))
+		Expect(status.LUNs[0].Sharing).To(Equal(fusionv1alpha1.LUNSharedByAll))
+		Expect(status.LUNs[0].Nodes[1].Path).To(Equal("/dev/sdc"))
+		Expect(status.LUNs[1].Sharing).To(Equal(fusionv1alpha1.LUNPartiallyShared))
+		Expect(status.LUNs[2].Sharing).To(Equal(fusionv1alpha1.LUNNodeLocal))
+		Expect(status.MismatchedLUNCount).To(BeZero())
+		Expect(status.AvailableLUNCount).To(Equal(int32(3)))
+		Expect(status.LUNs[0].Usage).To(Equal(fusionv1alpha1.LUNAvailable))
+	})
+
+	It("marks the LUNs used by LocalDisks", func() {
+		mpath := newDevice("0x600c", "/dev/dm-0", 300, "IBM")
+		mpath.MultipathMembers = []fusionv1alpha1.MultipathMember{{Name: "sdd", Path: "/dev/sdd"}, {Name: "sde", Path: "/dev/sde"}}
+		status := BuildInventory([]fusionv1alpha1.LocalVolumeDiscoveryResult{
+			newResult("worker-0",
+				newDevice("0x600a", "/dev/sdb", 100, "IBM"),
+				newDevice("0x600b", "/dev/sdc", 200, "IBM"),
+				mpath,
+				newDevice("uuid.0x600d", "/dev/sdf", 400, "IBM"),
+			),
+			newResult("worker-1", newDevice("0x600b", "/dev/sdb", 200, "IBM")),
+		}, []unstructured.Unstructured{
+			// The device of worker-1, not the one of worker-0
+			newLocalDisk("nsd1", "worker-1", "/dev/sdb", "fs1"),
+			newLocalDisk("nsd2", "worker-0", "/dev/sde", ""),
+			// Not discovered on its node, matched by name
+			newLocalDisk("sdf-0x600d", "worker-2", "/dev/sdf", ""),
+			newLocalDisk("unknown", "worker-0", "/dev/sdz", "fs1"),
+		})
+
+		Expect(status.LUNs).To(HaveLen(4))
+		Expect(status.AvailableLUNCount).To(Equal(int32(1)))
+		Expect(status.LUNs[0].Usage).To(Equal(fusionv1alpha1.LUNAvailable))
+		Expect(status.LUNs[0].LocalDisk).To(BeEmpty())
+		Expect(status.LUNs[1].Usage).To(Equal(fusionv1alpha1.LUNInFilesystem))
+		Expect(status.LUNs[1].LocalDisk).To(Equal("nsd1"))
+		Expect(status.LUNs[1].Filesystem).To(Equal("fs1"))
+		Expect(status.LUNs[2].Usage).To(Equal(fusionv1alpha1.LUNClaimedByLocalDisk))
+		Expect(status.LUNs[2].LocalDisk).To(Equal("nsd2"))
+		Expect(status.LUNs[3].Usage).To(Equal(fusionv1alpha1.LUNClaimedByLocalDisk))
+		Expect(status.LUNs[3].LocalDisk).To(Equal("sdf-0x600d"))
+	})
+
+	It("flags size and vendor mismatches", func() {
+		status := BuildInventory([]fusionv1alpha1.LocalVolumeDiscoveryResult{
+			newResult("worker-0", newDevice("0x600a", "/dev/sdb", 100, "IBM"), newDevice("0x600b", "/dev/sdc", 200, "IBM ")),
+			newResult("worker-1", newDevice("0x600a", "/dev/sdb", 150, "IBM"), newDevice("0x600b", "/dev/sdc", 200, "NETAPP")),
+		}, nil)
+
+		Expect(status.MismatchedLUNCount).To(Equal(int32(2)))
+		Expect(status.LUNs[0].SizeMismatch).To(BeTrue())
+		Expect(status.LUNs[0].VendorMismatch).To(BeFalse())
+		Expect(status.LUNs[1].SizeMismatch).To(BeFalse())
+		Expect(status.LUNs[1].VendorMismatch).To(BeTrue())
+	})
+
+	It("prefers the multipath device and skips devices without WWN", func() {
+		mpath := newDevice("0x600a", "/dev/dm-0", 100, "IBM")
+		mpath.Type = fusionv1alpha1.MultiPathType
+		status := BuildInventory([]fusionv1alpha1.LocalVolumeDiscoveryResult{
+			newResult("worker-0", newDevice("0x600a", "/dev/sdb", 100, "IBM"), mpath, newDevice("", "/dev/sdz", 100, "IBM")),
+		}, nil)
+
+		Expect(status.LUNs).To(HaveLen(1))
+		Expect(status.LUNs[0].Nodes).To(HaveLen(1))
+		Expect(status.LUNs[0].Nodes[0].Path).To(Equal("/dev/dm-0"))
+		Expect(status.LUNs[0].Sharing).To(Equal(fusionv1alpha1.LUNSharedByAll))
+	})
+
+	It("lists the devices without WWN with the LocalDisk of their node", func() {
+		local := fusionv1alpha1.DiscoveredDevice{
+			DeviceID: "/dev/disk/by-id/scsi-0QEMU_QEMU_HARDDISK_drive-scsi1", Path: "/dev/sdd", Type: fusionv1alpha1.DiskType, Size: 100,
+		}
+		status := BuildInventory([]fusionv1alpha1.LocalVolumeDiscoveryResult{
+			newResult("worker-1", newDevice("0x600a", "/dev/sdc", 100, "IBM"), local),
+			newResult("worker-0", newDevice("0x600a", "/dev/sdb", 100, "IBM"), local),
+		}, []unstructured.Unstructured{
+			newLocalDisk("nsd1", "worker-0", "/dev/sdb", "fs1"),
+			newLocalDisk("nsd2", "worker-1", "/dev/disk/by-id/scsi-0QEMU_QEMU_HARDDISK_drive-scsi1", ""),
+		})
+
+		Expect(status.LUNs).To(HaveLen(1))
+		Expect(status.LUNs[0].LocalDisk).To(Equal("nsd1"))
+		Expect(status.NodeLocalDevices).To(Equal([]fusionv1alpha1.NodeLocalDevice{
+			{NodeName: "worker-0", DeviceID: local.DeviceID, Path: "/dev/sdd", Size: 100, Usage: fusionv1alpha1.LUNAvailable},
+			{NodeName: "worker-1", DeviceID: local.DeviceID, Path: "/dev/sdd", Size: 100, Usage: fusionv1alpha1.LUNClaimedByLocalDisk,
+				LocalDisk: "nsd2"},
+		}))
+	})
+})
+
+var _ = Describe("SharedLUNInventoryReconciler", func() {
+	It("creates the inventory and only updates it when the content changes", func() {
+		scheme := runtime.NewScheme()
+		Expect(fusionv1alpha1.AddToScheme(scheme)).To(Succeed())
+		result := newResult("worker-0", newDevice("0x600a", "/dev/sdb", 100, "IBM"))
+		cl := fake.NewClientBuilder().
+			WithScheme(scheme).
+			WithObjects(&result).
+			WithStatusSubresource(&fusionv1alpha1.SharedLUNInventory{}, &fusionv1alpha1.LocalVolumeDiscoveryResult{}).
+			Build()
+		reconciler := &SharedLUNInventoryReconciler{Client: cl, Scheme: scheme}
+		request := reconcile.Request{NamespacedName: types.NamespacedName{Name: fusionv1alpha1.SharedLUNInventoryName}}
+
+		_, err := reconciler.Reconcile(context.Background(), request)
+		Expect(err).ToNot(HaveOccurred())
+		inventory := &fusionv1alpha1.SharedLUNInventory{}
+		Expect(cl.Get(context.Background(), request.NamespacedName, inventory)).To(Succeed())
+		Expect(inventory.Status.LUNs).To(HaveLen(1))
+		Expect(inventory.Status.LastUpdated).ToNot(BeNil())
+		resourceVersion := inventory.ResourceVersion
+
+		_, err = reconciler.Reconcile(context.Background(), request)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cl.Get(context.Background(), request.NamespacedName, inventory)).To(Succeed())
+		Expect(inventory.ResourceVersion).To(Equal(resourceVersion))
+	})
+
+	It("does not create the inventory without discovery results", func() {
+		scheme := runtime.NewScheme()
+		Expect(fusionv1alpha1.AddToScheme(scheme)).To(Succeed())
+		cl := fake.NewClientBuilder().WithScheme(scheme).Build()
+		reconciler := &SharedLUNInventoryReconciler{Client: cl, Scheme: scheme}
+		request := reconcile.Request{NamespacedName: types.NamespacedName{Name: fusionv1alpha1.SharedLUNInventoryName}}
+
+		_, err := reconciler.Reconcile(context.Background(), request)
+		Expect(err).ToNot(HaveOccurred())
+		err = cl.Get(context.Background(), request.NamespacedName, &fusionv1alpha1.SharedLUNInventory{})
+		Expect(errors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("reports the LocalDisk using a LUN", func() {
+		scheme := runtime.NewScheme()
+		Expect(fusionv1alpha1.AddToScheme(scheme)).To(Succeed())
+		scheme.AddKnownTypeWithName(common.LocalDiskGVK, &unstructured.Unstructured{})
+		scheme.AddKnownTypeWithName(common.LocalDiskGVK.GroupVersion().WithKind("LocalDiskList"), &unstructured.UnstructuredList{})
+		result := newResult("worker-0", newDevice("0x600a", "/dev/sdb", 100, "IBM"))
+		localDisk := newLocalDisk("nsd1", "worker-0", "/dev/sdb", "fs1")
+		cl := fake.NewClientBuilder().
+			WithScheme(scheme).
+			WithObjects(&result, &localDisk).
+			WithStatusSubresource(&fusionv1alpha1.SharedLUNInventory{}, &fusionv1alpha1.LocalVolumeDiscoveryResult{}).
+			Build()
+		reconciler := &SharedLUNInventoryReconciler{Client: cl, Scheme: scheme}
+		request := reconcile.Request{NamespacedName: types.NamespacedName{Name: fusionv1alpha1.SharedLUNInventoryName}}
+		Expect(cl.Get(context.Background(), client.ObjectKeyFromObject(&result), &result)).To(Succeed())
+		resultVersion := result.ResourceVersion
+
+		_, err := reconciler.Reconcile(context.Background(), request)
+		Expect(err).ToNot(HaveOccurred())
+		inventory := &fusionv1alpha1.SharedLUNInventory{}
+		Expect(cl.Get(context.Background(), request.NamespacedName, inventory)).To(Succeed())
+		Expect(inventory.Status.LUNs[0].Usage).To(Equal(fusionv1alpha1.LUNInFilesystem))
+		Expect(inventory.Status.LUNs[0].LocalDisk).To(Equal("nsd1"))
+
+		// The results are left to the device finder
+		unchanged := &fusionv1alpha1.LocalVolumeDiscoveryResult{}
+		Expect(cl.Get(context.Background(), client.ObjectKeyFromObject(&result), unchanged)).To(Succeed())
+		Expect(unchanged.ResourceVersion).To(Equal(resultVersion))
+	})
+})
@@ -0,0 +1,14 @@
+package sharedluninventory
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestSharedLUNInventory(t *testing.T) {
+	RegisterFailHandler(Fail)
+
+	RunSpecs(t, "SharedLUNInventory Suite")
+}
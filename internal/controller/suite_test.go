@@ -27,7 +27,9 @@ import (
 	configv1 "github.com/openshift/api/config/v1"
 	consolev1 "github.com/openshift/api/console/v1"
 	operatorv1 "github.com/openshift/api/operator/v1"
+	operatorsv2 "github.com/operator-framework/api/pkg/operators/v2"
 	kmmv1beta1 "github.com/rh-ecosystem-edge/kernel-module-management/api/v1beta1"
+	kmmv1beta2 "github.com/rh-ecosystem-edge/kernel-module-management/api/v1beta2"
 	corev1 "k8s.io/api/core/v1"
 	kruntime "k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes/scheme"
@@ -102,6 +104,8 @@ func createFakeScheme() *kruntime.Scheme {
 		consolev1.AddToScheme,
 		operatorv1.AddToScheme,
 		kmmv1beta1.AddToScheme,
+		kmmv1beta2.AddToScheme,
+		operatorsv2.AddToScheme,
 	)
 	Expect(builder.AddToScheme(s)).To(Succeed())
 	return s
@@ -0,0 +1,164 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/manifestival/manifestival"
+	corev1 "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/api/meta"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	fusionv1alpha1 "github.com/openshift-storage-scale/openshift-fusion-access-operator/api/v1alpha1"
+	"github.com/openshift-storage-scale/openshift-fusion-access-operator/internal/controller/kernelmodule"
+)
+
+const (
+	ConditionUpgrading = "Upgrading"
+
+	// Reasons of the Upgrading condition, one per stage
+	ReasonUpgradePullingImages        = "PullingImages"
+	ReasonUpgradeBuildingKernelModule = "BuildingKernelModule"
+	ReasonUpgradeApplyingManifest     = "ApplyingManifest"
+	ReasonUpgradeFailed               = "UpgradeFailed"
+	ReasonUpgradeCompleted            = "UpgradeCompleted"
+
+	// How often we check the kernel module builds while upgrading
+	upgradeRequeueInterval = 30 * time.Second
+)
+
+// isUpgrading returns true when the requested IBM Storage Scale version differs from the installed one
+func isUpgrading(fusionaccess *fusionv1alpha1.FusionAccess) bool {
+	installed := fusionaccess.Status.InstalledStorageScaleVersion
+	requested := string(fusionaccess.Spec.StorageScaleVersion)
+	return installed != "" && requested != "" && installed != requested
+}
+
+// runStagedUpgrade runs the stages needed before the manifest of a new IBM Storage Scale version
+// can be applied: first we check that the new images can be pulled, then we let KMM build the kernel
+// modules for the new core image so the nodes do not wait on builds once the daemons are restarted.
+// It returns true once the manifest can be applied
+func (r *FusionAccessReconciler) runStagedUpgrade(
+	ctx context.Context,
+	ns string,
+	fusionaccess *fusionv1alpha1.FusionAccess,
+	installManifest manifestival.Manifest,
+) (bool, error) {
+	from := fusionaccess.Status.InstalledStorageScaleVersion
+	to := fusionaccess.Spec.StorageScaleVersion
+
+	// The image pull check is slow, skip it if it already passed for this generation
+	current := meta.FindStatusCondition(fusionaccess.Status.Conditions, ConditionUpgrading)
+	pulled := current != nil && current.ObservedGeneration == fusionaccess.Generation &&
+		(current.Reason == ReasonUpgradeBuildingKernelModule || current.Reason == ReasonUpgradeApplyingManifest)
+	if !pulled {
+		if err := r.setUpgradingCondition(ctx, fusionaccess, v1.ConditionTrue, ReasonUpgradePullingImages,
+			fmt.Sprintf("Upgrading from %s to %s: checking that the new images can be pulled", from, to)); err != nil {
+			return false, err
+		}
+		if err := r.runPullImageCheck(ctx, ns, fusionaccess); err != nil {
+			return false, r.failUpgrade(ctx, fusionaccess, fmt.Errorf("images of %s cannot be pulled: %w", to, err))
+		}
+	}
+
+	// Only pre-build if we are already managing the kernel module
+	modules, err := kernelmodule.ListKMMModules(ctx, r.Client, ns)
+	if err != nil {
+		return false, err
+	}
+	if len(modules) > 0 {
+		coreImage, err := getIBMCoreImageFromManifest(installManifest)
+		if err != nil {
+			return false, r.failUpgrade(ctx, fusionaccess, err)
+		}
+		built, err := kernelmodule.PrebuildKMMImages(ctx, r.Client, ns, coreImage, string(to))
+		if err != nil {
+			return false, r.failUpgrade(ctx, fusionaccess, err)
+		}
+		if !built {
+			log.Log.Info("Waiting for the kernel modules of the new core image to be built", "coreImage", coreImage)
+			return false, r.setUpgradingCondition(ctx, fusionaccess, v1.ConditionTrue, ReasonUpgradeBuildingKernelModule,
+				fmt.Sprintf("Upgrading from %s to %s: building the kernel modules for %s", from, to, coreImage))
+		}
+	}
+
+	return true, r.setUpgradingCondition(ctx, fusionaccess, v1.ConditionTrue, ReasonUpgradeApplyingManifest,
+		fmt.Sprintf("Upgrading from %s to %s: applying the new manifest", from, to))
+}
+
+// completeUpgrade is called once the new manifest has been applied
+func (r *FusionAccessReconciler) completeUpgrade(ctx context.Context, ns string, fusionaccess *fusionv1alpha1.FusionAccess) error {
+	if err := kernelmodule.DeleteKMMPrebuild(ctx, r.Client, ns); err != nil {
+		return err
+	}
+	meta.SetStatusCondition(&fusionaccess.Status.Conditions, v1.Condition{
+		Type:               ConditionUpgrading,
+		Status:             v1.ConditionFalse,
+		Reason:             ReasonUpgradeCompleted,
+		Message:            fmt.Sprintf("Upgraded from %s to %s", fusionaccess.Status.InstalledStorageScaleVersion, fusionaccess.Spec.StorageScaleVersion),
+		ObservedGeneration: fusionaccess.Generation,
+	})
+	return nil
+}
+
+func (r *FusionAccessReconciler) failUpgrade(ctx context.Context, fusionaccess *fusionv1alpha1.FusionAccess, err error) error {
+	log.Log.Error(err, "Upgrade failed")
+	setComponentCondition(fusionaccess, ConditionManifestApplied, ReasonFailed, err.Error())
+	serr := r.setUpgradingCondition(ctx, fusionaccess, v1.ConditionFalse, ReasonUpgradeFailed, err.Error())
+	if serr != nil {
+		return errors.Join(serr, err)
+	}
+	return err
+}
+
+func (r *FusionAccessReconciler) setUpgradingCondition(
+	ctx context.Context,
+	fusionaccess *fusionv1alpha1.FusionAccess,
+	status v1.ConditionStatus,
+	reason, message string,
+) error {
+	meta.SetStatusCondition(&fusionaccess.Status.Conditions, v1.Condition{
+		Type:               ConditionUpgrading,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: fusionaccess.Generation,
+	})
+	return r.updateStatus(ctx, fusionaccess)
+}
+
+// getIBMCoreImageFromManifest returns the core init image the given manifest will deploy
+func getIBMCoreImageFromManifest(installManifest manifestival.Manifest) (string, error) {
+	configMaps := installManifest.Filter(manifestival.ByKind("ConfigMap"), manifestival.ByName(kernelmodule.IBMManagerConfigName))
+	for _, u := range configMaps.Resources() {
+		if u.GetNamespace() != kernelmodule.IBMManagerConfigNamespace {
+			continue
+		}
+		cm := &corev1.ConfigMap{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, cm); err != nil {
+			return "", fmt.Errorf("failed to convert %s in getIBMCoreImageFromManifest: %w", kernelmodule.IBMManagerConfigName, err)
+		}
+		return kernelmodule.ParseIBMCoreImage(cm)
+	}
+	return "", fmt.Errorf("configmap %s not found in the manifest", kernelmodule.IBMManagerConfigName)
+}
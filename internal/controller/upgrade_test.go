@@ -0,0 +1,161 @@
+package controller
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	"github.com/manifestival/manifestival"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	kmmv1beta1 "github.com/rh-ecosystem-edge/kernel-module-management/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	fusionv1alpha "github.com/openshift-storage-scale/openshift-fusion-access-operator/api/v1alpha1"
+	"github.com/openshift-storage-scale/openshift-fusion-access-operator/internal/controller/kernelmodule"
+)
+
+var _ = Describe("Storage Scale upgrade", func() {
+	const ns = "ibm-fusion-access-operator"
+
+	var (
+		ctx        = context.Background()
+		scheme     = createFakeScheme()
+		cl         client.Client
+		reconciler *FusionAccessReconciler
+		fa         *fusionv1alpha.FusionAccess
+		pullChecks int
+	)
+
+	getFusionAccess := func() *fusionv1alpha.FusionAccess {
+		updated := &fusionv1alpha.FusionAccess{}
+		Expect(cl.Get(ctx, client.ObjectKeyFromObject(fa), updated)).To(Succeed())
+		return updated
+	}
+
+	BeforeEach(func() {
+		os.Setenv("DEPLOYMENT_NAMESPACE", ns)
+		pullChecks = 0
+		fa = &fusionv1alpha.FusionAccess{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       "fusionaccess",
+				Namespace:  ns,
+				Finalizers: []string{storageScaleFinalizer},
+			},
+			Spec: fusionv1alpha.FusionAccessSpec{StorageScaleVersion: "v5.2.3.1"},
+			Status: fusionv1alpha.FusionAccessStatus{
+				InstalledStorageScaleVersion: "v5.2.2.1",
+			},
+		}
+		storageNode := &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "worker-0",
+				Labels: map[string]string{
+					"kubernetes.io/arch":            "amd64",
+					kernelmodule.KMMNodeSelectorKey: kernelmodule.KMMNodeSelectorValue,
+				},
+			},
+			Status: corev1.NodeStatus{NodeInfo: corev1.NodeSystemInfo{KernelVersion: "5.14.0-570.el9.x86_64"}},
+		}
+		cl = fake.NewClientBuilder().
+			WithScheme(scheme).
+			WithObjects(fa, storageNode, newNamespace(ns), newOCPVersion("4.18.5"),
+				&operatorv1.Console{ObjectMeta: metav1.ObjectMeta{Name: "cluster"}},
+				&kmmv1beta1.Module{ObjectMeta: metav1.ObjectMeta{Name: kernelmodule.KMMModuleName, Namespace: ns}}).
+			WithStatusSubresource(&fusionv1alpha.FusionAccess{}).
+			Build()
+		reconciler = &FusionAccessReconciler{
+			Client: cl,
+			Scheme: scheme,
+			CanPullImage: func(_ context.Context, _ client.Client, _, _, _ string) (bool, error) {
+				pullChecks++
+				return true, nil
+			},
+		}
+	})
+
+	AfterEach(func() {
+		os.Unsetenv("DEPLOYMENT_NAMESPACE")
+	})
+
+	reconcileOnce := func() (reconcile.Result, error) {
+		return reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(fa)})
+	}
+
+	It("builds the kernel modules before applying the new manifest", func() {
+		By("waiting for the kernel module builds")
+		result, err := reconcileOnce()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(upgradeRequeueInterval))
+
+		updated := getFusionAccess()
+		cond := meta.FindStatusCondition(updated.Status.Conditions, ConditionUpgrading)
+		Expect(cond).ToNot(BeNil())
+		Expect(cond.Status).To(Equal(metav1.ConditionTrue))
+		Expect(cond.Reason).To(Equal(ReasonUpgradeBuildingKernelModule))
+		Expect(updated.Status.Status).To(Equal(fusionv1alpha.PhaseProgressing))
+		Expect(updated.Status.InstalledStorageScaleVersion).To(Equal("v5.2.2.1"))
+		Expect(meta.FindStatusCondition(updated.Status.Conditions, ConditionManifestApplied)).To(BeNil())
+
+		prebuild := &kmmv1beta1.Module{}
+		Expect(cl.Get(ctx, client.ObjectKey{Name: kernelmodule.KMMPrebuildName, Namespace: ns}, prebuild)).To(Succeed())
+		Expect(prebuild.Spec.ModuleLoader.Container.Version).To(Equal("v5.2.3.1"))
+		Expect(prebuild.Spec.Selector).To(HaveKeyWithValue(kernelmodule.KMMNodeSelectorKey, kernelmodule.KMMNodeSelectorValue))
+
+		By("applying the manifest once the images exist")
+		// KMM lists the images of the pre-build Module in the ModuleImagesConfig of the same name
+		image := strings.ReplaceAll(prebuild.Spec.ModuleLoader.Container.KernelMappings[0].ContainerImage,
+			"${KERNEL_FULL_VERSION}", "5.14.0-570.el9.x86_64")
+		Expect(cl.Create(ctx, &kmmv1beta1.ModuleImagesConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: kernelmodule.KMMPrebuildName, Namespace: ns},
+			Spec: kmmv1beta1.ModuleImagesConfigSpec{
+				Images: []kmmv1beta1.ModuleImageSpec{{Image: image, KernelVersion: "5.14.0-570.el9.x86_64"}},
+			},
+			Status: kmmv1beta1.ModuleImagesConfigStatus{
+				ImagesStates: []kmmv1beta1.ModuleImageState{{Image: image, Status: kmmv1beta1.ImageExists}},
+			},
+		})).To(Succeed())
+
+		_, err = reconcileOnce()
+		Expect(err).ToNot(HaveOccurred())
+		// The pull check is not repeated while waiting on the builds
+		Expect(pullChecks).To(Equal(2))
+
+		updated = getFusionAccess()
+		cond = meta.FindStatusCondition(updated.Status.Conditions, ConditionUpgrading)
+		Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+		Expect(cond.Reason).To(Equal(ReasonUpgradeCompleted))
+		Expect(updated.Status.InstalledStorageScaleVersion).To(Equal("v5.2.3.1"))
+		err = cl.Get(ctx, client.ObjectKey{Name: kernelmodule.KMMPrebuildName, Namespace: ns}, &kmmv1beta1.Module{})
+		Expect(kerrors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("stops the upgrade when the new images cannot be pulled", func() {
+		reconciler.CanPullImage = func(_ context.Context, _ client.Client, _, _, _ string) (bool, error) {
+			return false, kerrors.NewBadRequest("unauthorized")
+		}
+		_, err := reconcileOnce()
+		Expect(err).To(HaveOccurred())
+
+		updated := getFusionAccess()
+		cond := meta.FindStatusCondition(updated.Status.Conditions, ConditionUpgrading)
+		Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+		Expect(cond.Reason).To(Equal(ReasonUpgradeFailed))
+		Expect(updated.Status.InstalledStorageScaleVersion).To(Equal("v5.2.2.1"))
+	})
+
+	It("finds the core image in the IBM manifest", func() {
+		installManifest, err := manifestival.NewManifest("../../files/v5.2.3.1/install.yaml")
+		Expect(err).ToNot(HaveOccurred())
+		image, err := getIBMCoreImageFromManifest(installManifest)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(image).To(ContainSubstring("ibm-spectrum-scale-core-init"))
+	})
+})
@@ -0,0 +1,117 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Masterminds/semver/v3"
+	configv1 "github.com/openshift/api/config/v1"
+	meta "k8s.io/apimachinery/pkg/api/meta"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	fusionv1alpha1 "github.com/openshift-storage-scale/openshift-fusion-access-operator/api/v1alpha1"
+	"github.com/openshift-storage-scale/openshift-fusion-access-operator/internal/controller/operatorcondition"
+	"github.com/openshift-storage-scale/openshift-fusion-access-operator/internal/utils"
+)
+
+const (
+	// ConditionOpenShiftUpgradeable mirrors the Upgradeable condition we publish to OLM
+	ConditionOpenShiftUpgradeable = "OpenShiftUpgradeable"
+
+	ReasonNextMinorSupported   = "NextMinorSupported"
+	ReasonNextMinorUnsupported = "NextMinorUnsupported"
+	ReasonNoSupportMatrix      = "NoSupportMatrix"
+)
+
+// nextOpenShiftMinor returns the first release of the next OpenShift minor version
+func nextOpenShiftMinor(current *semver.Version) *semver.Version {
+	return semver.New(current.Major(), current.Minor()+1, 0, "", "")
+}
+
+// checkOpenShiftUpgradeable checks whether the installed IBM Storage Scale version supports the next
+// OpenShift minor. If it does not, the message names the Storage Scale version that needs to be installed first
+func checkOpenShiftUpgradeable(scaleVersion string, ocpVersion *semver.Version) (v1.ConditionStatus, string, string) {
+	if scaleVersion == "" {
+		return v1.ConditionTrue, ReasonNoSupportMatrix,
+			"No IBM Storage Scale version is set, OpenShift upgrades are not checked against the support matrix"
+	}
+	next := nextOpenShiftMinor(ocpVersion)
+	nextMinor := fmt.Sprintf("%d.%d", next.Major(), next.Minor())
+	if utils.IsOpenShiftSupported(scaleVersion, *next) {
+		return v1.ConditionTrue, ReasonNextMinorSupported,
+			fmt.Sprintf("IBM Storage Scale %s supports OpenShift %s", scaleVersion, nextMinor)
+	}
+
+	required, err := utils.GetOldestStorageScaleVersionFor(scaleVersion, *next)
+	if err != nil || required == "" {
+		return v1.ConditionFalse, ReasonNextMinorUnsupported,
+			fmt.Sprintf("IBM Storage Scale %s does not support OpenShift %s and no IBM Storage Scale version supporting it is available yet",
+				scaleVersion, nextMinor)
+	}
+	return v1.ConditionFalse, ReasonNextMinorUnsupported,
+		fmt.Sprintf("IBM Storage Scale %s does not support OpenShift %s, upgrade IBM Storage Scale to %s first",
+			scaleVersion, nextMinor, required)
+}
+
+// updateUpgradeable publishes whether the cluster can move to the next OpenShift minor to OLM
+// and mirrors it in the FusionAccess conditions. The caller is responsible for updating the status
+func (r *FusionAccessReconciler) updateUpgradeable(ctx context.Context, ns string, fusionaccess *fusionv1alpha1.FusionAccess) error {
+	clusterVersion := &configv1.ClusterVersion{}
+	if err := r.Get(ctx, types.NamespacedName{Name: "version"}, clusterVersion); err != nil {
+		return fmt.Errorf("failed to get ClusterVersion in updateUpgradeable: %w", err)
+	}
+	ocpVersion, err := utils.GetCurrentClusterVersion(clusterVersion)
+	if err != nil {
+		return fmt.Errorf("failed to get current cluster version in updateUpgradeable: %w", err)
+	}
+
+	scaleVersion := fusionaccess.Status.InstalledStorageScaleVersion
+	if scaleVersion == "" {
+		scaleVersion = string(fusionaccess.Spec.StorageScaleVersion)
+	}
+	status, reason, message := checkOpenShiftUpgradeable(scaleVersion, ocpVersion)
+	if status == v1.ConditionFalse {
+		log.Log.Info("Blocking OpenShift upgrades", "reason", message)
+	}
+	if err := operatorcondition.SetUpgradeable(ctx, r.Client, ns, status, reason, message); err != nil {
+		return err
+	}
+	// Upgradeable=False only holds back the upgrades of the operator itself, the OpenShift minor
+	// upgrades are held back by the olm.maxOpenShiftVersion property of our CSV
+	maxOpenShiftVersion := ""
+	if scaleVersion != "" {
+		if maxOpenShiftVersion, err = utils.GetMaxOpenShiftVersion(scaleVersion); err != nil {
+			log.Log.Info("No support matrix for IBM Storage Scale, not publishing a maximum OpenShift version",
+				"version", scaleVersion, "error", err.Error())
+		}
+	}
+	if err := operatorcondition.SetMaxOpenShiftVersion(ctx, r.Client, ns, maxOpenShiftVersion); err != nil {
+		return err
+	}
+	meta.SetStatusCondition(&fusionaccess.Status.Conditions, v1.Condition{
+		Type:               ConditionOpenShiftUpgradeable,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: fusionaccess.Generation,
+	})
+	return nil
+}
@@ -0,0 +1,149 @@
+package controller
+
+import (
+	"context"
+	"os"
+
+	"github.com/Masterminds/semver/v3"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	operatorsv2 "github.com/operator-framework/api/pkg/operators/v2"
+	meta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	fusionv1alpha "github.com/openshift-storage-scale/openshift-fusion-access-operator/api/v1alpha1"
+	"github.com/openshift-storage-scale/openshift-fusion-access-operator/internal/controller/operatorcondition"
+)
+
+var _ = Describe("OpenShift Upgradeable condition", func() {
+	const (
+		ns                    = "ibm-fusion-access-operator"
+		operatorConditionName = "openshift-fusion-access-operator.v1.0.0"
+	)
+
+	var (
+		ctx        = context.Background()
+		scheme     = createFakeScheme()
+		cl         client.Client
+		reconciler *FusionAccessReconciler
+		fa         *fusionv1alpha.FusionAccess
+	)
+
+	csvGVK := schema.GroupVersionKind{Group: "operators.coreos.com", Version: "v1alpha1", Kind: "ClusterServiceVersion"}
+
+	buildReconciler := func(ocpVersion string) {
+		csv := &unstructured.Unstructured{}
+		csv.SetGroupVersionKind(csvGVK)
+		csv.SetName(operatorConditionName)
+		csv.SetNamespace(ns)
+		csv.SetAnnotations(map[string]string{
+			"operatorframework.io/properties": `{"properties":[{"type":"olm.package","value":{"packageName":"openshift-fusion-access-operator"}}]}`,
+		})
+		cl = fake.NewClientBuilder().
+			WithScheme(scheme).
+			WithObjects(fa, newOCPVersion(ocpVersion), csv,
+				&operatorsv2.OperatorCondition{ObjectMeta: metav1.ObjectMeta{Name: operatorConditionName, Namespace: ns}}).
+			Build()
+		reconciler = &FusionAccessReconciler{Client: cl, Scheme: scheme}
+	}
+
+	getUpgradeable := func() *metav1.Condition {
+		operatorCondition := &operatorsv2.OperatorCondition{}
+		Expect(cl.Get(ctx, client.ObjectKey{Name: operatorConditionName, Namespace: ns}, operatorCondition)).To(Succeed())
+		return meta.FindStatusCondition(operatorCondition.Spec.Conditions, operatorsv2.Upgradeable)
+	}
+
+	getCSVProperties := func() string {
+		csv := &unstructured.Unstructured{}
+		csv.SetGroupVersionKind(csvGVK)
+		Expect(cl.Get(ctx, client.ObjectKey{Name: operatorConditionName, Namespace: ns}, csv)).To(Succeed())
+		return csv.GetAnnotations()["operatorframework.io/properties"]
+	}
+
+	BeforeEach(func() {
+		os.Setenv(operatorcondition.OperatorConditionNameEnv, operatorConditionName)
+		fa = &fusionv1alpha.FusionAccess{
+			ObjectMeta: metav1.ObjectMeta{Name: "fusionaccess", Namespace: ns},
+			Spec:       fusionv1alpha.FusionAccessSpec{StorageScaleVersion: "v5.2.3.1"},
+			Status:     fusionv1alpha.FusionAccessStatus{InstalledStorageScaleVersion: "v5.2.3.1"},
+		}
+	})
+
+	AfterEach(func() {
+		os.Unsetenv(operatorcondition.OperatorConditionNameEnv)
+	})
+
+	It("allows the upgrade when the next minor is supported", func() {
+		buildReconciler("4.18.5")
+		Expect(reconciler.updateUpgradeable(ctx, ns, fa)).To(Succeed())
+
+		cond := getUpgradeable()
+		Expect(cond).ToNot(BeNil())
+		Expect(cond.Status).To(Equal(metav1.ConditionTrue))
+		Expect(cond.Reason).To(Equal(ReasonNextMinorSupported))
+		Expect(meta.IsStatusConditionTrue(fa.Status.Conditions, ConditionOpenShiftUpgradeable)).To(BeTrue())
+	})
+
+	It("publishes the newest supported OpenShift minor in the CSV properties", func() {
+		buildReconciler("4.18.5")
+		Expect(reconciler.updateUpgradeable(ctx, ns, fa)).To(Succeed())
+		Expect(getCSVProperties()).To(MatchJSON(`{"properties":[
+			{"type":"olm.package","value":{"packageName":"openshift-fusion-access-operator"}},
+			{"type":"olm.maxOpenShiftVersion","value":"4.19"}]}`))
+
+		// the property follows the installed Storage Scale version
+		fa.Status.InstalledStorageScaleVersion = ""
+		fa.Spec.StorageScaleVersion = ""
+		Expect(reconciler.updateUpgradeable(ctx, ns, fa)).To(Succeed())
+		Expect(getCSVProperties()).To(MatchJSON(`{"properties":[
+			{"type":"olm.package","value":{"packageName":"openshift-fusion-access-operator"}}]}`))
+	})
+
+	It("sets the generation it observed on the mirrored condition", func() {
+		fa.Generation = 3
+		buildReconciler("4.18.5")
+		Expect(reconciler.updateUpgradeable(ctx, ns, fa)).To(Succeed())
+		Expect(meta.FindStatusCondition(fa.Status.Conditions, ConditionOpenShiftUpgradeable).ObservedGeneration).
+			To(Equal(int64(3)))
+	})
+
+	It("blocks the upgrade when the next minor is not supported", func() {
+		buildReconciler("4.19.2")
+		Expect(reconciler.updateUpgradeable(ctx, ns, fa)).To(Succeed())
+
+		cond := getUpgradeable()
+		Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+		Expect(cond.Reason).To(Equal(ReasonNextMinorUnsupported))
+		Expect(cond.Message).To(ContainSubstring("OpenShift 4.20"))
+		Expect(meta.IsStatusConditionFalse(fa.Status.Conditions, ConditionOpenShiftUpgradeable)).To(BeTrue())
+	})
+
+	It("does not fail when not running through OLM", func() {
+		os.Unsetenv(operatorcondition.OperatorConditionNameEnv)
+		buildReconciler("4.19.2")
+		Expect(reconciler.updateUpgradeable(ctx, ns, fa)).To(Succeed())
+		Expect(getUpgradeable()).To(BeNil())
+	})
+
+	It("does not block upgrades without a Storage Scale version", func() {
+		fa.Spec.StorageScaleVersion = ""
+		fa.Status.InstalledStorageScaleVersion = ""
+		buildReconciler("4.19.2")
+		Expect(reconciler.updateUpgradeable(ctx, ns, fa)).To(Succeed())
+		Expect(getUpgradeable().Status).To(Equal(metav1.ConditionTrue))
+	})
+})
+
+var _ = DescribeTable("checkOpenShiftUpgradeable",
+	func(scaleVersion, ocpVersion string, expected metav1.ConditionStatus, messagePart string) {
+		status, _, message := checkOpenShiftUpgradeable(scaleVersion, semver.MustParse(ocpVersion))
+		Expect(status).To(Equal(expected))
+		Expect(message).To(ContainSubstring(messagePart))
+	},
+	Entry("supported next minor", "v5.2.3.1", "4.17.10", metav1.ConditionTrue, "supports OpenShift 4.18"),
+	Entry("no newer version available", "v5.2.3.1", "4.19.0", metav1.ConditionFalse, "available yet"),
+)
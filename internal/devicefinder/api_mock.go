@@ -2,6 +2,7 @@ package devicefinder
 
 import (
 	"github.com/openshift-storage-scale/openshift-fusion-access-operator/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -13,6 +14,12 @@ type MockAPIUpdater struct {
 	MockUpdateDiscoveryResultStatus func(lvdr *v1alpha1.LocalVolumeDiscoveryResult) error
 	MockUpdateDiscoveryResult       func(lvdr *v1alpha1.LocalVolumeDiscoveryResult) error
 	MockGetLocalVolumeDiscovery     func(name, namespace string) (*v1alpha1.LocalVolumeDiscovery, error)
+	MockListLocalDisks              func(namespace string) ([]unstructured.Unstructured, error)
+	MockListDeviceWipeRequests      func() ([]v1alpha1.DeviceWipeRequest, error)
+	MockUpdateDeviceWipeRequest     func(wipeRequest *v1alpha1.DeviceWipeRequest) error
+	MockGetNodeFacts                func(name, namespace string) (*v1alpha1.NodeFacts, error)
+	MockCreateNodeFacts             func(nodeFacts *v1alpha1.NodeFacts) error
+	MockUpdateNodeFactsStatus       func(nodeFacts *v1alpha1.NodeFacts) error
 }
 
 var _ ApiUpdater = &MockAPIUpdater{}
@@ -65,3 +72,62 @@ func (f *MockAPIUpdater) GetLocalVolumeDiscovery(name, namespace string) (*v1alp
 
 	return &v1alpha1.LocalVolumeDiscovery{}, nil
 }
+
+// ListLocalDisks mocks ListLocalDisks
+func (f *MockAPIUpdater) ListLocalDisks(namespace string) ([]unstructured.Unstructured, error) {
+	if f.MockListLocalDisks != nil {
+		return f.MockListLocalDisks(namespace)
+	}
+
+	return nil, nil
+}
+
+// ListDeviceWipeRequests mocks ListDeviceWipeRequests
+func (f *MockAPIUpdater) ListDeviceWipeRequests() ([]v1alpha1.DeviceWipeRequest, error) {
+	if f.MockListDeviceWipeRequests != nil {
+		return f.MockListDeviceWipeRequests()
+	}
+
+	return nil, nil
+}
+
+// UpdateDeviceWipeRequestStatus mocks UpdateDeviceWipeRequestStatus
+func (f *MockAPIUpdater) UpdateDeviceWipeRequestStatus(wipeRequest *v1alpha1.DeviceWipeRequest) error {
+	if f.MockUpdateDeviceWipeRequest != nil {
+		return f.MockUpdateDeviceWipeRequest(wipeRequest)
+	}
+
+	return nil
+}
+
+// GetNodeFacts mocks GetNodeFacts
+func (f *MockAPIUpdater) GetNodeFacts(name, namespace string) (*v1alpha1.NodeFacts, error) {
+	if f.MockGetNodeFacts != nil {
+		return f.MockGetNodeFacts(name, namespace)
+	}
+
+	return &v1alpha1.NodeFacts{}, nil
+}
+
+// CreateNodeFacts mocks CreateNodeFacts
+func (f *MockAPIUpdater) CreateNodeFacts(nodeFacts *v1alpha1.NodeFacts) error {
+	if f.MockCreateNodeFacts != nil {
+		return f.MockCreateNodeFacts(nodeFacts)
+	}
+
+	return nil
+}
+
+// UpdateNodeFactsStatus mocks UpdateNodeFactsStatus
+func (f *MockAPIUpdater) UpdateNodeFactsStatus(nodeFacts *v1alpha1.NodeFacts) error {
+	if f.MockUpdateNodeFactsStatus != nil {
+		return f.MockUpdateNodeFactsStatus(nodeFacts)
+	}
+
+	return nil
+}
+
+// Events returns the recorded events
+func (f *MockAPIUpdater) Events() []*DiskEvent {
+	return f.events
+}
@@ -6,6 +6,8 @@ import (
 	"os"
 
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
@@ -16,6 +18,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client/config"
 
 	"github.com/openshift-storage-scale/openshift-fusion-access-operator/api/v1alpha1"
+	"github.com/openshift-storage-scale/openshift-fusion-access-operator/internal/common"
 )
 
 const componentName = "local-storage-devicefinder"
@@ -27,6 +30,12 @@ type ApiUpdater interface {
 	UpdateDiscoveryResultStatus(lvdr *v1alpha1.LocalVolumeDiscoveryResult) error
 	UpdateDiscoveryResult(lvdr *v1alpha1.LocalVolumeDiscoveryResult) error
 	GetLocalVolumeDiscovery(name, namespace string) (*v1alpha1.LocalVolumeDiscovery, error)
+	ListLocalDisks(namespace string) ([]unstructured.Unstructured, error)
+	ListDeviceWipeRequests() ([]v1alpha1.DeviceWipeRequest, error)
+	UpdateDeviceWipeRequestStatus(wipeRequest *v1alpha1.DeviceWipeRequest) error
+	GetNodeFacts(name, namespace string) (*v1alpha1.NodeFacts, error)
+	CreateNodeFacts(nodeFacts *v1alpha1.NodeFacts) error
+	UpdateNodeFactsStatus(nodeFacts *v1alpha1.NodeFacts) error
 }
 
 type sdkAPIUpdater struct {
@@ -128,3 +137,38 @@ func (s *sdkAPIUpdater) GetLocalVolumeDiscovery(
 	)
 	return discoveryCR, err
 }
+
+// ListLocalDisks lists the IBM Storage Scale LocalDisks. It returns an empty list until their CRD is installed
+func (s *sdkAPIUpdater) ListLocalDisks(namespace string) ([]unstructured.Unstructured, error) {
+	localDisks := &unstructured.UnstructuredList{}
+	localDisks.SetGroupVersionKind(common.LocalDiskGVK.GroupVersion().WithKind(common.LocalDiskGVK.Kind + "List"))
+	err := s.client.List(context.TODO(), localDisks, client.InNamespace(namespace))
+	if meta.IsNoMatchError(err) {
+		return nil, nil
+	}
+	return localDisks.Items, err
+}
+
+func (s *sdkAPIUpdater) ListDeviceWipeRequests() ([]v1alpha1.DeviceWipeRequest, error) {
+	wipeRequests := &v1alpha1.DeviceWipeRequestList{}
+	err := s.client.List(context.TODO(), wipeRequests)
+	return wipeRequests.Items, err
+}
+
+func (s *sdkAPIUpdater) UpdateDeviceWipeRequestStatus(wipeRequest *v1alpha1.DeviceWipeRequest) error {
+	return s.client.Status().Update(context.TODO(), wipeRequest)
+}
+
+func (s *sdkAPIUpdater) GetNodeFacts(name, namespace string) (*v1alpha1.NodeFacts, error) {
+	nodeFacts := &v1alpha1.NodeFacts{}
+	err := s.client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: namespace}, nodeFacts)
+	return nodeFacts, err
+}
+
+func (s *sdkAPIUpdater) CreateNodeFacts(nodeFacts *v1alpha1.NodeFacts) error {
+	return s.client.Create(context.TODO(), nodeFacts)
+}
+
+func (s *sdkAPIUpdater) UpdateNodeFactsStatus(nodeFacts *v1alpha1.NodeFacts) error {
+	return s.client.Status().Update(context.TODO(), nodeFacts)
+}
@@ -22,6 +22,8 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
+	"github.com/openshift-storage-scale/openshift-fusion-access-operator/internal/diskutils"
+
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	//+kubebuilder:scaffold:imports
@@ -39,6 +41,11 @@ func TestDevicefinder(t *testing.T) {
 var _ = BeforeSuite(func() {
 	logf.SetLogger(zap.New(zap.WriteTo(GinkgoWriter), zap.UseDevMode(true)))
 
+	// The tests do not have access to the devices, their headers are all empty
+	diskutils.ReadDeviceHeader = func(string) ([]byte, error) { return make([]byte, diskutils.HeaderSize), nil }
+	// nor can they be probed for persistent reservations
+	diskutils.Ioctl = fakeIoctl{}
+
 	By("bootstrapping test environment")
 	// testEnv = &envtest.Environment{
 	// 	CRDDirectoryPaths:     []string{filepath.Join("..", "..", "config", "crd", "bases")},
@@ -1,14 +1,16 @@
 package discovery
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"os/signal"
+	"path"
 	"reflect"
 	"syscall"
 	"time"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/klog/v2"
@@ -22,17 +24,28 @@ const (
 	localVolumeDiscoveryComponent = "auto-discover-devices"
 	udevEventPeriod               = 5 * time.Second
 	probeInterval                 = 5 * time.Minute
+	wipeRequestInterval           = 10 * time.Second
 	resultCRName                  = "discovery-result-%s"
 )
 
-var supportedDeviceTypes = sets.NewString("mpath", "disk")
-
 // DeviceDiscovery instance
 type DeviceDiscovery struct {
 	apiClient            devicefinder.ApiUpdater
 	eventSync            *devicefinder.EventReporter
 	disks                []v1alpha1.DiscoveredDevice
+	rejectedDisks        []v1alpha1.RejectedDevice
 	localVolumeDiscovery *v1alpha1.LocalVolumeDiscovery
+	// expectedPaths is the highest number of paths seen for each multipath LUN, by WWN
+	expectedPaths map[string]int32
+	// degradedLUNs are the WWNs of the LUNs used by Storage Scale that lost paths
+	degradedLUNs       sets.Set[string]
+	multipathCondition metav1.Condition
+	// initiators are the SAN initiators of the node
+	initiators *v1alpha1.NodeInitiators
+	// nodeFacts are the facts of the host last published in its NodeFacts
+	nodeFacts *v1alpha1.NodeFactsStatus
+	// probes caches what was read from the discovered devices themselves
+	probes *deviceProbes
 }
 
 // NewDeviceDiscovery returns a new DeviceDiscovery instance
@@ -50,7 +63,7 @@ func NewDeviceDiscovery() (*DeviceDiscovery, error) {
 		return &DeviceDiscovery{}, err
 	}
 
-	dd := &DeviceDiscovery{}
+	dd := &DeviceDiscovery{probes: newDeviceProbes()}
 	dd.apiClient = apiUpdater
 	dd.eventSync = devicefinder.NewEventReporter(dd.apiClient)
 	lvd, err := dd.apiClient.GetLocalVolumeDiscovery(
@@ -84,25 +97,45 @@ func (discovery *DeviceDiscovery) Start() error {
 	if err != nil {
 		return fmt.Errorf("failed to discover devices: %w", err)
 	}
+	if err := discovery.syncNodeFacts(); err != nil {
+		klog.Errorf("failed to publish the node facts. %v", err)
+	}
 
 	// Watch udev events for continuous discovery of devices
 	sigc := make(chan os.Signal, 1)
 	signal.Notify(sigc, syscall.SIGTERM)
 
-	udevEvents := make(chan string)
-	go udevBlockMonitor(udevEvents, udevEventPeriod)
+	health := newMonitorHealth()
+	go serveHealth(healthProbeAddress, health)
+
+	udevEvents := make(chan sets.Set[string])
+	go udevBlockMonitor(udevEvents, udevEventPeriod, health)
+	probeTicker := time.NewTicker(probeInterval)
+	defer probeTicker.Stop()
+	wipeTicker := time.NewTicker(wipeRequestInterval)
+	defer wipeTicker.Stop()
 	for {
 		select {
 		case <-sigc:
 			klog.Info("shutdown signal received, exiting...")
 			return nil
-		case <-time.After(probeInterval):
+		case <-probeTicker.C:
 			if err := discovery.discoverDevices(); err != nil {
 				klog.Errorf("failed to discover devices during probe interval. %v", err)
 			}
-		case _, ok := <-udevEvents:
+			if err := discovery.syncNodeFacts(); err != nil {
+				klog.Errorf("failed to publish the node facts during probe interval. %v", err)
+			}
+		case <-wipeTicker.C:
+			if discovery.processWipeRequests() {
+				if err := discovery.discoverDevices(); err != nil {
+					klog.Errorf("failed to discover devices after a wipe. %v", err)
+				}
+			}
+		case devices, ok := <-udevEvents:
 			if ok {
-				klog.Info("trigger probe from udev event")
+				klog.Infof("trigger probe from udev events of %v", sets.List(devices))
+				discovery.probes.invalidate(devices)
 				if err := discovery.discoverDevices(); err != nil {
 					klog.Errorf("failed to discover devices triggered from udev event. %v", err)
 				}
@@ -131,13 +164,32 @@ func (discovery *DeviceDiscovery) discoverDevices() error {
 
 	klog.Infof("valid block devices: %+v", validDevices)
 
-	discoveredDisks := getDiscoverdDevices(validDevices)
+	filter, err := discovery.getDeviceFilter()
+	if err != nil {
+		message := "failed to apply the device filter"
+		e := devicefinder.NewEvent(
+			devicefinder.ErrorInvalidDeviceFilter,
+			fmt.Sprintf("%s. Error: %+v", message, err),
+			"",
+		)
+		discovery.eventSync.Report(e, discovery.localVolumeDiscovery)
+		return fmt.Errorf("%s: %w", message, err)
+	}
+
+	discoveredDisks, rejectedDisks := getDiscoverdDevices(validDevices, filter)
+	discovery.probes.apply(discoveredDisks)
 	klog.Infof("discovered devices: %+v", discoveredDisks)
+	condition := discovery.checkMultipathPaths(discoveredDisks)
+	initiators := getInitiators()
 
 	// Update discovered devices in the  LocalVolumeDiscoveryResult resource
-	if !reflect.DeepEqual(discovery.disks, discoveredDisks) {
+	if !reflect.DeepEqual(discovery.disks, discoveredDisks) || !reflect.DeepEqual(discovery.rejectedDisks, rejectedDisks) ||
+		discovery.multipathCondition != condition || !reflect.DeepEqual(discovery.initiators, initiators) {
 		klog.Info("device list updated. Updating LocalVolumeDiscoveryResult status...")
 		discovery.disks = discoveredDisks
+		discovery.rejectedDisks = rejectedDisks
+		discovery.multipathCondition = condition
+		discovery.initiators = initiators
 		err = discovery.updateStatus()
 		if err != nil {
 			message := "failed to update LocalVolumeDiscoveryResult status"
@@ -157,28 +209,78 @@ func (discovery *DeviceDiscovery) discoverDevices() error {
 	return nil
 }
 
-// getValidBlockDevices fetches and unmarshalls all the block devices sutitable for discovery
-func getValidBlockDevices() ([]diskutils.BlockDevice, error) {
-	lDevices := diskutils.BlockDeviceList{}
-	blockDevices, err := diskutils.GetBlockDevices()
+// getDeviceFilter compiles the device filter of the LocalVolumeDiscovery. The object is fetched again
+// on every discovery, so that changes to the filter are picked up without restarting the daemon
+func (discovery *DeviceDiscovery) getDeviceFilter() (*deviceFilter, error) {
+	lvd, err := discovery.apiClient.GetLocalVolumeDiscovery(
+		discovery.localVolumeDiscovery.Name,
+		discovery.localVolumeDiscovery.Namespace,
+	)
+	if err != nil {
+		klog.Warningf("failed to refresh the LocalVolumeDiscovery object, using the last known device filter. Error %v", err)
+	} else {
+		discovery.localVolumeDiscovery = lvd
+	}
+
+	byIDLinks, err := diskutils.GetDeviceLinks(diskutils.DiskByIDDir)
 	if err != nil {
-		return lDevices.BlockDevices, err
+		klog.Warningf("failed to list the by-id device links. Error %v", err)
 	}
-	err = json.Unmarshal(blockDevices, &lDevices)
+	byPathLinks, err := diskutils.GetDeviceLinks(diskutils.DiskByPathDir)
 	if err != nil {
-		return lDevices.BlockDevices, err
+		klog.Warningf("failed to list the by-path device links. Error %v", err)
 	}
+	return newDeviceFilter(discovery.localVolumeDiscovery.Spec.DeviceFilter, byIDLinks, byPathLinks)
+}
 
-	return lDevices.BlockDevices, nil
+// getInitiators reads the Fibre Channel ports and the iSCSI initiator of the node, it returns nil when the
+// node has none
+func getInitiators() *v1alpha1.NodeInitiators {
+	initiators := diskutils.Initiators.ReadInitiators()
+	if len(initiators.FCHosts) == 0 && initiators.ISCSIInitiatorName == "" {
+		return nil
+	}
+	nodeInitiators := &v1alpha1.NodeInitiators{ISCSIInitiatorName: initiators.ISCSIInitiatorName}
+	for _, host := range initiators.FCHosts {
+		nodeInitiators.FCHosts = append(nodeInitiators.FCHosts, v1alpha1.FCHost{
+			Name:       host.Name,
+			PortName:   host.PortName,
+			NodeName:   host.NodeName,
+			PortState:  host.PortState,
+			Speed:      host.Speed,
+			FabricName: host.FabricName,
+		})
+	}
+	return nodeInitiators
+}
+
+// getValidBlockDevices reads all the block devices sutitable for discovery
+func getValidBlockDevices() ([]diskutils.BlockDevice, error) {
+	return diskutils.DeviceReader.ReadBlockDevices()
 }
 
-// getDiscoverdDevices creates v1alpha1.DiscoveredDevice from diskutil.BlockDevices
-func getDiscoverdDevices(blockDevices []diskutils.BlockDevice) []v1alpha1.DiscoveredDevice {
+// getDiscoverdDevices creates v1alpha1.DiscoveredDevice from diskutil.BlockDevices. The devices that are
+// not usable are returned as v1alpha1.RejectedDevice, along with the reason they were rejected. The
+// attributes read from the devices themselves are set by deviceProbes
+func getDiscoverdDevices(
+	blockDevices []diskutils.BlockDevice,
+	filter *deviceFilter,
+) ([]v1alpha1.DiscoveredDevice, []v1alpha1.RejectedDevice) {
 	discoveredDevices := make([]v1alpha1.DiscoveredDevice, 0)
+	rejectedDevices := make([]v1alpha1.RejectedDevice, 0)
 	for idx := range blockDevices {
-		if ignoreDevices(&blockDevices[idx]) {
+		reason, message := rejectDevice(&blockDevices[idx], filter)
+		if reason == "" {
+			if message = filter.excludes(&blockDevices[idx]); message != "" {
+				reason = v1alpha1.RejectedByDeviceFilter
+			}
+		}
+		if reason != "" {
+			klog.Infof("ignoring device %q: %s", blockDevices[idx].Name, message)
+			rejectedDevices = append(rejectedDevices, newRejectedDevice(&blockDevices[idx], reason, message))
 			continue
 		}
+
 		deviceID, err := blockDevices[idx].GetPathByID()
 		if err != nil {
 			klog.Warningf(
@@ -206,19 +308,117 @@ func getDiscoverdDevices(blockDevices []diskutils.BlockDevice) []v1alpha1.Discov
 			Size:     blockDevices[idx].Size,
 			WWN:      blockDevices[idx].WWN,
 		}
+		setHardwareAttributes(&discoveredDevice, &blockDevices[idx])
 		discoveredDevices = append(discoveredDevices, discoveredDevice)
 	}
-	return uniqueDevices(discoveredDevices)
+	discoveredDevices = uniqueDevices(discoveredDevices)
+	setPathCounts(discoveredDevices)
+	return discoveredDevices, uniqueRejectedDevices(rejectedDevices)
 }
 
-// uniqueDevices removes duplicate devices from the list using WWN as a key
+// setHardwareAttributes fills the hardware attributes of a discovered device. A multipath device is
+// reported through each of its members, which become its MultipathMembers
+func setHardwareAttributes(discovered *v1alpha1.DiscoveredDevice, dev *diskutils.BlockDevice) {
+	discovered.Serial = dev.Serial
+	discovered.Property = v1alpha1.NonRotational
+	if dev.Rotational {
+		discovered.Property = v1alpha1.Rotational
+	}
+	discovered.Transport = dev.Transport
+	discovered.LogicalSectorSize = dev.LogicalSectorSize
+	discovered.PhysicalSectorSize = dev.PhysicalSectorSize
+	discovered.NUMANode = dev.NUMANode
+
+	var byPath string
+	if len(dev.ByPathLinks) > 0 {
+		byPath = path.Join(diskutils.DiskByPathDir, dev.ByPathLinks[0])
+	}
+	if dev.FSType != "mpath_member" {
+		discovered.ByPath = byPath
+		return
+	}
+	discovered.MultipathMembers = []v1alpha1.MultipathMember{{
+		Name:      dev.KName,
+		Path:      fmt.Sprintf("/dev/%s", dev.KName),
+		ByPath:    byPath,
+		State:     dev.State,
+		Transport: dev.Transport,
+		NUMANode:  dev.NUMANode,
+	}}
+}
+
+// setHeader classifies what the first sectors of a discovered device hold. A disk already used as a
+// Storage Scale NSD has no signature lsblk knows about, and must not be offered as a free disk
+func setHeader(discovered *v1alpha1.DiscoveredDevice) {
+	data, err := diskutils.ReadDeviceHeader(discovered.Path)
+	if err != nil {
+		klog.Warningf("failed to read the header of the device %q. Error %v", discovered.Path, err)
+		discovered.Header = v1alpha1.HeaderUnknown
+		return
+	}
+	header := diskutils.ClassifyHeader(data)
+	switch {
+	case header.GPFS:
+		discovered.Header = v1alpha1.HeaderGPFSNSD
+		discovered.NSDName = header.NSDName
+	case header.Signature != "":
+		discovered.Header = v1alpha1.HeaderForeignSignature
+		discovered.Signature = header.Signature
+	default:
+		discovered.Header = v1alpha1.HeaderFree
+	}
+}
+
+// setPersistentReservation probes whether a discovered device supports SCSI-3 persistent reservations.
+// Devices that are not SCSI, like virtio disks, cannot be probed and are reported as Unknown
+func setPersistentReservation(discovered *v1alpha1.DiscoveredDevice) {
+	types, err := diskutils.ProbePersistentReservation(discovered.Path)
+	switch {
+	case errors.Is(err, diskutils.ErrPersistentReservationUnsupported):
+		discovered.PersistentReservation = v1alpha1.PersistentReservationUnsupported
+	case err != nil:
+		klog.V(2).Infof("failed to probe the persistent reservations of the device %q. Error %v", discovered.Path, err)
+		discovered.PersistentReservation = v1alpha1.PersistentReservationUnknown
+	default:
+		discovered.PersistentReservation = v1alpha1.PersistentReservationSupported
+		discovered.PersistentReservationTypes = types
+	}
+}
+
+// newRejectedDevice creates v1alpha1.RejectedDevice from a diskutil.BlockDevice
+func newRejectedDevice(dev *diskutils.BlockDevice, reason v1alpha1.RejectionReason, message string) v1alpha1.RejectedDevice {
+	// Rejected devices do not always have a persistent ID or a multipath device
+	deviceID, _ := dev.GetPathByID()
+	path, err := dev.GetDevPath()
+	if err != nil || path == "" {
+		path = fmt.Sprintf("/dev/%s", dev.KName)
+	}
+	return v1alpha1.RejectedDevice{
+		Path:     path,
+		DeviceID: deviceID,
+		WWN:      dev.WWN,
+		Model:    dev.Model,
+		Vendor:   dev.Vendor,
+		Size:     dev.Size,
+		Reason:   reason,
+		Message:  message,
+	}
+}
+
+// uniqueDevices removes duplicate devices from the list using WWN as a key. Devices without a WWN
+// cannot be told apart that way and are keyed on their path instead
 func uniqueDevices(sample []v1alpha1.DiscoveredDevice) []v1alpha1.DiscoveredDevice {
 	var unique []v1alpha1.DiscoveredDevice
-	type key struct{ value string }
+	type key struct{ wwn, path string }
 	m := make(map[key]int)
 	for _, v := range sample {
-		k := key{v.WWN}
+		k := key{wwn: v.WWN}
+		if v.WWN == "" {
+			k.path = v.Path
+		}
 		if i, ok := m[k]; ok {
+			// Every member of a multipath device reports it, keep all of its paths
+			v.MultipathMembers = append(unique[i].MultipathMembers, v.MultipathMembers...)
 			unique[i] = v
 		} else {
 			m[k] = len(unique)
@@ -228,76 +428,78 @@ func uniqueDevices(sample []v1alpha1.DiscoveredDevice) []v1alpha1.DiscoveredDevi
 	return unique
 }
 
-// ignoreDevices checks if a device should be ignored during discovery
-func ignoreDevices(dev *diskutils.BlockDevice) bool {
+// uniqueRejectedDevices removes the duplicate paths of a multipath device from the list using the path as a key
+func uniqueRejectedDevices(sample []v1alpha1.RejectedDevice) []v1alpha1.RejectedDevice {
+	var unique []v1alpha1.RejectedDevice
+	seen := sets.New[string]()
+	for _, v := range sample {
+		if !seen.Has(v.Path) {
+			seen.Insert(v.Path)
+			unique = append(unique, v)
+		}
+	}
+	return unique
+}
+
+// rejectDevice checks if a device should be ignored during discovery. It returns an empty reason for usable devices
+func rejectDevice(dev *diskutils.BlockDevice, filter *deviceFilter) (v1alpha1.RejectionReason, string) {
 	if dev.ReadOnly {
-		klog.Infof("ignoring read only device %q", dev.Name)
-		return true
+		return v1alpha1.RejectedReadOnly, "read only device"
 	}
 
 	if dev.State == diskutils.StateSuspended {
-		klog.Infof("ignoring device %q with invalid state %q", dev.Name, dev.State)
-		return true
+		return v1alpha1.RejectedSuspended, fmt.Sprintf("device with invalid state %q", dev.State)
 	}
 
-	if !supportedDeviceTypes.Has(dev.Type) {
-		klog.Infof("ignoring device %q with unsupported type %q", dev.Name, dev.Type)
-		return true
+	if !filter.supportsType(dev.Type) {
+		return v1alpha1.RejectedUnsupportedType, fmt.Sprintf("device with unsupported type %q", dev.Type)
 	}
 
 	if dev.Removable {
-		klog.Infof("ignoring device %s with removable capability", dev.Name)
-		return true
+		return v1alpha1.RejectedRemovable, "device with removable capability"
 	}
 
 	if dev.BiosPartition() {
-		klog.Infof("ignoring device %q with partion with bios/boot label", dev.Name)
-		return true
+		return v1alpha1.RejectedBootDevice, "device with partition with bios/boot label"
 	}
 
 	if dev.Mountpoint != "" {
-		klog.Infof("ignoring device %q with at least one mountpoint", dev.Mountpoint)
-		return true
+		return v1alpha1.RejectedMounted, fmt.Sprintf("device mounted on %q", dev.Mountpoint)
 	}
 
 	if dev.Size == 0 {
-		klog.Infof("ignoring device %q with 0 size", dev.Name)
-		return true
+		return v1alpha1.RejectedZeroSize, "device with 0 size"
 	}
 
-	if dev.WWN == "" {
-		klog.Infof("ignoring device %q without WWN", dev.Name)
-		return true
+	if dev.WWN == "" && filter.requiresWWN() {
+		return v1alpha1.RejectedNoWWN, "device without WWN"
 	}
 
 	if dev.FSType != "" && dev.FSType != "mpath_member" {
-		klog.Infof("ignoring device %q with FS", dev.FSType)
-		return true
+		return v1alpha1.RejectedHasFilesystem, fmt.Sprintf("device with filesystem %s", dev.FSType)
 	}
 	// Ignore childrens which has partiton/fs on them
 	if dev.Children != nil {
 		for idx := range dev.Children {
 			if dev.Children[idx].Type == "part" {
-				klog.Infof("ignoring device %q with partitions", dev.Children[idx].Name)
-				return true
+				return v1alpha1.RejectedHasPartitions, fmt.Sprintf("device with partition %q", dev.Children[idx].Name)
 			}
 			if dev.Children[idx].FSType != "" {
-				klog.Infof(
-					"ignoring device %q with filesystem %s",
-					dev.Children[idx].Name,
+				return v1alpha1.RejectedHasFilesystem, fmt.Sprintf(
+					"device with filesystem %s on %q",
 					dev.Children[idx].FSType,
+					dev.Children[idx].Name,
 				)
-				return true
 			}
 			if dev.Children[idx].Children != nil {
 				for idx2 := range dev.Children[idx].Children {
-					return ignoreDevices(&dev.Children[idx].Children[idx2])
+					return rejectDevice(&dev.Children[idx].Children[idx2], filter)
 				}
 			}
 		}
 	}
 
-	return false
+	return "", ""
 }
 
 func parseDeviceType(deviceType string) v1alpha1.DiscoveredDeviceType {
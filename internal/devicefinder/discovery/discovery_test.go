@@ -3,10 +3,14 @@ package discovery
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
+	"path/filepath"
+	"unsafe"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"golang.org/x/sys/unix"
 
 	"github.com/openshift-storage-scale/openshift-fusion-access-operator/api/v1alpha1"
 	"github.com/openshift-storage-scale/openshift-fusion-access-operator/internal/diskutils"
@@ -67,12 +71,12 @@ var _ = Describe("Device Discovery", func() {
 		It(
 			"should have the correct number of discovered disks with multipath (input data 1)",
 			func() {
-				discoveredDisks := getDiscoverdDevices(deviceList2Disk2MultiPath.BlockDevices)
+				discoveredDisks, _ := getDiscoverdDevices(deviceList2Disk2MultiPath.BlockDevices, noDeviceFilter())
 				Expect(discoveredDisks).To(HaveLen(4))
 			},
 		)
 		It("should have the correct disks with multipath (input data 1)", func() {
-			discoveredDisks := getDiscoverdDevices(deviceList2Disk2MultiPath.BlockDevices)
+			discoveredDisks, _ := getDiscoverdDevices(deviceList2Disk2MultiPath.BlockDevices, noDeviceFilter())
 
 			Expect(discoveredDisks).To(ContainElement(
 				v1alpha1.DiscoveredDevice{
@@ -83,6 +87,18 @@ var _ = Describe("Device Discovery", func() {
 					Vendor:   "LIO-ORG ",
 					Size:     75161927680,
 					WWN:      "0x6001405c595842b2d484d0bb11e42179",
+					Serial:   "c595842b-2d48-4d0b-b11e-42179674b55a",
+					Property: v1alpha1.Rotational,
+
+					Transport:          "iscsi",
+					LogicalSectorSize:  512,
+					PhysicalSectorSize: 512,
+					MultipathMembers: []v1alpha1.MultipathMember{
+						{Name: "sdf", Path: "/dev/sdf", State: "running", Transport: "iscsi"},
+						{Name: "sdh", Path: "/dev/sdh", State: "running", Transport: "iscsi"},
+					},
+					TotalPaths:  2,
+					ActivePaths: 2,
 				},
 			))
 			Expect(discoveredDisks).To(ContainElement(
@@ -94,6 +110,18 @@ var _ = Describe("Device Discovery", func() {
 					Vendor:   "LIO-ORG ",
 					Size:     85899345920,
 					WWN:      "0x60014056ade16393c8f412da451430e4",
+					Serial:   "6ade1639-3c8f-412d-a451-430e41087461",
+					Property: v1alpha1.Rotational,
+
+					Transport:          "iscsi",
+					LogicalSectorSize:  512,
+					PhysicalSectorSize: 512,
+					MultipathMembers: []v1alpha1.MultipathMember{
+						{Name: "sdg", Path: "/dev/sdg", State: "running", Transport: "iscsi"},
+						{Name: "sdi", Path: "/dev/sdi", State: "running", Transport: "iscsi"},
+					},
+					TotalPaths:  2,
+					ActivePaths: 2,
 				},
 			))
 			Expect(discoveredDisks).To(ContainElement(
@@ -105,6 +133,11 @@ var _ = Describe("Device Discovery", func() {
 					Vendor:   "QEMU    ",
 					Size:     10737418240,
 					WWN:      "0x5000c50015ff75aa",
+					Serial:   "thirddisk",
+					Property: v1alpha1.Rotational,
+
+					LogicalSectorSize:  512,
+					PhysicalSectorSize: 512,
 				},
 			))
 			Expect(discoveredDisks).To(ContainElement(
@@ -116,6 +149,11 @@ var _ = Describe("Device Discovery", func() {
 					Vendor:   "QEMU    ",
 					Size:     53687091200,
 					WWN:      "0x5000c50015ea75bb",
+					Serial:   "seconddisk",
+					Property: v1alpha1.Rotational,
+
+					LogicalSectorSize:  512,
+					PhysicalSectorSize: 512,
 				},
 			))
 
@@ -124,7 +162,7 @@ var _ = Describe("Device Discovery", func() {
 		It(
 			"should have the correct number of discovered disks with multipath (input data 2)",
 			func() {
-				discoveredDisks := getDiscoverdDevices(deviceList0Disk0MultiPath.BlockDevices)
+				discoveredDisks, _ := getDiscoverdDevices(deviceList0Disk0MultiPath.BlockDevices, noDeviceFilter())
 				Expect(discoveredDisks).To(BeEmpty())
 			},
 		)
@@ -132,13 +170,13 @@ var _ = Describe("Device Discovery", func() {
 		It(
 			"should have the correct number of discovered disks without multipath (input data 3)",
 			func() {
-				discoveredDisks := getDiscoverdDevices(deviceList7Disk.BlockDevices)
+				discoveredDisks, _ := getDiscoverdDevices(deviceList7Disk.BlockDevices, noDeviceFilter())
 				Expect(discoveredDisks).To(HaveLen(2))
 			},
 		)
 
 		It("should have the correct disks without multipath (input data 3)", func() {
-			discoveredDisks := getDiscoverdDevices(deviceList7Disk.BlockDevices)
+			discoveredDisks, _ := getDiscoverdDevices(deviceList7Disk.BlockDevices, noDeviceFilter())
 			Expect(discoveredDisks).To(ContainElement(
 				v1alpha1.DiscoveredDevice{
 					DeviceID: "",
@@ -148,6 +186,12 @@ var _ = Describe("Device Discovery", func() {
 					Vendor:   "NETAPP  ",
 					Size:     4294967296,
 					WWN:      "0x600a098038304437415d4b6a5968624d",
+					Serial:   "80D7A\\x5dKjYhbM",
+					Property: v1alpha1.NonRotational,
+
+					Transport:          "fc",
+					LogicalSectorSize:  512,
+					PhysicalSectorSize: 4096,
 				},
 			))
 			Expect(discoveredDisks).To(ContainElement(
@@ -159,17 +203,23 @@ var _ = Describe("Device Discovery", func() {
 					Vendor:   "NETAPP  ",
 					Size:     1288490188800,
 					WWN:      "0x600a098038304437415d4b6a5968624f",
+					Serial:   "80D7A\\x5dKjYhbO",
+					Property: v1alpha1.NonRotational,
+
+					Transport:          "fc",
+					LogicalSectorSize:  512,
+					PhysicalSectorSize: 4096,
 				},
 			))
 		})
 
 		It("should have the correct number of discovered disks (input data 4)", func() {
-			discoveredDisks := getDiscoverdDevices(deviceList0Disk.BlockDevices)
+			discoveredDisks, _ := getDiscoverdDevices(deviceList0Disk.BlockDevices, noDeviceFilter())
 			Expect(discoveredDisks).To(BeEmpty())
 		})
 
 		It("should have the correct number of discovered disks (san disk env)", func() {
-			discoveredDisks := getDiscoverdDevices(deviceListSanDisk.BlockDevices)
+			discoveredDisks, _ := getDiscoverdDevices(deviceListSanDisk.BlockDevices, noDeviceFilter())
 			Expect(discoveredDisks).To(BeEmpty())
 		})
 
@@ -180,10 +230,220 @@ var _ = Describe("Device Discovery", func() {
 			err = json.Unmarshal(LsblkOut0Disk0MultiPath0DM, &deviceList0Disk0MultiPath0DM)
 			Expect(err).To(Not(HaveOccurred()))
 
-			discoveredDisks := getDiscoverdDevices(deviceList0Disk0MultiPath0DM.BlockDevices)
+			discoveredDisks, _ := getDiscoverdDevices(deviceList0Disk0MultiPath0DM.BlockDevices, noDeviceFilter())
 			Expect(discoveredDisks).To(BeEmpty())
 
 		})
 
 	})
 })
+
+var _ = Describe("Rejected Devices", func() {
+	DescribeTable("reports why a device is not discovered",
+		func(dev diskutils.BlockDevice, reason v1alpha1.RejectionReason) {
+			dev.Name, dev.KName, dev.Path = "sdb", "sdb", "/dev/sdb"
+			discovered, rejected := getDiscoverdDevices([]diskutils.BlockDevice{dev}, noDeviceFilter())
+			Expect(discovered).To(BeEmpty())
+			Expect(rejected).To(HaveLen(1))
+			Expect(rejected[0].Path).To(Equal("/dev/sdb"))
+			Expect(rejected[0].Reason).To(Equal(reason))
+			Expect(rejected[0].Message).ToNot(BeEmpty())
+		},
+		Entry("read only", diskutils.BlockDevice{Type: "disk", Size: 1024, WWN: "0x1", ReadOnly: true}, v1alpha1.RejectedReadOnly),
+		Entry("removable", diskutils.BlockDevice{Type: "disk", Size: 1024, WWN: "0x1", Removable: true}, v1alpha1.RejectedRemovable),
+		Entry("filesystem", diskutils.BlockDevice{Type: "disk", Size: 1024, WWN: "0x1", FSType: "xfs"}, v1alpha1.RejectedHasFilesystem),
+		Entry("partitions", diskutils.BlockDevice{
+			Type: "disk", Size: 1024, WWN: "0x1",
+			Children: []diskutils.BlockDevice{{Name: "sdb1", KName: "sdb1", Type: "part"}},
+		}, v1alpha1.RejectedHasPartitions),
+		Entry("no WWN", diskutils.BlockDevice{Type: "disk", Size: 1024}, v1alpha1.RejectedNoWWN),
+		Entry("boot device", diskutils.BlockDevice{
+			Type: "disk", Size: 1024, WWN: "0x1",
+			Children: []diskutils.BlockDevice{{Name: "sdb1", KName: "sdb1", Type: "part", PartLabel: "BIOS-BOOT"}},
+		}, v1alpha1.RejectedBootDevice),
+		Entry("mounted", diskutils.BlockDevice{Type: "disk", Size: 1024, WWN: "0x1", Mountpoint: "/var"}, v1alpha1.RejectedMounted),
+		Entry("unsupported type", diskutils.BlockDevice{Type: "loop", Size: 1024}, v1alpha1.RejectedUnsupportedType),
+		Entry("suspended", diskutils.BlockDevice{Type: "mpath", Size: 1024, WWN: "0x1", State: diskutils.StateSuspended}, v1alpha1.RejectedSuspended),
+		Entry("zero size", diskutils.BlockDevice{Type: "disk", WWN: "0x1"}, v1alpha1.RejectedZeroSize),
+	)
+
+	It("reports the multipath device once for all its paths", func() {
+		member := func(name string) diskutils.BlockDevice {
+			return diskutils.BlockDevice{
+				Name: name, KName: name, Path: "/dev/" + name, Type: "disk", Size: 1024, FSType: "mpath_member",
+				Children: []diskutils.BlockDevice{{Name: "mpatha", KName: "dm-0", Type: "mpath", Size: 1024}},
+			}
+		}
+		_, rejected := getDiscoverdDevices([]diskutils.BlockDevice{member("sdb"), member("sdc")}, noDeviceFilter())
+		Expect(rejected).To(HaveLen(1))
+		Expect(rejected[0].Path).To(Equal("/dev/dm-0"))
+		Expect(rejected[0].Reason).To(Equal(v1alpha1.RejectedNoWWN))
+	})
+})
+
+var _ = Describe("Hardware Attributes", func() {
+	numaNode := func(node int32) *int32 { return &node }
+
+	It("reports the hardware attributes of a disk", func() {
+		discovered, _ := getDiscoverdDevices([]diskutils.BlockDevice{{
+			Name: "nvme0n1", KName: "nvme0n1", Path: "/dev/nvme0n1", Type: "disk", Size: 1024, WWN: "eui.0025388b91b2b5e1",
+			Serial: "S4EWNX0R123456", Transport: "nvme", LogicalSectorSize: 512, PhysicalSectorSize: 4096,
+			ByPathLinks: []string{"pci-0000:5e:00.0-nvme-1"}, NUMANode: numaNode(0),
+		}}, noDeviceFilter())
+		Expect(discovered).To(HaveLen(1))
+		Expect(discovered[0].Serial).To(Equal("S4EWNX0R123456"))
+		Expect(discovered[0].Property).To(Equal(v1alpha1.NonRotational))
+		Expect(discovered[0].Transport).To(Equal("nvme"))
+		Expect(discovered[0].LogicalSectorSize).To(Equal(int64(512)))
+		Expect(discovered[0].PhysicalSectorSize).To(Equal(int64(4096)))
+		Expect(discovered[0].ByPath).To(Equal("/dev/disk/by-path/pci-0000:5e:00.0-nvme-1"))
+		Expect(discovered[0].NUMANode).To(Equal(numaNode(0)))
+		Expect(discovered[0].MultipathMembers).To(BeEmpty())
+	})
+
+	It("reports the paths of a multipath device with their state", func() {
+		member := func(name, state, byPath string, node int32) diskutils.BlockDevice {
+			return diskutils.BlockDevice{
+				Name: name, KName: name, Path: "/dev/" + name, Type: "disk", Size: 1024, FSType: "mpath_member",
+				WWN: "0x600a098038304437415d4b6a5968624f", State: state, Rotational: true, Transport: "fc",
+				ByPathLinks: []string{byPath}, NUMANode: numaNode(node),
+				Children: []diskutils.BlockDevice{{Name: "mpatha", KName: "dm-0", Type: "mpath", Size: 1024}},
+			}
+		}
+		discovered, _ := getDiscoverdDevices([]diskutils.BlockDevice{
+			member("sdb", "running", "pci-0000:3b:00.0-fc-0x500a0981891b8dc5-lun-0", 0),
+			member("sdc", "offline", "pci-0000:d8:00.0-fc-0x500a0981991b8dc5-lun-0", 1),
+		}, noDeviceFilter())
+		Expect(discovered).To(HaveLen(1))
+		Expect(discovered[0].Path).To(Equal("/dev/dm-0"))
+		Expect(discovered[0].Property).To(Equal(v1alpha1.Rotational))
+		Expect(discovered[0].ByPath).To(BeEmpty())
+		Expect(discovered[0].MultipathMembers).To(Equal([]v1alpha1.MultipathMember{
+			{
+				Name: "sdb", Path: "/dev/sdb", ByPath: "/dev/disk/by-path/pci-0000:3b:00.0-fc-0x500a0981891b8dc5-lun-0",
+				State: "running", Transport: "fc", NUMANode: numaNode(0),
+			},
+			{
+				Name: "sdc", Path: "/dev/sdc", ByPath: "/dev/disk/by-path/pci-0000:d8:00.0-fc-0x500a0981991b8dc5-lun-0",
+				State: "offline", Transport: "fc", NUMANode: numaNode(1),
+			},
+		}))
+	})
+})
+
+var _ = Describe("Device Header", func() {
+	disk := diskutils.BlockDevice{Name: "sdb", KName: "sdb", Path: "/dev/sdb", Type: "disk", Size: 1024, WWN: "0x1"}
+
+	discover := func(header []byte, err error) v1alpha1.DiscoveredDevice {
+		original := diskutils.ReadDeviceHeader
+		DeferCleanup(func() { diskutils.ReadDeviceHeader = original })
+		diskutils.ReadDeviceHeader = func(path string) ([]byte, error) {
+			Expect(path).To(Equal("/dev/sdb"))
+			return header, err
+		}
+		discovered, _ := getDiscoverdDevices([]diskutils.BlockDevice{disk}, noDeviceFilter())
+		Expect(discovered).To(HaveLen(1))
+		newDeviceProbes().apply(discovered)
+		return discovered[0]
+	}
+
+	It("reports a device used by Storage Scale", func() {
+		header := make([]byte, diskutils.HeaderSize)
+		copy(header[512:], "NSD descriptor for /dev/sdb created by GPFS")
+		Expect(discover(header, nil).Header).To(Equal(v1alpha1.HeaderGPFSNSD))
+	})
+
+	It("reports a foreign signature", func() {
+		header := make([]byte, diskutils.HeaderSize)
+		copy(header, "XFSB")
+		device := discover(header, nil)
+		Expect(device.Header).To(Equal(v1alpha1.HeaderForeignSignature))
+		Expect(device.Signature).To(Equal("xfs"))
+	})
+
+	It("reports an unreadable device as unknown", func() {
+		Expect(discover(nil, fmt.Errorf("input/output error")).Header).To(Equal(v1alpha1.HeaderUnknown))
+	})
+})
+
+// fakeIoctl answers the PERSISTENT RESERVE IN commands with the given capabilities
+type fakeIoctl struct {
+	capabilities []byte
+}
+
+func (f fakeIoctl) Ioctl(_ uintptr, _ uintptr, arg unsafe.Pointer) error {
+	if f.capabilities == nil {
+		return unix.ENOTTY
+	}
+	// dxfer_len and dxferp of sg_io_hdr, after the 12 bytes of its first fields
+	dxferLen := *(*uint32)(unsafe.Add(arg, 12))
+	dxferp := *(*unsafe.Pointer)(unsafe.Add(arg, 16))
+	copy(unsafe.Slice((*byte)(dxferp), dxferLen), f.capabilities)
+	return nil
+}
+
+var _ = Describe("Persistent Reservation", func() {
+	discover := func(ioctl diskutils.IoctlExecutor) v1alpha1.DiscoveredDevice {
+		path := filepath.Join(GinkgoT().TempDir(), "sdb")
+		Expect(os.WriteFile(path, nil, 0o600)).To(Succeed())
+		original := diskutils.Ioctl
+		DeferCleanup(func() { diskutils.Ioctl = original })
+		diskutils.Ioctl = ioctl
+
+		disk := diskutils.BlockDevice{Name: "sdb", KName: "sdb", Path: path, Type: "disk", Size: 1024, WWN: "0x1"}
+		discovered, _ := getDiscoverdDevices([]diskutils.BlockDevice{disk}, noDeviceFilter())
+		Expect(discovered).To(HaveLen(1))
+		newDeviceProbes().apply(discovered)
+		return discovered[0]
+	}
+
+	It("reports the reservation types of a LUN", func() {
+		device := discover(fakeIoctl{capabilities: []byte{0x00, 0x08, 0x0d, 0x81, 0x22, 0x00, 0x00, 0x00}})
+		Expect(device.PersistentReservation).To(Equal(v1alpha1.PersistentReservationSupported))
+		Expect(device.PersistentReservationTypes).To(Equal([]string{"WriteExclusiveRegistrantsOnly", "WriteExclusive"}))
+	})
+
+	It("reports a device that cannot be probed as unknown", func() {
+		device := discover(fakeIoctl{})
+		Expect(device.PersistentReservation).To(Equal(v1alpha1.PersistentReservationUnknown))
+		Expect(device.PersistentReservationTypes).To(BeEmpty())
+	})
+})
+
+// fakeInitiatorReader returns fixed SAN initiators
+type fakeInitiatorReader struct {
+	initiators diskutils.SANInitiators
+}
+
+func (r fakeInitiatorReader) ReadInitiators() diskutils.SANInitiators {
+	return r.initiators
+}
+
+var _ = Describe("Initiators", func() {
+	read := func(initiators diskutils.SANInitiators) *v1alpha1.NodeInitiators {
+		original := diskutils.Initiators
+		DeferCleanup(func() { diskutils.Initiators = original })
+		diskutils.Initiators = fakeInitiatorReader{initiators: initiators}
+		return getInitiators()
+	}
+
+	It("reports the Fibre Channel ports and the iSCSI initiator of the node", func() {
+		Expect(read(diskutils.SANInitiators{
+			FCHosts: []diskutils.FCHost{{
+				Name: "host1", PortName: "10:00:00:90:fa:8c:1f:52", NodeName: "20:00:00:90:fa:8c:1f:52",
+				PortState: "Online", Speed: "16 Gbit", FabricName: "10:00:00:05:1e:90:e0:b4",
+			}},
+			ISCSIInitiatorName: "iqn.1994-05.com.redhat:worker-0",
+		})).To(Equal(&v1alpha1.NodeInitiators{
+			FCHosts: []v1alpha1.FCHost{{
+				Name: "host1", PortName: "10:00:00:90:fa:8c:1f:52", NodeName: "20:00:00:90:fa:8c:1f:52",
+				PortState: "Online", Speed: "16 Gbit", FabricName: "10:00:00:05:1e:90:e0:b4",
+			}},
+			ISCSIInitiatorName: "iqn.1994-05.com.redhat:worker-0",
+		}))
+	})
+
+	It("reports nothing for a node without SAN initiators", func() {
+		Expect(read(diskutils.SANInitiators{FCHosts: []diskutils.FCHost{}})).To(BeNil())
+	})
+})
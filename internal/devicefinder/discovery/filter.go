@@ -0,0 +1,148 @@
+package discovery
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/openshift-storage-scale/openshift-fusion-access-operator/api/v1alpha1"
+	"github.com/openshift-storage-scale/openshift-fusion-access-operator/internal/diskutils"
+)
+
+// defaultDeviceTypes are reported when the DeviceFilterPolicy does not list any device type
+var defaultDeviceTypes = []string{"mpath", "disk"}
+
+// deviceFilter is the compiled DeviceFilterPolicy of the LocalVolumeDiscovery, along with the
+// /dev/disk links of the node the globs are matched against
+type deviceFilter struct {
+	policy      v1alpha1.DeviceFilterPolicy
+	deviceTypes sets.Set[string]
+	vendor      *regexp.Regexp
+	model       *regexp.Regexp
+	byIDLinks   map[string][]string
+	byPathLinks map[string][]string
+}
+
+// newDeviceFilter validates and compiles the policy. A nil policy reports every disk and multipath
+// device with a WWN
+func newDeviceFilter(
+	policy *v1alpha1.DeviceFilterPolicy,
+	byIDLinks, byPathLinks map[string][]string,
+) (*deviceFilter, error) {
+	filter := &deviceFilter{
+		deviceTypes: sets.New(defaultDeviceTypes...),
+		byIDLinks:   byIDLinks,
+		byPathLinks: byPathLinks,
+	}
+	if policy == nil {
+		return filter, nil
+	}
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+	filter.policy = *policy
+	if len(policy.DeviceTypes) > 0 {
+		filter.deviceTypes = sets.New[string]()
+		for _, deviceType := range policy.DeviceTypes {
+			filter.deviceTypes.Insert(string(deviceType))
+		}
+	}
+	if policy.VendorRegex != "" {
+		filter.vendor = regexp.MustCompile(policy.VendorRegex)
+	}
+	if policy.ModelRegex != "" {
+		filter.model = regexp.MustCompile(policy.ModelRegex)
+	}
+	return filter, nil
+}
+
+// supportsType returns true when the device type is reported by the policy
+func (f *deviceFilter) supportsType(deviceType string) bool {
+	return f.deviceTypes.Has(deviceType)
+}
+
+// requiresWWN returns true unless the policy allows devices without a WWN
+func (f *deviceFilter) requiresWWN() bool {
+	return !f.policy.AllowNoWWN
+}
+
+// excludes checks the size, vendor, model, links and exclusions of the policy against a device. It
+// returns why the device is excluded, or an empty string if it is not
+func (f *deviceFilter) excludes(dev *diskutils.BlockDevice) string {
+	if f.policy.MinSize != nil && dev.Size < f.policy.MinSize.Value() {
+		return fmt.Sprintf("device smaller than %s", f.policy.MinSize)
+	}
+	if f.policy.MaxSize != nil && dev.Size > f.policy.MaxSize.Value() {
+		return fmt.Sprintf("device larger than %s", f.policy.MaxSize)
+	}
+	if f.vendor != nil && !f.vendor.MatchString(strings.TrimSpace(dev.Vendor)) {
+		return fmt.Sprintf("vendor %q does not match %q", dev.Vendor, f.policy.VendorRegex)
+	}
+	if f.model != nil && !f.model.MatchString(strings.TrimSpace(dev.Model)) {
+		return fmt.Sprintf("model %q does not match %q", dev.Model, f.policy.ModelRegex)
+	}
+
+	byIDLinks := f.links(dev, f.byIDLinks)
+	if dev.PathByID != "" {
+		byIDLinks = append(byIDLinks, path.Join(diskutils.DiskByIDDir, dev.PathByID))
+	}
+	byPathLinks := f.links(dev, f.byPathLinks)
+	if len(f.policy.ByPathGlobs) > 0 && !matchesAny(f.policy.ByPathGlobs, byPathLinks) {
+		return "no by-path link matches the byPathGlobs"
+	}
+	if len(f.policy.ByIDGlobs) > 0 && !matchesAny(f.policy.ByIDGlobs, byIDLinks) {
+		return "no by-id link matches the byIDGlobs"
+	}
+
+	var names []string
+	for _, kname := range deviceKNames(dev) {
+		names = append(names, "/dev/"+kname)
+	}
+	if dev.Path != "" {
+		names = append(names, dev.Path)
+	}
+	if dev.WWN != "" {
+		names = append(names, dev.WWN)
+	}
+	names = append(append(names, byIDLinks...), byPathLinks...)
+	if matchesAny(f.policy.ExcludeDevices, names) {
+		return "device listed in excludeDevices"
+	}
+	return ""
+}
+
+// links returns the links of the device and of its multipath device
+func (f *deviceFilter) links(dev *diskutils.BlockDevice, links map[string][]string) []string {
+	var result []string
+	for _, kname := range deviceKNames(dev) {
+		result = append(result, links[kname]...)
+	}
+	return result
+}
+
+// deviceKNames returns the kernel name of the device and, for multipath members, of the multipath device
+func deviceKNames(dev *diskutils.BlockDevice) []string {
+	knames := []string{dev.KName}
+	if dev.FSType == "mpath_member" {
+		for idx := range dev.Children {
+			knames = append(knames, dev.Children[idx].KName)
+		}
+	}
+	return knames
+}
+
+// matchesAny returns true when one of the names matches one of the globs
+func matchesAny(globs, names []string) bool {
+	for _, glob := range globs {
+		for _, name := range names {
+			// The globs are validated when the filter is created
+			if matched, _ := path.Match(glob, name); matched {
+				return true
+			}
+		}
+	}
+	return false
+}
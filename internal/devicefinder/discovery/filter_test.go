@@ -0,0 +1,138 @@
+package discovery
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/openshift-storage-scale/openshift-fusion-access-operator/api/v1alpha1"
+	"github.com/openshift-storage-scale/openshift-fusion-access-operator/internal/diskutils"
+)
+
+// noDeviceFilter returns the filter used when the LocalVolumeDiscovery has no device filter
+func noDeviceFilter() *deviceFilter {
+	filter, err := newDeviceFilter(nil, nil, nil)
+	Expect(err).ToNot(HaveOccurred())
+	return filter
+}
+
+var _ = Describe("Device Filter", func() {
+	var (
+		blockDevices []diskutils.BlockDevice
+		byIDLinks    map[string][]string
+		byPathLinks  map[string][]string
+	)
+
+	quantity := func(value string) *resource.Quantity {
+		q := resource.MustParse(value)
+		return &q
+	}
+
+	discoveredPaths := func(policy *v1alpha1.DeviceFilterPolicy) []string {
+		filter, err := newDeviceFilter(policy, byIDLinks, byPathLinks)
+		Expect(err).ToNot(HaveOccurred())
+		paths := []string{}
+		discovered, _ := getDiscoverdDevices(blockDevices, filter)
+		for _, device := range discovered {
+			paths = append(paths, device.Path)
+		}
+		return paths
+	}
+
+	BeforeEach(func() {
+		blockDevices = []diskutils.BlockDevice{
+			{
+				Name: "sda", KName: "sda", Path: "/dev/sda", Type: "disk", Size: 100 * 1024 * 1024 * 1024,
+				Vendor: "IBM     ", Model: "2145", WWN: "0x6005076810810261f800000000000a01", PathByID: "wwn-0x6005076810810261f800000000000a01",
+			},
+			{
+				Name: "sdb", KName: "sdb", Path: "/dev/sdb", Type: "disk", Size: 2 * 1024 * 1024 * 1024 * 1024,
+				Vendor: "NETAPP", Model: "LUN C-Mode", WWN: "0x600a098038314d6f4a5d4f6b2f6c7a01", PathByID: "wwn-0x600a098038314d6f4a5d4f6b2f6c7a01",
+			},
+			{
+				Name: "sdc", KName: "sdc", Path: "/dev/sdc", Type: "disk", Size: 10 * 1024 * 1024 * 1024,
+				Vendor: "QEMU", Model: "QEMU HARDDISK",
+			},
+		}
+		byIDLinks = map[string][]string{
+			"sda": {"/dev/disk/by-id/scsi-36005076810810261f800000000000a01"},
+			"sdb": {"/dev/disk/by-id/scsi-3600a098038314d6f4a5d4f6b2f6c7a01"},
+			"sdc": {"/dev/disk/by-id/scsi-0QEMU_QEMU_HARDDISK_drive-scsi2"},
+		}
+		byPathLinks = map[string][]string{
+			"sda": {"/dev/disk/by-path/pci-0000:3b:00.0-fc-0x500507680b215ac1-lun-1"},
+			"sdb": {"/dev/disk/by-path/pci-0000:3b:00.1-fc-0x500a0981891b8dc5-lun-0"},
+			"sdc": {"/dev/disk/by-path/pci-0000:00:10.0-scsi-0:0:2:0"},
+		}
+	})
+
+	It("reports the disks with a WWN without a policy", func() {
+		Expect(discoveredPaths(nil)).To(Equal([]string{"/dev/sda", "/dev/sdb"}))
+	})
+
+	DescribeTable("applies the policy",
+		func(policy *v1alpha1.DeviceFilterPolicy, expected []string) {
+			Expect(discoveredPaths(policy)).To(Equal(expected))
+		},
+		Entry("minimum size", &v1alpha1.DeviceFilterPolicy{MinSize: quantity("1Ti")}, []string{"/dev/sdb"}),
+		Entry("maximum size", &v1alpha1.DeviceFilterPolicy{MaxSize: quantity("1Ti")}, []string{"/dev/sda"}),
+		Entry("vendor regex ignoring the padding", &v1alpha1.DeviceFilterPolicy{VendorRegex: "^IBM$"}, []string{"/dev/sda"}),
+		Entry("model regex", &v1alpha1.DeviceFilterPolicy{ModelRegex: "C-Mode"}, []string{"/dev/sdb"}),
+		Entry("devices without WWN", &v1alpha1.DeviceFilterPolicy{AllowNoWWN: true}, []string{"/dev/sda", "/dev/sdb", "/dev/sdc"}),
+		Entry("device types", &v1alpha1.DeviceFilterPolicy{DeviceTypes: []v1alpha1.DiscoveredDeviceType{v1alpha1.MultiPathType}}, []string{}),
+		Entry("by-path globs",
+			&v1alpha1.DeviceFilterPolicy{AllowNoWWN: true, ByPathGlobs: []string{"/dev/disk/by-path/pci-0000:3b:00.*-fc-*"}},
+			[]string{"/dev/sda", "/dev/sdb"}),
+		Entry("by-id globs from the links directory",
+			&v1alpha1.DeviceFilterPolicy{ByIDGlobs: []string{"/dev/disk/by-id/scsi-3600a0980*"}}, []string{"/dev/sdb"}),
+		Entry("by-id globs from lsblk",
+			&v1alpha1.DeviceFilterPolicy{ByIDGlobs: []string{"/dev/disk/by-id/wwn-0x60050768*"}}, []string{"/dev/sda"}),
+		Entry("excluded WWN", &v1alpha1.DeviceFilterPolicy{ExcludeDevices: []string{"0x6005076810810261f800000000000a01"}}, []string{"/dev/sdb"}),
+		Entry("excluded device path", &v1alpha1.DeviceFilterPolicy{ExcludeDevices: []string{"/dev/sdb"}}, []string{"/dev/sda"}),
+		Entry("excluded link glob",
+			&v1alpha1.DeviceFilterPolicy{ExcludeDevices: []string{"/dev/disk/by-path/*-fc-0x500a0981891b8dc5-*"}}, []string{"/dev/sda"}),
+	)
+
+	It("matches the links of the multipath device of a multipath member", func() {
+		blockDevices = []diskutils.BlockDevice{{
+			Name: "sdd", KName: "sdd", Path: "/dev/sdd", Type: "disk", Size: 1024, FSType: "mpath_member",
+			WWN:      "0x600a098038314d6f4a5d4f6b2f6c7a02",
+			Children: []diskutils.BlockDevice{{Name: "mpatha", KName: "dm-0", Type: "mpath", Size: 1024}},
+		}}
+		byIDLinks = map[string][]string{"dm-0": {"/dev/disk/by-id/dm-name-mpatha"}}
+		Expect(discoveredPaths(&v1alpha1.DeviceFilterPolicy{ByIDGlobs: []string{"/dev/disk/by-id/dm-name-*"}})).
+			To(Equal([]string{"/dev/dm-0"}))
+		Expect(discoveredPaths(&v1alpha1.DeviceFilterPolicy{ExcludeDevices: []string{"/dev/dm-0"}})).To(BeEmpty())
+	})
+
+	It("reports why the devices were excluded", func() {
+		filter, err := newDeviceFilter(&v1alpha1.DeviceFilterPolicy{VendorRegex: "^NETAPP$"}, byIDLinks, byPathLinks)
+		Expect(err).ToNot(HaveOccurred())
+		_, rejected := getDiscoverdDevices(blockDevices, filter)
+		Expect(rejected).To(Equal([]v1alpha1.RejectedDevice{
+			{
+				Path: "/dev/sda", DeviceID: "/dev/disk/by-id/wwn-0x6005076810810261f800000000000a01",
+				WWN: "0x6005076810810261f800000000000a01", Model: "2145", Vendor: "IBM     ", Size: 100 * 1024 * 1024 * 1024,
+				Reason: v1alpha1.RejectedByDeviceFilter, Message: `vendor "IBM     " does not match "^NETAPP$"`,
+			},
+			{
+				Path: "/dev/sdc", Model: "QEMU HARDDISK", Vendor: "QEMU", Size: 10 * 1024 * 1024 * 1024,
+				Reason: v1alpha1.RejectedNoWWN, Message: "device without WWN",
+			},
+		}))
+	})
+
+	It("keeps every device without WWN", func() {
+		blockDevices = append(blockDevices, diskutils.BlockDevice{
+			Name: "sdd", KName: "sdd", Path: "/dev/sdd", Type: "disk", Size: 10 * 1024 * 1024 * 1024,
+			Vendor: "QEMU", Model: "QEMU HARDDISK",
+		})
+		Expect(discoveredPaths(&v1alpha1.DeviceFilterPolicy{AllowNoWWN: true})).
+			To(Equal([]string{"/dev/sda", "/dev/sdb", "/dev/sdc", "/dev/sdd"}))
+	})
+
+	It("refuses an invalid policy", func() {
+		_, err := newDeviceFilter(&v1alpha1.DeviceFilterPolicy{ModelRegex: "("}, nil, nil)
+		Expect(err).To(MatchError(ContainSubstring("invalid deviceFilter modelRegex")))
+	})
+})
@@ -0,0 +1,37 @@
+package discovery
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+// healthProbeAddress is where the liveness probe of the discovery daemonset checks the uevent monitor
+const healthProbeAddress = ":8081"
+
+// healthHandler reports the health of the uevent monitor on /healthz
+func healthHandler(health *monitorHealth) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, _ *http.Request) {
+		if err := health.Check(); err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprintln(w, health)
+	})
+	return mux
+}
+
+// serveHealth serves the health of the uevent monitor until the process exits
+func serveHealth(addr string, health *monitorHealth) {
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           healthHandler(health),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	if err := server.ListenAndServe(); err != nil {
+		klog.Errorf("health endpoint stopped. %v", err)
+	}
+}
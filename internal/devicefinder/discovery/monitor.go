@@ -1,118 +1,148 @@
 package discovery
 
 import (
-	"bufio"
-	"fmt"
-	"os/exec"
-	"regexp"
+	"slices"
+	"strings"
 	"time"
 
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/klog/v2"
 )
 
 var (
-	udevExclusionFilter = []string{"(?i)dm-[0-9]+", "(?i)rbd[0-9]", "(?i)nbd[0-9]+"}
-	udevEventMatch      = []string{"(?i)add", "(?i)remove"}
+	// ueventActions are the actions of the block uevents that can change the discovered devices
+	ueventActions = []string{"add", "remove", "change"}
+	// ueventExcludedDevices are the prefixes of the device names whose uevents are ignored
+	ueventExcludedDevices = []string{"dm-", "rbd", "nbd", "loop"}
 )
 
+// allDevices is sent instead of a device name when uevents may have been missed
+const allDevices = "*"
+
 // Monitors udev for block device changes, and collapses these events such that
-// only one event is emitted per period in order to deal with flapping.
-func udevBlockMonitor(c chan string, period time.Duration) {
+// only one event is emitted per period in order to deal with flapping. The event
+// holds the names of the devices of the collapsed uevents.
+func udevBlockMonitor(c chan sets.Set[string], period time.Duration, health *monitorHealth) {
 	defer close(c)
 
-	// return any add or remove events, but none that match device mapper
-	// events. string matching is case-insensitive
+	// return any add, remove or change events, but none of device mapper, rbd, nbd or loop devices
 	events := make(chan string)
 
-	klog.Infof("regex for matching udev events - %q", udevEventMatch)
-	klog.Infof("regex for list of devices to be ignored for udev events - %q", udevExclusionFilter)
+	klog.Infof("uevent actions watched - %q", ueventActions)
+	klog.Infof("device prefixes ignored for uevents - %q", ueventExcludedDevices)
 
-	go rawUdevBlockMonitor(events, udevEventMatch, udevExclusionFilter)
+	go rawUdevBlockMonitor(events, health, nil)
 
 	for {
-		event, ok := <-events
+		device, ok := <-events
 		if !ok {
 			return
 		}
+		devices := sets.New(device)
 		timeout := time.NewTimer(period)
 		for {
 			select {
 			case <-timeout.C:
-			case _, ok := <-events:
+			case device, ok := <-events:
 				if !ok {
 					return
 				}
+				devices.Insert(device)
 				continue
 			}
 			break
 		}
-		c <- event
+		c <- devices
 	}
 }
 
-// Scans `udevadm monitor` output for block sub-system events. Each line of
-// output matching a set of substrings is sent to the provided channel. An event
-// is returned if it passes any matches tests, and passes all exclusion tests.
-func rawUdevBlockMonitor(c chan string, matches, exclusions []string) {
+// Reads the block sub-system uevents from a NETLINK_KOBJECT_UEVENT socket. The device
+// name of each event accepted by matchUevent is sent to the provided channel. The socket
+// is reopened with an exponential backoff when it fails, and allDevices is sent once it
+// is reopened since uevents may have been missed in the meantime.
+func rawUdevBlockMonitor(c chan string, health *monitorHealth, stop <-chan struct{}) {
 	defer close(c)
 
-	cmd := exec.Command("udevadm", "monitor", "-u", "-k", "-s", "block")
-	stdout, err := cmd.StdoutPipe()
-	if err != nil {
-		klog.Warningf("Cannot open udevadm stdout: %v", err)
-		return
-	}
+	backoff := ueventMinBackoff
+	reconnecting := false
+	for {
+		socket, err := dialUevents()
+		if err == nil {
+			health.setConnected()
+			klog.Info("uevent monitor connected")
+			backoff = ueventMinBackoff
+			if reconnecting && !send(c, allDevices, stop) {
+				_ = socket.Close()
+				return
+			}
+			err = readUevents(socket, c, health, stop)
+			_ = socket.Close()
+		}
+		if err == nil {
+			return
+		}
+		klog.Warningf("uevent monitor failed, reconnecting in %s: %v", backoff, err)
+		health.setDisconnected(err)
+		reconnecting = true
 
-	err = cmd.Start()
-	if err != nil {
-		klog.Warningf("Cannot start udevadm monitoring: %v", err)
-		return
+		select {
+		case <-stop:
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, ueventMaxBackoff)
 	}
+}
 
-	scanner := bufio.NewScanner(stdout)
-	for scanner.Scan() {
-		text := scanner.Text()
-		klog.Infof("udevadm monitor: %s", text)
-		match, err := matchUdevEvent(text, matches, exclusions)
+// readUevents sends the device names of the matching block uevents received on the socket to the channel
+// until the socket fails. It returns nil when stopped
+func readUevents(socket ueventSocket, c chan string, health *monitorHealth, stop <-chan struct{}) error {
+	for {
+		msg, err := socket.Receive()
 		if err != nil {
-			klog.Warningf("udevadm filtering failed: %v", err)
-			return
+			return err
 		}
-		if match {
-			c <- text
+		event, err := parseUevent(msg)
+		if err != nil {
+			klog.Warningf("ignoring uevent: %v", err)
+			continue
+		}
+		if event.Subsystem != "block" {
+			continue
+		}
+		health.setEvent()
+		klog.V(2).Infof("uevent monitor: %s %s DEVNAME=%s DEVTYPE=%s",
+			event.Action, event.DevPath, event.DevName, event.DevType)
+		if !matchUevent(event) {
+			continue
+		}
+		klog.Infof("uevent monitor: matched event: %s %s", event.Action, event.DevName)
+		if !send(c, event.DevName, stop) {
+			return nil
 		}
 	}
+}
 
-	if err := scanner.Err(); err != nil {
-		klog.Warningf("udevadm monitor scanner error: %v", err)
+// send sends the event unless stop is closed first
+func send(c chan string, event string, stop <-chan struct{}) bool {
+	select {
+	case c <- event:
+		return true
+	case <-stop:
+		return false
 	}
-
-	klog.Info("udevadm monitor finished")
 }
 
-func matchUdevEvent(text string, matches, exclusions []string) (bool, error) {
-	for _, match := range matches {
-		matched, err := regexp.MatchString(match, text)
-		if err != nil {
-			return false, fmt.Errorf("failed to search string: %v", err)
-		}
-		if matched {
-			hasExclusion := false
-			for _, exclusion := range exclusions {
-				matched, err = regexp.MatchString(exclusion, text)
-				if err != nil {
-					return false, fmt.Errorf("failed to search string: %v", err)
-				}
-				if matched {
-					hasExclusion = true
-					break
-				}
-			}
-			if !hasExclusion {
-				klog.Infof("udevadm monitor: matched event: %s", text)
-				return true, nil
-			}
+// matchUevent returns true for the add, remove and change uevents of a block device that is not
+// excluded. DEVNAME is relative to /dev
+func matchUevent(event *uevent) bool {
+	if event.Subsystem != "block" || !slices.Contains(ueventActions, event.Action) {
+		return false
+	}
+	for _, prefix := range ueventExcludedDevices {
+		if strings.HasPrefix(event.DevName, prefix) {
+			return false
 		}
 	}
-	return false, nil
+	return true
 }
@@ -1,64 +1,171 @@
 package discovery
 
 import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 )
 
+// fakeUeventSocket returns its messages, then fails
+type fakeUeventSocket struct {
+	messages [][]byte
+}
+
+func (f *fakeUeventSocket) Receive() ([]byte, error) {
+	if len(f.messages) == 0 {
+		return nil, errors.New("socket closed")
+	}
+	msg := f.messages[0]
+	f.messages = f.messages[1:]
+	return msg, nil
+}
+
+func (f *fakeUeventSocket) Close() error {
+	return nil
+}
+
+func kernelUevent(properties ...string) []byte {
+	return []byte(strings.Join(properties, "\x00") + "\x00")
+}
+
 var _ = Describe("UdevEvent", func() {
-	Context("matchUdevEvent", func() {
+	Context("matchUevent", func() {
 		DescribeTable("should match udev events correctly",
-			func(text string, matches, exclusion []string, expected bool) {
-				actual, err := matchUdevEvent(text, matches, exclusion)
-				Expect(err).ToNot(HaveOccurred())
-				Expect(actual).To(Equal(expected))
+			func(event uevent, expected bool) {
+				Expect(matchUevent(&event)).To(Equal(expected))
 			},
 			Entry("match add udev event",
-				"KERNEL[1008.734088] add      /devices/pci0000:00/0000:00:07.0/virtio5/block/vdc (block)",
-				[]string{"(?i)add", "(?i)remove"},
-				[]string{"(?i)dm-[0-9]+"},
-				true,
-			),
+				uevent{Action: "add", Subsystem: "block", DevName: "vdc"}, true),
 			Entry("match remove udev event",
-				"KERNEL[1008.734088] remove     /devices/pci0000:00/0000:00:07.0/virtio5/block/vdc (block)",
-				[]string{"(?i)add", "(?i)remove"},
-				[]string{"(?i)dm-[0-9]+"},
-				true,
-			),
-			Entry("validate exclusion of change udev event",
-				"KERNEL[1008.734088] change      /devices/pci0000:00/0000:00:07.0/virtio5/block/vdc (block)",
-				[]string{"(?i)add", "(?i)remove"},
-				[]string{"(?i)dm-[0-9]+"},
-				false,
-			),
+				uevent{Action: "remove", Subsystem: "block", DevName: "vdc"}, true),
+			Entry("match change udev event",
+				uevent{Action: "change", Subsystem: "block", DevName: "sdb"}, true),
+			Entry("validate exclusion of bind udev event",
+				uevent{Action: "bind", Subsystem: "block", DevName: "vdc"}, false),
+			Entry("validate exclusion of event outside of the block subsystem",
+				uevent{Action: "add", Subsystem: "net", DevName: "veth0"}, false),
 			Entry("validate exclusion of event on dm device",
-				"KERNEL[1042.464238] add      /devices/virtual/block/dm-1 (block)",
-				[]string{"(?i)add", "(?i)remove"},
-				[]string{"(?i)dm-[0-9]+"},
-				false,
-			),
+				uevent{Action: "add", Subsystem: "block", DevName: "dm-1"}, false),
+			Entry("validate exclusion of event on rbd device",
+				uevent{Action: "add", Subsystem: "block", DevName: "rbd0"}, false),
+			Entry("validate exclusion of event on nbd device",
+				uevent{Action: "add", Subsystem: "block", DevName: "nbd12"}, false),
+			Entry("validate exclusion of event on loop device",
+				uevent{Action: "change", Subsystem: "block", DevName: "loop3"}, false),
 		)
+	})
+})
 
-		Context("with invalid regex patterns", func() {
-			It("should return error for invalid match regex pattern", func() {
-				text := "KERNEL[1008.734088] add      /devices/pci0000:00/0000:00:07.0/virtio5/block/vdc (block)"
-				matches := []string{"[invalid"}
-				exclusions := []string{"(?i)dm-[0-9]+"}
+var _ = Describe("Uevent", func() {
+	Context("parseUevent", func() {
+		It("parses a kernel uevent", func() {
+			event, err := parseUevent(kernelUevent(
+				"add@/devices/pci0000:00/0000:00:07.0/virtio5/block/vdc",
+				"ACTION=add", "DEVPATH=/devices/pci0000:00/0000:00:07.0/virtio5/block/vdc",
+				"SUBSYSTEM=block", "DEVNAME=vdc", "DEVTYPE=disk", "SEQNUM=4242",
+			))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(*event).To(Equal(uevent{
+				Action: "add", DevPath: "/devices/pci0000:00/0000:00:07.0/virtio5/block/vdc",
+				Subsystem: "block", DevName: "vdc", DevType: "disk",
+			}))
+		})
 
-				_, err := matchUdevEvent(text, matches, exclusions)
+		DescribeTable("refuses invalid messages",
+			func(msg []byte) {
+				_, err := parseUevent(msg)
 				Expect(err).To(HaveOccurred())
-				Expect(err.Error()).To(ContainSubstring("failed to search string"))
-			})
+			},
+			Entry("kernel uevent without header", kernelUevent("ACTION=add")),
+			Entry("uevent without action", kernelUevent("add@/devices/block/sdb", "SUBSYSTEM=block")),
+			Entry("udev uevent", []byte("libudev\x00\xfe\xed\xca\xfeACTION=add\x00")),
+		)
+	})
 
-			It("should return error for invalid exclusion regex pattern", func() {
-				text := "KERNEL[1008.734088] add      /devices/pci0000:00/0000:00:07.0/virtio5/block/vdc (block)"
-				matches := []string{"(?i)add"}
-				exclusions := []string{"[invalid"}
+	Context("rawUdevBlockMonitor", func() {
+		var (
+			originalDial       func() (ueventSocket, error)
+			originalMinBackoff time.Duration
+		)
 
-				_, err := matchUdevEvent(text, matches, exclusions)
-				Expect(err).To(HaveOccurred())
-				Expect(err.Error()).To(ContainSubstring("failed to search string"))
-			})
+		BeforeEach(func() {
+			originalDial, originalMinBackoff = dialUevents, ueventMinBackoff
+			ueventMinBackoff = time.Millisecond
+		})
+
+		AfterEach(func() {
+			dialUevents, ueventMinBackoff = originalDial, originalMinBackoff
+		})
+
+		It("sends the matching block events and reconnects when the socket fails", func() {
+			var mux sync.Mutex
+			dials := 0
+			dialUevents = func() (ueventSocket, error) {
+				mux.Lock()
+				defer mux.Unlock()
+				dials++
+				switch dials {
+				case 1:
+					return &fakeUeventSocket{messages: [][]byte{
+						kernelUevent("add@/devices/virtual/net/veth0", "ACTION=add", "SUBSYSTEM=net"),
+						kernelUevent("add@/devices/virtual/block/dm-1", "ACTION=add", "SUBSYSTEM=block", "DEVNAME=dm-1"),
+						kernelUevent("add@/devices/platform/host1/block/sdb", "ACTION=add",
+							"DEVPATH=/devices/platform/host1/block/sdb", "SUBSYSTEM=block", "DEVNAME=sdb", "DEVTYPE=disk"),
+					}}, nil
+				case 2:
+					return nil, errors.New("no netlink")
+				default:
+					return &fakeUeventSocket{}, nil
+				}
+			}
+
+			events := make(chan string)
+			stop := make(chan struct{})
+			health := newMonitorHealth()
+			go rawUdevBlockMonitor(events, health, stop)
+
+			Eventually(events).Should(Receive(Equal("sdb")))
+			Eventually(events).Should(Receive(Equal(allDevices)))
+			close(stop)
+			Eventually(events).Should(BeClosed())
+			health.mux.Lock()
+			defer health.mux.Unlock()
+			Expect(health.reconnects).To(BeNumerically(">=", 2))
+		})
+	})
+
+	Context("health", func() {
+		get := func(health *monitorHealth) *httptest.ResponseRecorder {
+			recorder := httptest.NewRecorder()
+			healthHandler(health).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+			return recorder
+		}
+
+		It("is healthy while connected", func() {
+			health := newMonitorHealth()
+			health.setConnected()
+			health.setEvent()
+			recorder := get(health)
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Body.String()).To(ContainSubstring("uevent monitor connected"))
+		})
+
+		It("is unhealthy when disconnected for too long", func() {
+			health := newMonitorHealth()
+			health.setConnected()
+			health.setDisconnected(errors.New("no netlink"))
+			Expect(get(health).Code).To(Equal(http.StatusOK))
+
+			health.unhealthyAfter = 0
+			recorder := get(health)
+			Expect(recorder.Code).To(Equal(http.StatusServiceUnavailable))
+			Expect(recorder.Body.String()).To(ContainSubstring("no netlink"))
 		})
 	})
 })
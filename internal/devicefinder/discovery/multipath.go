@@ -0,0 +1,222 @@
+package discovery
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift-storage-scale/openshift-fusion-access-operator/api/v1alpha1"
+	"github.com/openshift-storage-scale/openshift-fusion-access-operator/internal/common"
+	"github.com/openshift-storage-scale/openshift-fusion-access-operator/internal/devicefinder"
+)
+
+// activePathState is the state of the SCSI device of a path that can serve I/O
+const activePathState = "running"
+
+// degradedLUN is a LUN used by Storage Scale with fewer active paths than expected
+type degradedLUN struct {
+	wwn      string
+	path     string
+	active   int32
+	expected int32
+}
+
+func (l degradedLUN) String() string {
+	if l.path == "" {
+		return fmt.Sprintf("LUN %s has no path left, %d expected", l.wwn, l.expected)
+	}
+	return fmt.Sprintf("LUN %s (%s) has %d of %d paths active", l.wwn, l.path, l.active, l.expected)
+}
+
+// checkMultipathPaths sets the expected number of paths of the devices, and reports the LUNs used by
+// Storage Scale that lost paths. It returns the MultipathDegraded condition of the node
+func (discovery *DeviceDiscovery) checkMultipathPaths(devices []v1alpha1.DiscoveredDevice) metav1.Condition {
+	discovery.resetExpectedPathsOnRequest()
+	discovery.setExpectedPaths(devices)
+	usedLUNs, err := discovery.getUsedLUNs(devices)
+	if err != nil {
+		// Keep the last known condition rather than reporting the LUNs as healthy
+		klog.Warningf("failed to find the LUNs used by Storage Scale. Error %v", err)
+		return discovery.multipathCondition
+	}
+	degraded := discovery.findDegradedLUNs(devices, usedLUNs)
+	discovery.reportDegradedLUNs(degraded)
+	return multipathCondition(degraded)
+}
+
+// setPathCounts sets the total and active number of paths of the multipath devices
+func setPathCounts(devices []v1alpha1.DiscoveredDevice) {
+	for idx := range devices {
+		members := devices[idx].MultipathMembers
+		if len(members) == 0 {
+			continue
+		}
+		devices[idx].TotalPaths = int32(len(members))
+		devices[idx].ActivePaths = 0
+		for _, member := range members {
+			// lsblk and sysfs may not know the state of the path, it is then assumed to be active
+			if member.State == "" || member.State == activePathState {
+				devices[idx].ActivePaths++
+			}
+		}
+	}
+}
+
+// setExpectedPaths records the highest number of paths seen for each multipath LUN, and sets it as the
+// expected number of paths of the devices. A LUN that was multipathed and is now seen as a single disk
+// keeps its expectation
+func (discovery *DeviceDiscovery) setExpectedPaths(devices []v1alpha1.DiscoveredDevice) {
+	if discovery.expectedPaths == nil {
+		discovery.expectedPaths = map[string]int32{}
+	}
+	for idx := range devices {
+		wwn := devices[idx].WWN
+		if devices[idx].TotalPaths > discovery.expectedPaths[wwn] {
+			discovery.expectedPaths[wwn] = devices[idx].TotalPaths
+		}
+		devices[idx].ExpectedPaths = discovery.expectedPaths[wwn]
+	}
+}
+
+// loadExpectedPaths restores the expected number of paths from the LocalVolumeDiscoveryResult, so that
+// paths lost while the daemon was restarting are still noticed
+func (discovery *DeviceDiscovery) loadExpectedPaths(result *v1alpha1.LocalVolumeDiscoveryResult) {
+	discovery.expectedPaths = map[string]int32{}
+	for _, device := range result.Status.DiscoveredDevices {
+		if device.ExpectedPaths > 0 {
+			discovery.expectedPaths[device.WWN] = device.ExpectedPaths
+		}
+	}
+}
+
+// resetExpectedPathsOnRequest forgets the expected number of paths of every LUN when the
+// LocalVolumeDiscoveryResult of the node has the reset annotation, which is then removed
+func (discovery *DeviceDiscovery) resetExpectedPathsOnRequest() {
+	name := truncateNodeName(resultCRName, os.Getenv("MY_NODE_NAME"))
+	result, err := discovery.apiClient.GetDiscoveryResult(name, os.Getenv("WATCH_NAMESPACE"))
+	if err != nil {
+		klog.Warningf("failed to check the reset of the expected number of paths. Error %v", err)
+		return
+	}
+	if _, found := result.Annotations[v1alpha1.ResetExpectedPathsAnnotation]; !found {
+		return
+	}
+	delete(result.Annotations, v1alpha1.ResetExpectedPathsAnnotation)
+	if err := discovery.apiClient.UpdateDiscoveryResult(result); err != nil {
+		klog.Warningf("failed to remove the %s annotation. Error %v", v1alpha1.ResetExpectedPathsAnnotation, err)
+		return
+	}
+	klog.Info("resetting the expected number of paths of the multipath LUNs to their current number of paths")
+	discovery.expectedPaths = map[string]int32{}
+}
+
+// findDegradedLUNs returns the LUNs used by Storage Scale that have fewer active paths than expected,
+// including the ones whose paths are all gone. A LUN whose paths are all gone and that is not used by
+// Storage Scale has been unmapped from the node, its expected number of paths is forgotten
+func (discovery *DeviceDiscovery) findDegradedLUNs(devices []v1alpha1.DiscoveredDevice, usedLUNs sets.Set[string]) []degradedLUN {
+	var degraded []degradedLUN
+	seen := sets.New[string]()
+	for idx := range devices {
+		device := &devices[idx]
+		seen.Insert(device.WWN)
+		if !usedLUNs.Has(device.WWN) || device.ExpectedPaths == 0 {
+			continue
+		}
+		// A disk that is not multipathed is a single path
+		active := device.ActivePaths
+		if device.TotalPaths == 0 {
+			active = 1
+		}
+		if active < device.ExpectedPaths {
+			degraded = append(degraded, degradedLUN{wwn: device.WWN, path: device.Path, active: active, expected: device.ExpectedPaths})
+		}
+	}
+	for wwn, expected := range discovery.expectedPaths {
+		if seen.Has(wwn) {
+			continue
+		}
+		if !usedLUNs.Has(wwn) {
+			delete(discovery.expectedPaths, wwn)
+			continue
+		}
+		degraded = append(degraded, degradedLUN{wwn: wwn, expected: expected})
+	}
+	slices.SortFunc(degraded, func(a, b degradedLUN) int { return strings.Compare(a.wwn, b.wwn) })
+	return degraded
+}
+
+// getUsedLUNs returns the WWNs of the LUNs with a LocalDisk, including the ones this node no longer sees
+func (discovery *DeviceDiscovery) getUsedLUNs(devices []v1alpha1.DiscoveredDevice) (sets.Set[string], error) {
+	localDisks, err := discovery.apiClient.ListLocalDisks(common.StorageScaleNamespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list LocalDisks: %w", err)
+	}
+	nodeName := os.Getenv("MY_NODE_NAME")
+	namesByWWN := map[string][]string{}
+	for wwn := range discovery.expectedPaths {
+		namesByWWN[wwn] = nil
+	}
+	for idx := range devices {
+		namesByWWN[devices[idx].WWN] = append(namesByWWN[devices[idx].WWN], devices[idx].Names()...)
+	}
+
+	used := sets.New[string]()
+	for wwn, names := range namesByWWN {
+		namesByNode := map[string][]string{nodeName: names}
+		for idx := range localDisks {
+			if common.LocalDiskUsesLUN(&localDisks[idx], wwn, namesByNode) {
+				used.Insert(wwn)
+				break
+			}
+		}
+	}
+	return used, nil
+}
+
+// multipathCondition returns the MultipathDegraded condition for the degraded LUNs
+func multipathCondition(degraded []degradedLUN) metav1.Condition {
+	if len(degraded) == 0 {
+		return metav1.Condition{
+			Type:    v1alpha1.MultipathDegradedCondition,
+			Status:  metav1.ConditionFalse,
+			Reason:  v1alpha1.MultipathAllPathsActiveReason,
+			Message: "All the LUNs used by Storage Scale have their expected number of paths",
+		}
+	}
+	messages := make([]string, 0, len(degraded))
+	for _, lun := range degraded {
+		messages = append(messages, lun.String())
+	}
+	return metav1.Condition{
+		Type:    v1alpha1.MultipathDegradedCondition,
+		Status:  metav1.ConditionTrue,
+		Reason:  v1alpha1.MultipathPathsLostReason,
+		Message: strings.Join(messages, "; "),
+	}
+}
+
+// reportDegradedLUNs reports a warning event for each newly degraded LUN, and a normal one once its
+// paths are back. A LUN that degrades again is reported again
+func (discovery *DeviceDiscovery) reportDegradedLUNs(degraded []degradedLUN) {
+	current := sets.New[string]()
+	for _, lun := range degraded {
+		current.Insert(lun.wwn)
+		klog.Warningf("multipath LUN used by Storage Scale is degraded: %s", lun)
+		discovery.eventSync.Forget(devicefinder.NewSuccessEvent(devicefinder.MultipathPathsRestored, "", lun.wwn))
+		e := devicefinder.NewEvent(devicefinder.MultipathPathsLost, lun.String(), lun.wwn)
+		discovery.eventSync.Report(e, discovery.localVolumeDiscovery)
+	}
+	for _, wwn := range sets.List(discovery.degradedLUNs.Difference(current)) {
+		klog.Infof("multipath LUN %s has its expected number of paths again", wwn)
+		discovery.eventSync.Forget(devicefinder.NewEvent(devicefinder.MultipathPathsLost, "", wwn))
+		message := fmt.Sprintf("LUN %s has its expected number of paths again", wwn)
+		e := devicefinder.NewSuccessEvent(devicefinder.MultipathPathsRestored, message, wwn)
+		discovery.eventSync.Report(e, discovery.localVolumeDiscovery)
+	}
+	discovery.degradedLUNs = current
+}
@@ -0,0 +1,208 @@
+package discovery
+
+import (
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/openshift-storage-scale/openshift-fusion-access-operator/api/v1alpha1"
+	"github.com/openshift-storage-scale/openshift-fusion-access-operator/internal/devicefinder"
+	"github.com/openshift-storage-scale/openshift-fusion-access-operator/internal/diskutils"
+)
+
+var _ = Describe("Multipath Paths", func() {
+	const wwn = "0x600a098038304437415d4b6a5968624f"
+
+	var (
+		mockClient *devicefinder.MockAPIUpdater
+		dd         *DeviceDiscovery
+		localDisks []unstructured.Unstructured
+	)
+
+	// paths returns the members of a multipath LUN in the given states
+	paths := func(states ...string) []diskutils.BlockDevice {
+		members := []diskutils.BlockDevice{}
+		for idx, state := range states {
+			name := fmt.Sprintf("sd%c", 'b'+idx)
+			members = append(members, diskutils.BlockDevice{
+				Name: name, KName: name, Path: "/dev/" + name, Type: "disk", Size: 1024, FSType: "mpath_member",
+				WWN: wwn, State: state,
+				Children: []diskutils.BlockDevice{{Name: "mpatha", KName: "dm-0", Type: "mpath", Size: 1024}},
+			})
+		}
+		return members
+	}
+
+	localDisk := func(name, node, device string) unstructured.Unstructured {
+		obj := unstructured.Unstructured{Object: map[string]interface{}{
+			"spec": map[string]interface{}{"node": node, "device": device},
+		}}
+		obj.SetName(name)
+		return obj
+	}
+
+	check := func(blockDevices []diskutils.BlockDevice) ([]v1alpha1.DiscoveredDevice, metav1.Condition) {
+		devices, _ := getDiscoverdDevices(blockDevices, noDeviceFilter())
+		return devices, dd.checkMultipathPaths(devices)
+	}
+
+	warnings := func() []string {
+		messages := []string{}
+		for _, e := range mockClient.Events() {
+			if e.EventType == corev1.EventTypeWarning {
+				messages = append(messages, e.Message)
+			}
+		}
+		return messages
+	}
+
+	BeforeEach(func() {
+		setEnv()
+		DeferCleanup(unsetEnv)
+		localDisks = nil
+		mockClient = &devicefinder.MockAPIUpdater{
+			MockListLocalDisks: func(namespace string) ([]unstructured.Unstructured, error) {
+				Expect(namespace).To(Equal("ibm-spectrum-scale"))
+				return localDisks, nil
+			},
+		}
+		dd = getFakeDeviceDiscovery()
+		dd.apiClient = mockClient
+		dd.eventSync = devicefinder.NewEventReporter(mockClient)
+	})
+
+	It("counts the total and active paths", func() {
+		devices, condition := check(paths("running", "offline", "running"))
+		Expect(devices).To(HaveLen(1))
+		Expect(devices[0].TotalPaths).To(Equal(int32(3)))
+		Expect(devices[0].ActivePaths).To(Equal(int32(2)))
+		Expect(devices[0].ExpectedPaths).To(Equal(int32(3)))
+		Expect(devices[0].MultipathMembers).To(HaveLen(3))
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+	})
+
+	It("ignores the degraded LUNs that Storage Scale does not use", func() {
+		check(paths("running", "running"))
+		_, condition := check(paths("running"))
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		Expect(condition.Reason).To(Equal(v1alpha1.MultipathAllPathsActiveReason))
+		Expect(warnings()).To(BeEmpty())
+	})
+
+	It("reports a LUN used by a LocalDisk of this node that lost paths", func() {
+		localDisks = []unstructured.Unstructured{localDisk("nsd1", "node1", "/dev/dm-0")}
+		check(paths("running", "running"))
+		devices, condition := check(paths("running", "offline"))
+		Expect(devices[0].ExpectedPaths).To(Equal(int32(2)))
+		Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+		Expect(condition.Reason).To(Equal(v1alpha1.MultipathPathsLostReason))
+		Expect(condition.Message).To(Equal("LUN " + wwn + " (/dev/dm-0) has 1 of 2 paths active"))
+		Expect(warnings()).To(Equal([]string{condition.Message}))
+
+		// Still degraded, no new event
+		check(paths("running", "offline"))
+		Expect(warnings()).To(HaveLen(1))
+	})
+
+	It("matches the LocalDisks of other nodes by the WWN in their name", func() {
+		localDisks = []unstructured.Unstructured{localDisk("dm-0-"+wwn, "node2", "/dev/dm-3")}
+		check(paths("running", "running", "running", "running"))
+		_, condition := check(paths("running", "running"))
+		Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+		Expect(condition.Message).To(ContainSubstring("has 2 of 4 paths active"))
+	})
+
+	It("reports a LUN whose paths are all gone", func() {
+		localDisks = []unstructured.Unstructured{localDisk("dm-0-"+wwn, "node2", "/dev/dm-0")}
+		check(paths("running", "running"))
+		_, condition := check(nil)
+		Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+		Expect(condition.Message).To(Equal("LUN " + wwn + " has no path left, 2 expected"))
+	})
+
+	It("forgets a LUN that is not used once its paths are all gone", func() {
+		check(paths("running", "running"))
+		check(nil)
+		Expect(dd.expectedPaths).ToNot(HaveKey(wwn))
+		devices, _ := check(paths("running"))
+		Expect(devices[0].ExpectedPaths).To(Equal(int32(1)))
+	})
+
+	It("resets the expected number of paths when the result is annotated", func() {
+		localDisks = []unstructured.Unstructured{localDisk("nsd1", "node1", "/dev/dm-0")}
+		check(paths("running", "running"))
+
+		var updated *v1alpha1.LocalVolumeDiscoveryResult
+		mockClient.MockGetDiscoveryResult = func(name, namespace string) (*v1alpha1.LocalVolumeDiscoveryResult, error) {
+			Expect(name).To(Equal("discovery-result-node1"))
+			result := &v1alpha1.LocalVolumeDiscoveryResult{}
+			if updated == nil {
+				result.Annotations = map[string]string{v1alpha1.ResetExpectedPathsAnnotation: ""}
+			}
+			return result, nil
+		}
+		mockClient.MockUpdateDiscoveryResult = func(lvdr *v1alpha1.LocalVolumeDiscoveryResult) error {
+			updated = lvdr
+			return nil
+		}
+		devices, condition := check(paths("running"))
+		Expect(devices[0].ExpectedPaths).To(Equal(int32(1)))
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		Expect(updated.Annotations).ToNot(HaveKey(v1alpha1.ResetExpectedPathsAnnotation))
+
+		// The reset is applied once
+		check(paths("running", "running"))
+		_, condition = check(paths("running"))
+		Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+	})
+
+	It("reports the LUN again when it degrades after recovering", func() {
+		localDisks = []unstructured.Unstructured{localDisk("nsd1", "node1", "/dev/sdb")}
+		check(paths("running", "running"))
+		check(paths("running", "offline"))
+		_, condition := check(paths("running", "running"))
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		Expect(mockClient.Events()[len(mockClient.Events())-1].EventReason).To(Equal(devicefinder.MultipathPathsRestored))
+		check(paths("offline", "running"))
+		Expect(warnings()).To(HaveLen(2))
+	})
+
+	It("restores the expected number of paths from the result", func() {
+		localDisks = []unstructured.Unstructured{localDisk("nsd1", "node1", "/dev/dm-0")}
+		dd.loadExpectedPaths(&v1alpha1.LocalVolumeDiscoveryResult{Status: v1alpha1.LocalVolumeDiscoveryResultStatus{
+			DiscoveredDevices: []v1alpha1.DiscoveredDevice{{WWN: wwn, ExpectedPaths: 4}},
+		}})
+		devices, condition := check(paths("running", "running"))
+		Expect(devices[0].ExpectedPaths).To(Equal(int32(4)))
+		Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+	})
+
+	It("keeps the last condition when the LocalDisks cannot be listed", func() {
+		localDisks = []unstructured.Unstructured{localDisk("nsd1", "node1", "/dev/dm-0")}
+		check(paths("running", "running"))
+		dd.multipathCondition = multipathCondition([]degradedLUN{{wwn: wwn, path: "/dev/dm-0", active: 1, expected: 2}})
+		mockClient.MockListLocalDisks = func(string) ([]unstructured.Unstructured, error) {
+			return nil, fmt.Errorf("forbidden")
+		}
+		_, condition := check(paths("running", "running"))
+		Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+	})
+
+	It("sets the condition in the status", func() {
+		var updated *v1alpha1.LocalVolumeDiscoveryResult
+		mockClient.MockUpdateDiscoveryResultStatus = func(lvdr *v1alpha1.LocalVolumeDiscoveryResult) error {
+			updated = lvdr
+			return nil
+		}
+		localDisks = []unstructured.Unstructured{localDisk("nsd1", "node1", "/dev/dm-0")}
+		check(paths("running", "running"))
+		_, dd.multipathCondition = check(paths("offline", "running"))
+		Expect(dd.updateStatus()).To(Succeed())
+		Expect(meta.IsStatusConditionTrue(updated.Status.Conditions, v1alpha1.MultipathDegradedCondition)).To(BeTrue())
+	})
+})
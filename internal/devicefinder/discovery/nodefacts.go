@@ -0,0 +1,128 @@
+package discovery
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"runtime"
+	"slices"
+	"time"
+
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	apiTypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift-storage-scale/openshift-fusion-access-operator/api/v1alpha1"
+	"github.com/openshift-storage-scale/openshift-fusion-access-operator/internal/common"
+	"github.com/openshift-storage-scale/openshift-fusion-access-operator/internal/devicefinder"
+	"github.com/openshift-storage-scale/openshift-fusion-access-operator/internal/diskutils"
+)
+
+const nodeFactsCRName = "node-facts-%s"
+
+// newNodeFactsInstance creates spec for the NodeFacts of the node, owned by the LocalVolumeDiscovery like
+// the LocalVolumeDiscoveryResult
+func newNodeFactsInstance(nodeName, namespace, parentObjName, parentObjUID string) *v1alpha1.NodeFacts {
+	return &v1alpha1.NodeFacts{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      truncateNodeName(nodeFactsCRName, nodeName),
+			Namespace: namespace,
+			Labels:    map[string]string{common.DiscoveryNodeLabel: nodeName},
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion: v1alpha1.GroupVersion.String(),
+					Kind:       "LocalVolumeDiscovery",
+					Name:       parentObjName,
+					UID:        apiTypes.UID(parentObjUID),
+				},
+			},
+		},
+		Spec: v1alpha1.NodeFactsSpec{
+			NodeName: nodeName,
+		},
+	}
+}
+
+// getNodeFacts reads the facts of the host
+func getNodeFacts() v1alpha1.NodeFactsStatus {
+	facts := diskutils.Host.ReadHostFacts()
+	status := v1alpha1.NodeFactsStatus{
+		KernelVersion:            facts.KernelVersion,
+		Architecture:             runtime.GOARCH,
+		SecureBoot:               secureBootState(facts),
+		MultipathdActive:         facts.MultipathdActive,
+		MemoryTotal:              facts.MemoryTotal,
+		CPUCount:                 facts.CPUCount,
+		LoadedModules:            facts.LoadedModules,
+		StorageScaleModuleLoaded: slices.Contains(facts.LoadedModules, v1alpha1.StorageScaleKernelModule),
+	}
+	for _, hugePages := range facts.HugePages {
+		status.HugePages = append(status.HugePages, v1alpha1.HugePages{
+			Size:  resource.NewQuantity(hugePages.SizeKB*1024, resource.BinarySI).String(),
+			Total: hugePages.Total,
+			Free:  hugePages.Free,
+		})
+	}
+	return status
+}
+
+// secureBootState returns the Secure Boot state of the host
+func secureBootState(facts diskutils.HostFacts) v1alpha1.SecureBootState {
+	switch {
+	case !facts.EFI:
+		return v1alpha1.SecureBootUnsupported
+	case facts.SecureBoot == nil:
+		return v1alpha1.SecureBootUnknown
+	case *facts.SecureBoot:
+		return v1alpha1.SecureBootEnabled
+	default:
+		return v1alpha1.SecureBootDisabled
+	}
+}
+
+// syncNodeFacts publishes the facts of the host in the NodeFacts of the node, when they changed since
+// they were last published
+func (discovery *DeviceDiscovery) syncNodeFacts() error {
+	facts := getNodeFacts()
+	if discovery.nodeFacts != nil && reflect.DeepEqual(*discovery.nodeFacts, facts) {
+		return nil
+	}
+
+	if err := discovery.updateNodeFacts(facts); err != nil {
+		message := "failed to update NodeFacts status"
+		e := devicefinder.NewEvent(
+			devicefinder.ErrorUpdatingNodeFactsObject,
+			fmt.Sprintf("%s. Error: %+v", message, err),
+			"",
+		)
+		discovery.eventSync.Report(e, discovery.localVolumeDiscovery)
+		return fmt.Errorf("%s: %w", message, err)
+	}
+	discovery.nodeFacts = &facts
+	return nil
+}
+
+// updateNodeFacts creates the NodeFacts of the node if not present, and sets the facts in its status
+func (discovery *DeviceDiscovery) updateNodeFacts(facts v1alpha1.NodeFactsStatus) error {
+	newCR := newNodeFactsInstance(os.Getenv("MY_NODE_NAME"), os.Getenv("WATCH_NAMESPACE"),
+		os.Getenv("DISCOVERY_OBJECT_NAME"), os.Getenv("DISCOVERY_OBJECT_UID"))
+	nodeFacts, err := discovery.apiClient.GetNodeFacts(newCR.Name, newCR.Namespace)
+	if kerrors.IsNotFound(err) {
+		if err := discovery.apiClient.CreateNodeFacts(newCR); err != nil {
+			return fmt.Errorf("failed to create NodeFacts resource: %w", err)
+		}
+		klog.Info("successfully created NodeFacts resource")
+		nodeFacts = newCR
+	} else if err != nil {
+		return fmt.Errorf("failed to retrieve NodeFacts resource to update status: %w", err)
+	}
+
+	nodeFacts.Status = facts
+	nodeFacts.Status.CollectedTimeStamp = time.Now().UTC().Format(time.RFC3339)
+	if err := discovery.apiClient.UpdateNodeFactsStatus(nodeFacts); err != nil {
+		return fmt.Errorf("failed to update the facts in the NodeFacts resource: %w", err)
+	}
+	return nil
+}
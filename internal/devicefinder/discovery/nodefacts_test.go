@@ -0,0 +1,149 @@
+package discovery
+
+import (
+	"fmt"
+	"runtime"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/openshift-storage-scale/openshift-fusion-access-operator/api/v1alpha1"
+	"github.com/openshift-storage-scale/openshift-fusion-access-operator/internal/common"
+	"github.com/openshift-storage-scale/openshift-fusion-access-operator/internal/devicefinder"
+	"github.com/openshift-storage-scale/openshift-fusion-access-operator/internal/diskutils"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// fakeHostFactsReader returns fixed host facts
+type fakeHostFactsReader struct {
+	facts diskutils.HostFacts
+}
+
+func (r fakeHostFactsReader) ReadHostFacts() diskutils.HostFacts {
+	return r.facts
+}
+
+var _ = Describe("NodeFacts", func() {
+	enabled := true
+	hostFacts := diskutils.HostFacts{
+		KernelVersion:    "5.14.0-427.50.1.el9_4.x86_64",
+		EFI:              true,
+		SecureBoot:       &enabled,
+		MultipathdActive: true,
+		HugePages: []diskutils.HugePageSize{
+			{SizeKB: 2048, Total: 512, Free: 500},
+			{SizeKB: 1048576},
+		},
+		MemoryTotal:   32 * 1024 * 1024 * 1024,
+		CPUCount:      16,
+		LoadedModules: []string{"dm_multipath", "mmfs26", "nvme"},
+	}
+
+	setHostFacts := func(facts diskutils.HostFacts) {
+		original := diskutils.Host
+		DeferCleanup(func() { diskutils.Host = original })
+		diskutils.Host = fakeHostFactsReader{facts: facts}
+	}
+
+	It("converts the facts of the host", func() {
+		setHostFacts(hostFacts)
+		Expect(getNodeFacts()).To(Equal(v1alpha1.NodeFactsStatus{
+			KernelVersion:    "5.14.0-427.50.1.el9_4.x86_64",
+			Architecture:     runtime.GOARCH,
+			SecureBoot:       v1alpha1.SecureBootEnabled,
+			MultipathdActive: true,
+			HugePages: []v1alpha1.HugePages{
+				{Size: "2Mi", Total: 512, Free: 500},
+				{Size: "1Gi"},
+			},
+			MemoryTotal:              32 * 1024 * 1024 * 1024,
+			CPUCount:                 16,
+			LoadedModules:            []string{"dm_multipath", "mmfs26", "nvme"},
+			StorageScaleModuleLoaded: true,
+		}))
+	})
+
+	DescribeTable("reports the Secure Boot state",
+		func(efi bool, secureBoot *bool, expected v1alpha1.SecureBootState) {
+			Expect(secureBootState(diskutils.HostFacts{EFI: efi, SecureBoot: secureBoot})).To(Equal(expected))
+		},
+		Entry("Secure Boot on", true, &enabled, v1alpha1.SecureBootEnabled),
+		Entry("Secure Boot off", true, new(bool), v1alpha1.SecureBootDisabled),
+		Entry("unreadable EFI variable", true, nil, v1alpha1.SecureBootUnknown),
+		Entry("node without EFI", false, nil, v1alpha1.SecureBootUnsupported),
+	)
+
+	It("creates the NodeFacts of the node and only updates them when the facts change", func() {
+		var created, updated *v1alpha1.NodeFacts
+		updates := 0
+		mockClient := &devicefinder.MockAPIUpdater{
+			MockGetNodeFacts: func(name, namespace string) (*v1alpha1.NodeFacts, error) {
+				if created == nil {
+					return nil, kerrors.NewNotFound(schema.GroupResource{Resource: "nodefacts"}, name)
+				}
+				return created.DeepCopy(), nil
+			},
+			MockCreateNodeFacts: func(nodeFacts *v1alpha1.NodeFacts) error {
+				created = nodeFacts.DeepCopy()
+				return nil
+			},
+			MockUpdateNodeFactsStatus: func(nodeFacts *v1alpha1.NodeFacts) error {
+				updated = nodeFacts
+				updates++
+				return nil
+			},
+		}
+		dd := getFakeDeviceDiscovery()
+		dd.apiClient = mockClient
+		setEnv()
+		defer unsetEnv()
+		setHostFacts(hostFacts)
+
+		Expect(dd.syncNodeFacts()).To(Succeed())
+		Expect(created.Name).To(Equal("node-facts-node1"))
+		Expect(created.Namespace).To(Equal("ns"))
+		Expect(created.Labels).To(HaveKeyWithValue(common.DiscoveryNodeLabel, "node1"))
+		Expect(created.OwnerReferences[0].Name).To(Equal("auto-discover-devices"))
+		Expect(created.Spec.NodeName).To(Equal("node1"))
+		Expect(updated.Status.StorageScaleModuleLoaded).To(BeTrue())
+		Expect(updated.Status.CollectedTimeStamp).ToNot(BeEmpty())
+		Expect(updates).To(Equal(1))
+
+		Expect(dd.syncNodeFacts()).To(Succeed())
+		Expect(updates).To(Equal(1))
+
+		unloaded := hostFacts
+		unloaded.LoadedModules = []string{"dm_multipath", "nvme"}
+		setHostFacts(unloaded)
+		Expect(dd.syncNodeFacts()).To(Succeed())
+		Expect(updates).To(Equal(2))
+		Expect(updated.Status.StorageScaleModuleLoaded).To(BeFalse())
+	})
+
+	It("publishes the facts again after a failed update", func() {
+		failUpdate := true
+		mockClient := &devicefinder.MockAPIUpdater{
+			MockUpdateNodeFactsStatus: func(nodeFacts *v1alpha1.NodeFacts) error {
+				if failUpdate {
+					return fmt.Errorf("failed to update status")
+				}
+				return nil
+			},
+		}
+		dd := getFakeDeviceDiscovery()
+		dd.apiClient = mockClient
+		dd.eventSync = devicefinder.NewEventReporter(mockClient)
+		setEnv()
+		defer unsetEnv()
+		setHostFacts(hostFacts)
+
+		Expect(dd.syncNodeFacts()).ToNot(Succeed())
+		Expect(dd.nodeFacts).To(BeNil())
+		Expect(mockClient.Events()).To(ContainElement(HaveField("EventReason", devicefinder.ErrorUpdatingNodeFactsObject)))
+
+		failUpdate = false
+		Expect(dd.syncNodeFacts()).To(Succeed())
+		Expect(dd.nodeFacts).ToNot(BeNil())
+	})
+})
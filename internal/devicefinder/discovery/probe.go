@@ -0,0 +1,126 @@
+package discovery
+
+import (
+	"slices"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift-storage-scale/openshift-fusion-access-operator/api/v1alpha1"
+)
+
+// probeRefreshInterval is how long the probed attributes of a device are kept without a uevent. Writes
+// through a multipath device do not reach the monitor, since the uevents of dm devices are ignored
+var probeRefreshInterval = time.Hour
+
+// probeKey identifies a device like uniqueDevices does: by WWN, or by path for the devices without one
+type probeKey struct {
+	wwn  string
+	path string
+}
+
+// probedDevice holds what was read from a device itself, and what it was read for
+type probedDevice struct {
+	path     string
+	size     int64
+	probedAt time.Time
+
+	header                     v1alpha1.DeviceHeaderClass
+	nsdName                    string
+	signature                  string
+	persistentReservation      v1alpha1.PersistentReservationSupport
+	persistentReservationTypes []string
+}
+
+// deviceProbes caches the header and the persistent reservation support of the discovered devices, so
+// that every discovery does not send I/O and SCSI commands to every LUN. A device is probed again when
+// its path or size changes, when a uevent names it, or after probeRefreshInterval
+type deviceProbes struct {
+	devices map[probeKey]*probedDevice
+	// changed are the kernel names of the devices named by uevents since the last discovery
+	changed sets.Set[string]
+	// changedAll is set when uevents may have been missed
+	changedAll bool
+}
+
+func newDeviceProbes() *deviceProbes {
+	return &deviceProbes{devices: map[probeKey]*probedDevice{}, changed: sets.New[string]()}
+}
+
+func keyOf(device *v1alpha1.DiscoveredDevice) probeKey {
+	if device.WWN == "" {
+		return probeKey{path: device.Path}
+	}
+	return probeKey{wwn: device.WWN}
+}
+
+// invalidate makes the devices with one of the kernel names, like sdb or dm-0, be probed again
+func (p *deviceProbes) invalidate(names sets.Set[string]) {
+	if names.Has(allDevices) {
+		p.changedAll = true
+		return
+	}
+	p.changed = p.changed.Union(names)
+}
+
+// forget makes the devices of the LUN be probed again
+func (p *deviceProbes) forget(wwn string) {
+	delete(p.devices, probeKey{wwn: wwn})
+}
+
+// apply sets the probed attributes of the devices, probing the ones that are not cached or changed.
+// The devices that are gone are forgotten
+func (p *deviceProbes) apply(devices []v1alpha1.DiscoveredDevice) {
+	seen := map[probeKey]*probedDevice{}
+	for idx := range devices {
+		device := &devices[idx]
+		key := keyOf(device)
+		probed, found := p.devices[key]
+		if !found || p.changedAll || probed.path != device.Path || probed.size != device.Size ||
+			time.Since(probed.probedAt) > probeRefreshInterval || p.namesChanged(device) {
+			probed = probe(device)
+		}
+		probed.restore(device)
+		seen[key] = probed
+	}
+	p.devices = seen
+	p.changed = sets.New[string]()
+	p.changedAll = false
+}
+
+// namesChanged returns true when a uevent named the device or one of its paths
+func (p *deviceProbes) namesChanged(device *v1alpha1.DiscoveredDevice) bool {
+	for name := range p.changed {
+		if slices.Contains(device.Names(), "/dev/"+name) {
+			return true
+		}
+	}
+	return false
+}
+
+// probe reads the header and the persistent reservation support of the device
+func probe(device *v1alpha1.DiscoveredDevice) *probedDevice {
+	klog.V(2).Infof("probing the device %q", device.Path)
+	setHeader(device)
+	setPersistentReservation(device)
+	return &probedDevice{
+		path:                       device.Path,
+		size:                       device.Size,
+		probedAt:                   time.Now(),
+		header:                     device.Header,
+		nsdName:                    device.NSDName,
+		signature:                  device.Signature,
+		persistentReservation:      device.PersistentReservation,
+		persistentReservationTypes: device.PersistentReservationTypes,
+	}
+}
+
+// restore sets the probed attributes on the device
+func (probed *probedDevice) restore(device *v1alpha1.DiscoveredDevice) {
+	device.Header = probed.header
+	device.NSDName = probed.nsdName
+	device.Signature = probed.signature
+	device.PersistentReservation = probed.persistentReservation
+	device.PersistentReservationTypes = probed.persistentReservationTypes
+}
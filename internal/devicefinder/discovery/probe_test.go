@@ -0,0 +1,91 @@
+package discovery
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/openshift-storage-scale/openshift-fusion-access-operator/api/v1alpha1"
+	"github.com/openshift-storage-scale/openshift-fusion-access-operator/internal/diskutils"
+)
+
+var _ = Describe("Device Probes", func() {
+	var (
+		probes *deviceProbes
+		reads  map[string]int
+	)
+
+	devices := func() []v1alpha1.DiscoveredDevice {
+		return []v1alpha1.DiscoveredDevice{
+			{
+				Path: "/dev/dm-0", WWN: "0x600a", Size: 1024,
+				MultipathMembers: []v1alpha1.MultipathMember{{Name: "sdb", Path: "/dev/sdb"}, {Name: "sdc", Path: "/dev/sdc"}},
+			},
+			{Path: "/dev/sdd", Size: 2048},
+		}
+	}
+
+	BeforeEach(func() {
+		probes = newDeviceProbes()
+		reads = map[string]int{}
+		original, originalInterval := diskutils.ReadDeviceHeader, probeRefreshInterval
+		DeferCleanup(func() { diskutils.ReadDeviceHeader, probeRefreshInterval = original, originalInterval })
+		diskutils.ReadDeviceHeader = func(path string) ([]byte, error) {
+			reads[path]++
+			return make([]byte, diskutils.HeaderSize), nil
+		}
+		probes.apply(devices())
+		Expect(reads).To(Equal(map[string]int{"/dev/dm-0": 1, "/dev/sdd": 1}))
+	})
+
+	It("keeps the probed attributes of the devices that did not change", func() {
+		discovered := devices()
+		probes.apply(discovered)
+		Expect(reads).To(Equal(map[string]int{"/dev/dm-0": 1, "/dev/sdd": 1}))
+		Expect(discovered[0].Header).To(Equal(v1alpha1.HeaderFree))
+		Expect(discovered[0].PersistentReservation).To(Equal(v1alpha1.PersistentReservationUnknown))
+		Expect(discovered[1].Header).To(Equal(v1alpha1.HeaderFree))
+	})
+
+	It("probes a device again when a uevent names one of its paths", func() {
+		probes.invalidate(sets.New("sdc"))
+		probes.apply(devices())
+		Expect(reads).To(Equal(map[string]int{"/dev/dm-0": 2, "/dev/sdd": 1}))
+
+		// Once
+		probes.apply(devices())
+		Expect(reads).To(Equal(map[string]int{"/dev/dm-0": 2, "/dev/sdd": 1}))
+	})
+
+	It("probes every device again when uevents may have been missed", func() {
+		probes.invalidate(sets.New(allDevices))
+		probes.apply(devices())
+		Expect(reads).To(Equal(map[string]int{"/dev/dm-0": 2, "/dev/sdd": 2}))
+	})
+
+	It("probes a device again when its size or path changes", func() {
+		discovered := devices()
+		discovered[0].Size = 4096
+		discovered[1].Path = "/dev/sde"
+		probes.apply(discovered)
+		Expect(reads).To(Equal(map[string]int{"/dev/dm-0": 2, "/dev/sdd": 1, "/dev/sde": 1}))
+	})
+
+	It("probes a LUN again after it was wiped", func() {
+		probes.forget("0x600a")
+		probes.apply(devices())
+		Expect(reads).To(Equal(map[string]int{"/dev/dm-0": 2, "/dev/sdd": 1}))
+	})
+
+	It("forgets the devices that are gone", func() {
+		probes.apply(devices()[1:])
+		probes.apply(devices())
+		Expect(reads).To(Equal(map[string]int{"/dev/dm-0": 2, "/dev/sdd": 1}))
+	})
+
+	It("probes the devices again after the refresh interval", func() {
+		probeRefreshInterval = 0
+		probes.apply(devices())
+		Expect(reads).To(Equal(map[string]int{"/dev/dm-0": 2, "/dev/sdd": 2}))
+	})
+})
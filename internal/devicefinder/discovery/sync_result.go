@@ -12,6 +12,7 @@ import (
 	"github.com/openshift-storage-scale/openshift-fusion-access-operator/internal/devicefinder"
 
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	apiTypes "k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/validation"
@@ -55,7 +56,10 @@ func (discovery *DeviceDiscovery) ensureDiscoveryResultCR() error {
 		return fmt.Errorf("failed to create LocalVolumeDiscoveryResult resource. missing required env variables")
 	}
 	newCR := newDiscoveryResultInstance(nodeName, namespace, parentObjName, parentObjUID)
-	_, err := discovery.apiClient.GetDiscoveryResult(newCR.Name, newCR.Namespace)
+	existingCR, err := discovery.apiClient.GetDiscoveryResult(newCR.Name, newCR.Namespace)
+	if err == nil {
+		discovery.loadExpectedPaths(existingCR)
+	}
 
 	if kerrors.IsNotFound(err) {
 		err = discovery.apiClient.CreateDiscoveryResult(newCR)
@@ -86,7 +90,12 @@ func (discovery *DeviceDiscovery) updateStatus() error {
 
 	// Update discovered devce list and discovery time
 	resultCR.Status.DiscoveredDevices = discovery.disks
+	resultCR.Status.RejectedDevices = discovery.rejectedDisks
+	resultCR.Status.Initiators = discovery.initiators
 	resultCR.Status.DiscoveredTimeStamp = time.Now().UTC().Format(time.RFC3339)
+	if discovery.multipathCondition.Type != "" {
+		meta.SetStatusCondition(&resultCR.Status.Conditions, discovery.multipathCondition)
+	}
 
 	err = discovery.apiClient.UpdateDiscoveryResultStatus(resultCR)
 	if err != nil {
@@ -56,6 +56,23 @@ var _ = Describe("DeviceDiscovery", func() {
 			Expect(err).ToNot(HaveOccurred())
 		})
 
+		It("should publish the initiators of the node", func() {
+			var updated *v1alpha1.LocalVolumeDiscoveryResult
+			mockClient := &devicefinder.MockAPIUpdater{
+				MockUpdateDiscoveryResultStatus: func(lvdr *v1alpha1.LocalVolumeDiscoveryResult) error {
+					updated = lvdr
+					return nil
+				},
+			}
+			dd := getFakeDeviceDiscovery()
+			dd.apiClient = mockClient
+			dd.initiators = &v1alpha1.NodeInitiators{ISCSIInitiatorName: "iqn.1994-05.com.redhat:worker-0"}
+			setEnv()
+			defer unsetEnv()
+			Expect(dd.updateStatus()).To(Succeed())
+			Expect(updated.Status.Initiators).To(Equal(dd.initiators))
+		})
+
 		It("should fail when getting discovery result fails", func() {
 			mockClient := &devicefinder.MockAPIUpdater{
 				MockGetDiscoveryResult: func(name, namespace string) (*v1alpha1.LocalVolumeDiscoveryResult, error) {
@@ -170,7 +187,7 @@ var _ = Describe("DeviceDiscovery", func() {
 })
 
 func getFakeDeviceDiscovery() *DeviceDiscovery {
-	dd := &DeviceDiscovery{}
+	dd := &DeviceDiscovery{probes: newDeviceProbes()}
 	dd.apiClient = &devicefinder.MockAPIUpdater{}
 	dd.eventSync = devicefinder.NewEventReporter(dd.apiClient)
 	dd.disks = []v1alpha1.DiscoveredDevice{}
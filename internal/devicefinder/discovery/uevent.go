@@ -0,0 +1,188 @@
+package discovery
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sys/unix"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// ueventKernelGroup receives the uevents sent by the kernel. The udev group is not used: its messages
+	// are sent by a userspace process and the daemon does not share the network namespace of udev
+	ueventKernelGroup  = 1
+	ueventBufferSize   = 64 * 1024
+	ueventSocketRcvBuf = 4 * 1024 * 1024
+	// ueventUnhealthyAfter is how long the monitor can stay disconnected before it is reported unhealthy
+	ueventUnhealthyAfter = 5 * time.Minute
+)
+
+var (
+	// ueventMinBackoff and ueventMaxBackoff bound the delay between two attempts to reopen the uevent socket
+	ueventMinBackoff = time.Second
+	ueventMaxBackoff = time.Minute
+)
+
+// ueventSocket receives raw uevent messages
+type ueventSocket interface {
+	Receive() ([]byte, error)
+	Close() error
+}
+
+// dialUevents opens the socket uevents are read from, it is replaced in the tests
+var dialUevents = dialNetlinkUevents
+
+// netlinkueventSocket is a NETLINK_KOBJECT_UEVENT socket subscribed to the kernel group
+type netlinkueventSocket struct {
+	fd  int
+	buf []byte
+}
+
+func dialNetlinkUevents() (ueventSocket, error) {
+	fd, err := unix.Socket(unix.AF_NETLINK, unix.SOCK_RAW|unix.SOCK_CLOEXEC, unix.NETLINK_KOBJECT_UEVENT)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open uevent socket: %w", err)
+	}
+	// A larger buffer avoids ENOBUFS when many LUNs are mapped at once
+	if err := unix.SetsockoptInt(fd, unix.SOL_SOCKET, unix.SO_RCVBUFFORCE, ueventSocketRcvBuf); err != nil {
+		klog.Warningf("failed to grow the uevent socket buffer. Error %v", err)
+	}
+	addr := &unix.SockaddrNetlink{Family: unix.AF_NETLINK, Groups: ueventKernelGroup}
+	if err := unix.Bind(fd, addr); err != nil {
+		_ = unix.Close(fd)
+		return nil, fmt.Errorf("failed to bind uevent socket: %w", err)
+	}
+	return &netlinkueventSocket{fd: fd, buf: make([]byte, ueventBufferSize)}, nil
+}
+
+// Receive returns the next message sent by the kernel, the messages sent by other processes are dropped
+func (s *netlinkueventSocket) Receive() ([]byte, error) {
+	for {
+		n, from, err := unix.Recvfrom(s.fd, s.buf, 0)
+		if err != nil {
+			return nil, fmt.Errorf("failed to receive uevent: %w", err)
+		}
+		if sender, ok := from.(*unix.SockaddrNetlink); !ok || sender.Pid != 0 {
+			klog.V(2).Infof("ignoring uevent not sent by the kernel")
+			continue
+		}
+		return append([]byte(nil), s.buf[:n]...), nil
+	}
+}
+
+func (s *netlinkueventSocket) Close() error {
+	return unix.Close(s.fd)
+}
+
+// uevent holds the properties of a uevent the discovery cares about
+type uevent struct {
+	Action    string
+	DevPath   string
+	Subsystem string
+	DevName   string
+	DevType   string
+}
+
+// parseUevent parses a kernel uevent, action@devpath followed by NUL separated KEY=VALUE pairs
+func parseUevent(msg []byte) (*uevent, error) {
+	header, properties, found := bytes.Cut(msg, []byte{0})
+	if !found || !bytes.Contains(header, []byte("@")) {
+		return nil, fmt.Errorf("invalid uevent header %q", header)
+	}
+
+	event := &uevent{}
+	for _, property := range bytes.Split(properties, []byte{0}) {
+		key, value, found := strings.Cut(string(property), "=")
+		if !found {
+			continue
+		}
+		switch key {
+		case "ACTION":
+			event.Action = value
+		case "DEVPATH":
+			event.DevPath = value
+		case "SUBSYSTEM":
+			event.Subsystem = value
+		case "DEVNAME":
+			event.DevName = value
+		case "DEVTYPE":
+			event.DevType = value
+		}
+	}
+	if event.Action == "" {
+		return nil, fmt.Errorf("uevent without ACTION")
+	}
+	return event, nil
+}
+
+// monitorHealth tracks the connection of the uevent monitor
+type monitorHealth struct {
+	mux            sync.Mutex
+	connected      bool
+	since          time.Time
+	lastError      error
+	lastEvent      time.Time
+	reconnects     int
+	unhealthyAfter time.Duration
+}
+
+func newMonitorHealth() *monitorHealth {
+	return &monitorHealth{since: time.Now(), unhealthyAfter: ueventUnhealthyAfter}
+}
+
+func (h *monitorHealth) setConnected() {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+	if !h.connected {
+		h.connected = true
+		h.since = time.Now()
+	}
+}
+
+func (h *monitorHealth) setDisconnected(err error) {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+	h.lastError = err
+	if h.connected {
+		h.connected = false
+		h.since = time.Now()
+		h.reconnects++
+	}
+}
+
+func (h *monitorHealth) setEvent() {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+	h.lastEvent = time.Now()
+}
+
+// Check returns an error when the monitor has been disconnected for too long
+func (h *monitorHealth) Check() error {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+	if h.connected || time.Since(h.since) < h.unhealthyAfter {
+		return nil
+	}
+	return fmt.Errorf("uevent monitor disconnected since %s after %d reconnects: %v",
+		h.since.Format(time.RFC3339), h.reconnects, h.lastError)
+}
+
+// String describes the state of the monitor
+func (h *monitorHealth) String() string {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+	state := "disconnected"
+	if h.connected {
+		state = "connected"
+	}
+	lastEvent := "never"
+	if !h.lastEvent.IsZero() {
+		lastEvent = h.lastEvent.Format(time.RFC3339)
+	}
+	return fmt.Sprintf("uevent monitor %s since %s, %d reconnects, last event %s",
+		state, h.since.Format(time.RFC3339), h.reconnects, lastEvent)
+}
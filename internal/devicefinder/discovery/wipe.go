@@ -0,0 +1,175 @@
+package discovery
+
+import (
+	"fmt"
+	"os"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift-storage-scale/openshift-fusion-access-operator/api/v1alpha1"
+	"github.com/openshift-storage-scale/openshift-fusion-access-operator/internal/common"
+	"github.com/openshift-storage-scale/openshift-fusion-access-operator/internal/devicefinder"
+	"github.com/openshift-storage-scale/openshift-fusion-access-operator/internal/diskutils"
+)
+
+// gpfsSignature is the previous signature recorded for a Storage Scale NSD
+const gpfsSignature = "gpfs"
+
+// processWipeRequests wipes the LUNs of the DeviceWipeRequests of this node that the operator approved.
+// It returns true when a request was processed, the devices then have to be discovered again
+func (discovery *DeviceDiscovery) processWipeRequests() bool {
+	wipeRequests, err := discovery.apiClient.ListDeviceWipeRequests()
+	if err != nil {
+		klog.Warningf("failed to list the DeviceWipeRequests. Error %v", err)
+		return false
+	}
+	nodeName := os.Getenv("MY_NODE_NAME")
+	processed := false
+	for idx := range wipeRequests {
+		wipeRequest := &wipeRequests[idx]
+		if wipeRequest.Spec.NodeName != nodeName || wipeRequest.Status.Phase != v1alpha1.DeviceWipeApproved {
+			continue
+		}
+		discovery.processWipeRequest(wipeRequest)
+		processed = true
+	}
+	return processed
+}
+
+// processWipeRequest wipes the LUN of the request, and records the outcome in its status
+func (discovery *DeviceDiscovery) processWipeRequest(wipeRequest *v1alpha1.DeviceWipeRequest) {
+	wwn := wipeRequest.Spec.WWN
+	klog.Infof("wiping LUN %s for DeviceWipeRequest %q", wwn, wipeRequest.Name)
+
+	var e *devicefinder.DiskEvent
+	err := discovery.wipeLUN(wipeRequest)
+	// The wipe changes the header of the LUN, even when it fails part way
+	discovery.probes.forget(wwn)
+	now := metav1.Now()
+	wipeRequest.Status.CompletionTime = &now
+	if err != nil {
+		klog.Errorf("failed to wipe LUN %s. Error %v", wwn, err)
+		wipeRequest.Status.Phase = v1alpha1.DeviceWipeFailed
+		wipeRequest.Status.Message = err.Error()
+		e = devicefinder.NewEvent(devicefinder.ErrorWipingDevice, fmt.Sprintf("failed to wipe LUN %s: %v", wwn, err), wwn)
+	} else {
+		wipeRequest.Status.Phase = v1alpha1.DeviceWipeCompleted
+		wipeRequest.Status.Message = fmt.Sprintf("cleared the signatures of %s", wipeRequest.Status.DevicePath)
+		e = devicefinder.NewSuccessEvent(devicefinder.DeviceWiped,
+			fmt.Sprintf("LUN %s was wiped, %s", wwn, wipeRequest.Status.Message), wwn)
+	}
+	// Every request is reported, even when the same LUN was wiped before
+	discovery.eventSync.Forget(e)
+	discovery.eventSync.Report(e, wipeRequest)
+
+	if err := discovery.apiClient.UpdateDeviceWipeRequestStatus(wipeRequest); err != nil {
+		klog.Errorf("failed to update the status of DeviceWipeRequest %q. Error %v", wipeRequest.Name, err)
+	}
+}
+
+// wipeLUN checks again that the LUN is not mounted nor used by a LocalDisk, since this may have changed
+// after the approval, then clears its signatures
+func (discovery *DeviceDiscovery) wipeLUN(wipeRequest *v1alpha1.DeviceWipeRequest) error {
+	wwn := wipeRequest.Spec.WWN
+	blockDevices, err := diskutils.DeviceReader.ReadBlockDevices()
+	if err != nil {
+		return fmt.Errorf("failed to list the block devices: %w", err)
+	}
+	path, names, err := findWipeTarget(blockDevices, wwn)
+	if err != nil {
+		return err
+	}
+	localDisks, err := discovery.apiClient.ListLocalDisks(common.StorageScaleNamespace)
+	if err != nil {
+		return fmt.Errorf("failed to list LocalDisks: %w", err)
+	}
+	if localDisk := findLocalDisk(localDisks, wwn, names); localDisk != "" {
+		return fmt.Errorf("LUN is used by LocalDisk %s", localDisk)
+	}
+
+	header, err := diskutils.ReadDeviceHeader(path)
+	if err != nil {
+		return err
+	}
+	wipeRequest.Status.DevicePath = path
+	wipeRequest.Status.PreviousSignature = signatureOf(diskutils.ClassifyHeader(header))
+	wipeRequest.Status.WipedBytes, err = diskutils.WipeDevice(path)
+	if err != nil {
+		return err
+	}
+
+	header, err = diskutils.ReadDeviceHeader(path)
+	if err != nil {
+		return err
+	}
+	if signature := signatureOf(diskutils.ClassifyHeader(header)); signature != "" {
+		return fmt.Errorf("%s still has a %s signature after the wipe", path, signature)
+	}
+	return nil
+}
+
+// findWipeTarget returns the device to wipe for the LUN, the multipath device when the LUN has several
+// paths, along with all the names of the LUN on the node
+func findWipeTarget(blockDevices []diskutils.BlockDevice, wwn string) (string, []string, error) {
+	path := ""
+	var names []string
+	for idx := range blockDevices {
+		dev := &blockDevices[idx]
+		if dev.WWN != wwn {
+			continue
+		}
+		if mountpoint := findMountpoint(dev); mountpoint != "" {
+			return "", nil, fmt.Errorf("LUN is mounted on %q", mountpoint)
+		}
+		devPath, err := dev.GetDevPath()
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to get the path of %s: %w", dev.KName, err)
+		}
+		path = devPath
+		names = append(names, devPath, dev.Path)
+		if deviceID, err := dev.GetPathByID(); err == nil {
+			names = append(names, deviceID)
+		}
+		for _, link := range dev.ByPathLinks {
+			names = append(names, "/dev/disk/by-path/"+link)
+		}
+	}
+	if path == "" {
+		return "", nil, fmt.Errorf("LUN is not attached to the node")
+	}
+	return path, names, nil
+}
+
+// findMountpoint returns where the device, or a device built on top of it, is mounted
+func findMountpoint(dev *diskutils.BlockDevice) string {
+	if dev.Mountpoint != "" {
+		return dev.Mountpoint
+	}
+	for idx := range dev.Children {
+		if mountpoint := findMountpoint(&dev.Children[idx]); mountpoint != "" {
+			return mountpoint
+		}
+	}
+	return ""
+}
+
+// findLocalDisk returns the LocalDisk using the LUN, whose devices on this node have the given names
+func findLocalDisk(localDisks []unstructured.Unstructured, wwn string, names []string) string {
+	namesByNode := map[string][]string{os.Getenv("MY_NODE_NAME"): names}
+	for idx := range localDisks {
+		if common.LocalDiskUsesLUN(&localDisks[idx], wwn, namesByNode) {
+			return localDisks[idx].GetName()
+		}
+	}
+	return ""
+}
+
+// signatureOf returns the signature of the header, empty when it holds nothing known
+func signatureOf(header diskutils.DeviceHeader) string {
+	if header.GPFS {
+		return gpfsSignature
+	}
+	return header.Signature
+}
@@ -0,0 +1,173 @@
+package discovery
+
+import (
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/openshift-storage-scale/openshift-fusion-access-operator/api/v1alpha1"
+	"github.com/openshift-storage-scale/openshift-fusion-access-operator/internal/devicefinder"
+	"github.com/openshift-storage-scale/openshift-fusion-access-operator/internal/diskutils"
+)
+
+// fakeDeviceReader returns a fixed list of block devices
+type fakeDeviceReader struct {
+	devices []diskutils.BlockDevice
+}
+
+func (r fakeDeviceReader) ReadBlockDevices() ([]diskutils.BlockDevice, error) {
+	return r.devices, nil
+}
+
+var _ = Describe("Device Wipe", func() {
+	const wwn = "0x600a098038304437415d4b6a5968624f"
+
+	var (
+		mockClient   *devicefinder.MockAPIUpdater
+		dd           *DeviceDiscovery
+		localDisks   []unstructured.Unstructured
+		wipeRequests []v1alpha1.DeviceWipeRequest
+		updated      []v1alpha1.DeviceWipeRequest
+		wiped        []string
+		header       []byte
+	)
+
+	// multipathLUN is a LUN with two paths and an xfs filesystem on its multipath device
+	multipathLUN := func(mountpoint string) []diskutils.BlockDevice {
+		members := []diskutils.BlockDevice{}
+		for _, name := range []string{"sdb", "sdc"} {
+			members = append(members, diskutils.BlockDevice{
+				Name: name, KName: name, Path: "/dev/" + name, Type: "disk", Size: 1024, FSType: "mpath_member",
+				WWN: wwn, PathByID: "wwn-" + wwn,
+				Children: []diskutils.BlockDevice{{
+					Name: "mpatha", KName: "dm-0", Type: "mpath", Size: 1024, FSType: "xfs", PathByID: "dm-uuid-mpath-" + wwn,
+					Mountpoint: mountpoint,
+				}},
+			})
+		}
+		return members
+	}
+
+	newWipeRequest := func(node string, phase v1alpha1.DeviceWipePhase) v1alpha1.DeviceWipeRequest {
+		return v1alpha1.DeviceWipeRequest{
+			ObjectMeta: metav1.ObjectMeta{Name: "reuse-" + node},
+			Spec:       v1alpha1.DeviceWipeRequestSpec{WWN: wwn, NodeName: node, ConfirmWWN: wwn},
+			Status:     v1alpha1.DeviceWipeRequestStatus{Phase: phase},
+		}
+	}
+
+	localDisk := func(name, node, device string) unstructured.Unstructured {
+		obj := unstructured.Unstructured{Object: map[string]interface{}{
+			"spec": map[string]interface{}{"node": node, "device": device},
+		}}
+		obj.SetName(name)
+		return obj
+	}
+
+	BeforeEach(func() {
+		setEnv()
+		DeferCleanup(unsetEnv)
+		localDisks = nil
+		updated = nil
+		wiped = nil
+		wipeRequests = []v1alpha1.DeviceWipeRequest{
+			newWipeRequest("node1", v1alpha1.DeviceWipeApproved),
+			newWipeRequest("node2", v1alpha1.DeviceWipeApproved),
+			newWipeRequest("node1", v1alpha1.DeviceWipeCompleted),
+		}
+		header = make([]byte, diskutils.HeaderSize)
+		copy(header, "XFSB")
+
+		mockClient = &devicefinder.MockAPIUpdater{
+			MockListLocalDisks: func(string) ([]unstructured.Unstructured, error) { return localDisks, nil },
+			MockListDeviceWipeRequests: func() ([]v1alpha1.DeviceWipeRequest, error) {
+				return wipeRequests, nil
+			},
+			MockUpdateDeviceWipeRequest: func(wipeRequest *v1alpha1.DeviceWipeRequest) error {
+				updated = append(updated, *wipeRequest)
+				return nil
+			},
+		}
+		dd = getFakeDeviceDiscovery()
+		dd.apiClient = mockClient
+		dd.eventSync = devicefinder.NewEventReporter(mockClient)
+
+		readDeviceHeader, wipeDevice, deviceReader := diskutils.ReadDeviceHeader, diskutils.WipeDevice, diskutils.DeviceReader
+		DeferCleanup(func() {
+			diskutils.ReadDeviceHeader, diskutils.WipeDevice, diskutils.DeviceReader = readDeviceHeader, wipeDevice, deviceReader
+		})
+		diskutils.DeviceReader = fakeDeviceReader{devices: multipathLUN("")}
+		diskutils.ReadDeviceHeader = func(string) ([]byte, error) { return header, nil }
+		diskutils.WipeDevice = func(path string) (int64, error) {
+			wiped = append(wiped, path)
+			header = make([]byte, diskutils.HeaderSize)
+			return 2 * diskutils.WipeSize, nil
+		}
+	})
+
+	It("wipes the multipath device of the approved requests of the node", func() {
+		Expect(dd.processWipeRequests()).To(BeTrue())
+		Expect(wiped).To(Equal([]string{"/dev/dm-0"}))
+		Expect(updated).To(HaveLen(1))
+		status := updated[0].Status
+		Expect(updated[0].Spec.NodeName).To(Equal("node1"))
+		Expect(status.Phase).To(Equal(v1alpha1.DeviceWipeCompleted))
+		Expect(status.DevicePath).To(Equal("/dev/dm-0"))
+		Expect(status.PreviousSignature).To(Equal("xfs"))
+		Expect(status.WipedBytes).To(Equal(int64(2 * diskutils.WipeSize)))
+		Expect(status.CompletionTime).ToNot(BeNil())
+		Expect(mockClient.Events()).To(HaveLen(1))
+		Expect(mockClient.Events()[0].EventReason).To(Equal(devicefinder.DeviceWiped))
+	})
+
+	It("does nothing without approved requests for the node", func() {
+		wipeRequests = wipeRequests[1:]
+		Expect(dd.processWipeRequests()).To(BeFalse())
+		Expect(wiped).To(BeEmpty())
+		Expect(updated).To(BeEmpty())
+	})
+
+	DescribeTable("fails the request of a LUN that cannot be wiped",
+		func(setup func(), message string) {
+			setup()
+			Expect(dd.processWipeRequests()).To(BeTrue())
+			Expect(wiped).To(BeEmpty())
+			Expect(updated).To(HaveLen(1))
+			Expect(updated[0].Status.Phase).To(Equal(v1alpha1.DeviceWipeFailed))
+			Expect(updated[0].Status.Message).To(Equal(message))
+			Expect(mockClient.Events()[0].EventType).To(Equal(corev1.EventTypeWarning))
+		},
+		Entry("mounted since the approval", func() {
+			diskutils.DeviceReader = fakeDeviceReader{devices: multipathLUN("/var/data")}
+		}, `LUN is mounted on "/var/data"`),
+		Entry("detached from the node", func() {
+			diskutils.DeviceReader = fakeDeviceReader{}
+		}, "LUN is not attached to the node"),
+		Entry("used by a LocalDisk of the node", func() {
+			localDisks = []unstructured.Unstructured{localDisk("nsd1", "node1", "/dev/sdc")}
+		}, "LUN is used by LocalDisk nsd1"),
+		Entry("used by a LocalDisk named after it", func() {
+			localDisks = []unstructured.Unstructured{localDisk("dm-3-"+wwn, "node2", "/dev/dm-3")}
+		}, "LUN is used by LocalDisk dm-3-"+wwn),
+		Entry("LocalDisks cannot be listed", func() {
+			mockClient.MockListLocalDisks = func(string) ([]unstructured.Unstructured, error) {
+				return nil, fmt.Errorf("forbidden")
+			}
+		}, "failed to list LocalDisks: forbidden"),
+	)
+
+	It("fails the request when a signature is left", func() {
+		diskutils.WipeDevice = func(path string) (int64, error) {
+			wiped = append(wiped, path)
+			return 2 * diskutils.WipeSize, nil
+		}
+		dd.processWipeRequests()
+		Expect(wiped).To(HaveLen(1))
+		Expect(updated[0].Status.Phase).To(Equal(v1alpha1.DeviceWipeFailed))
+		Expect(updated[0].Status.Message).To(Equal("/dev/dm-0 still has a xfs signature after the wipe"))
+	})
+})
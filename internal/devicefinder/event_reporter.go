@@ -26,9 +26,21 @@ const (
 	ErrorCreatingDiscoveryResultObject = "ErrorCreatingDiscoveryResultObject"
 	ErrorUpdatingDiscoveryResultObject = "ErrorUpdatingDiscoveryResultObject"
 	ErrorListingBlockDevices           = "ErrorListingBlockDevices"
+	ErrorInvalidDeviceFilter           = "ErrorInvalidDeviceFilter"
 
 	CreatedDiscoveryResultObject = "CreatedDiscoveryResultObject"
 	UpdatedDiscoveredDeviceList  = "UpdatedDiscoveredDeviceList"
+
+	// Multipath events, the disk is the WWN of the LUN
+	MultipathPathsLost     = "MultipathPathsLost"
+	MultipathPathsRestored = "MultipathPathsRestored"
+
+	// DeviceWipeRequest events, the disk is the WWN of the LUN
+	DeviceWiped       = "DeviceWiped"
+	ErrorWipingDevice = "ErrorWipingDevice"
+
+	// NodeFacts events
+	ErrorUpdatingNodeFactsObject = "ErrorUpdatingNodeFactsObject"
 )
 
 // DiskEvent is instance of a single event
@@ -75,3 +87,11 @@ func (reporter *EventReporter) Report(e *DiskEvent, obj runtime.Object) {
 	reporter.apiClient.recordEvent(obj, e)
 	reporter.reportedEvents.Insert(eventKey)
 }
+
+// Forget allows an event that was already reported to be reported again, like when the condition
+// it reported went away and came back
+func (reporter *EventReporter) Forget(e *DiskEvent) {
+	reporter.mux.Lock()
+	defer reporter.mux.Unlock()
+	reporter.reportedEvents.Delete(fmt.Sprintf("%s:%s:%s", e.EventReason, e.EventType, e.Disk))
+}
@@ -2,23 +2,37 @@ package diskutils
 
 import (
 	"fmt"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 
 	"k8s.io/klog/v2"
 )
 
 var (
-	ExecCommand CommandExecutor
+	ExecCommand  CommandExecutor
+	DeviceReader BlockDeviceReader
+	Ioctl        IoctlExecutor
+	Initiators   InitiatorReader
+	Host         HostFactsReader
 )
 
 func init() {
 	ExecCommand = CmdExec{}
+	DeviceReader = SysfsReader{Root: "/"}
+	Ioctl = SysIoctl{}
+	Initiators = SysfsReader{Root: "/"}
+	Host = SysfsReader{Root: "/"}
 }
 
 const (
 	// StateSuspended is a possible value of BlockDevice.State
 	StateSuspended = "suspended"
+	// DiskByIDDir holds the persistent links of the block devices
+	DiskByIDDir = "/dev/disk/by-id"
+	// DiskByPathDir holds the links of the block devices named after their hardware path
+	DiskByPathDir = "/dev/disk/by-path"
 )
 
 type CommandExecutor interface {
@@ -58,6 +72,22 @@ type BlockDevice struct {
 	WWN        string        `json:"wwn,omitempty"`
 	Children   []BlockDevice `json:"children,omitempty"`
 	Mountpoint string        `json:"mountpoint,omitempty"`
+	Rotational bool          `json:"rota,omitempty"`
+	Serial     string        `json:"serial,omitempty"`
+	Transport  string        `json:"tran,omitempty"`
+	// LogicalSectorSize and PhysicalSectorSize are in bytes
+	LogicalSectorSize  int64 `json:"log-sec,omitempty"`
+	PhysicalSectorSize int64 `json:"phy-sec,omitempty"`
+	// ByPathLinks are all the /dev/disk/by-path link names of the device. They are only known when
+	// reading the udev database
+	ByPathLinks []string `json:"byPathLinks,omitempty"`
+	// NUMANode is the NUMA node of the controller of the device, or nil when unknown
+	NUMANode *int32 `json:"numaNode,omitempty"`
+	// IDLinks are all the /dev/disk/by-id link names of the device, PathByID is the shortest one.
+	// They are only known when reading the udev database
+	IDLinks []string `json:"idLinks,omitempty"`
+	// Holders are the kernel names of the devices built on top of this one, like multipath devices
+	Holders []string `json:"holders,omitempty"`
 }
 
 func (b *BlockDevice) BiosPartition() bool {
@@ -117,3 +147,26 @@ func GetBlockDevices() ([]byte, error) {
 	}
 	return output, err
 }
+
+// GetDeviceLinks returns the links found in dir, indexed by the kernel name of the device they point to
+func GetDeviceLinks(dir string) (map[string][]string, error) {
+	links := map[string][]string{}
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return links, nil
+	}
+	if err != nil {
+		return links, fmt.Errorf("failed to read %s: %w", dir, err)
+	}
+	for _, entry := range entries {
+		link := filepath.Join(dir, entry.Name())
+		target, err := filepath.EvalSymlinks(link)
+		if err != nil {
+			klog.Warningf("failed to resolve device link %q. Error %v", link, err)
+			continue
+		}
+		kname := filepath.Base(target)
+		links[kname] = append(links[kname], link)
+	}
+	return links, nil
+}
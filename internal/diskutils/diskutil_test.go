@@ -2,6 +2,8 @@ package diskutils
 
 import (
 	"errors"
+	"os"
+	"path/filepath"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -144,3 +146,36 @@ var _ = Describe("GetBlockDevices", func() {
 		Expect(output).To(BeEmpty())
 	})
 })
+
+var _ = Describe("GetDeviceLinks", func() {
+	It("indexes the links by the kernel name of their target", func() {
+		dir := GinkgoT().TempDir()
+		devDir := filepath.Join(dir, "dev")
+		linkDir := filepath.Join(dir, "by-path")
+		Expect(os.Mkdir(devDir, 0o755)).To(Succeed())
+		Expect(os.Mkdir(linkDir, 0o755)).To(Succeed())
+		for _, kname := range []string{"sda", "sdb"} {
+			Expect(os.WriteFile(filepath.Join(devDir, kname), nil, 0o600)).To(Succeed())
+		}
+		Expect(os.Symlink("../dev/sda", filepath.Join(linkDir, "pci-0000:00:10.0-scsi-0:0:0:0"))).To(Succeed())
+		Expect(os.Symlink("../dev/sdb", filepath.Join(linkDir, "pci-0000:3b:00.0-fc-0x5005-lun-1"))).To(Succeed())
+		Expect(os.Symlink("../dev/sdb", filepath.Join(linkDir, "pci-0000:3b:00.1-fc-0x5006-lun-1"))).To(Succeed())
+		Expect(os.Symlink("../dev/missing", filepath.Join(linkDir, "dangling"))).To(Succeed())
+
+		links, err := GetDeviceLinks(linkDir)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(links).To(Equal(map[string][]string{
+			"sda": {filepath.Join(linkDir, "pci-0000:00:10.0-scsi-0:0:0:0")},
+			"sdb": {
+				filepath.Join(linkDir, "pci-0000:3b:00.0-fc-0x5005-lun-1"),
+				filepath.Join(linkDir, "pci-0000:3b:00.1-fc-0x5006-lun-1"),
+			},
+		}))
+	})
+
+	It("returns no links when the directory does not exist", func() {
+		links, err := GetDeviceLinks(filepath.Join(GinkgoT().TempDir(), "by-id"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(links).To(BeEmpty())
+	})
+})
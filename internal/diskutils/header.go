@@ -0,0 +1,180 @@
+package diskutils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf16"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// HeaderSize is how much of the beginning of a device is read to classify it. It covers the
+	// superblocks of the common filesystems, the btrfs one being the furthest at 64KiB
+	HeaderSize = 128 * 1024
+
+	gptSignature     = "EFI PART"
+	gptEntryNameSize = 72
+	// nsdDescriptorMarker is written by Storage Scale in the NSD descriptor of the NSD v1 format
+	nsdDescriptorMarker = "NSD descriptor"
+	// nsdDescriptorArea is where the NSD v1 format keeps its descriptors
+	nsdDescriptorArea = 8 * sectorSize
+)
+
+// gpfsPartitionType is the GPT partition type GUID of the IBM General Parallel File System, used by
+// the NSD v2 format, as stored on disk
+var gpfsPartitionType = guidBytes(0x37affc90, 0xef7d, 0x4e96, [8]byte{0x91, 0xc3, 0x2d, 0x7a, 0xe0, 0x55, 0xb1, 0x74})
+
+// DeviceHeader is what the header region of a device holds
+type DeviceHeader struct {
+	// GPFS is true when the device is a Storage Scale NSD
+	GPFS bool
+	// NSDName is the name of the NSD when it could be decoded
+	NSDName string
+	// Signature is the name of a foreign signature found on the device, like xfs or LVM2_member
+	Signature string
+}
+
+// signature is a magic at a fixed offset identifying what a device holds, as blkid knows them
+type signature struct {
+	name   string
+	offset int
+	magic  []byte
+}
+
+var signatures = []signature{
+	{name: "xfs", offset: 0, magic: []byte("XFSB")},
+	{name: "crypto_LUKS", offset: 0, magic: []byte("LUKS\xba\xbe")},
+	{name: "ceph_bluestore", offset: 0, magic: []byte("bluestore block device")},
+	{name: "oracleasm", offset: 32, magic: []byte("ORCLDISK")},
+	{name: "LVM2_member", offset: 24, magic: []byte("LVM2 001")},
+	{name: "LVM2_member", offset: sectorSize + 24, magic: []byte("LVM2 001")},
+	{name: "LVM2_member", offset: 2*sectorSize + 24, magic: []byte("LVM2 001")},
+	{name: "LVM2_member", offset: 3*sectorSize + 24, magic: []byte("LVM2 001")},
+	{name: "linux_raid_member", offset: 0, magic: []byte{0xfc, 0x4e, 0x2b, 0xa9}},
+	{name: "linux_raid_member", offset: 4096, magic: []byte{0xfc, 0x4e, 0x2b, 0xa9}},
+	{name: "ext4", offset: 1080, magic: []byte{0x53, 0xef}},
+	{name: "swap", offset: 4096 - 10, magic: []byte("SWAPSPACE2")},
+	{name: "swap", offset: 65536 - 10, magic: []byte("SWAPSPACE2")},
+	{name: "iso9660", offset: 32769, magic: []byte("CD001")},
+	{name: "btrfs", offset: 65600, magic: []byte("_BHRfS_M")},
+	{name: "vfat", offset: 54, magic: []byte("FAT1")},
+	{name: "vfat", offset: 82, magic: []byte("FAT32")},
+}
+
+// ReadDeviceHeader reads the header region of a device, it is replaced in the tests
+var ReadDeviceHeader = readDeviceHeader
+
+func readDeviceHeader(path string) ([]byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer file.Close()
+	// Another node may have written to a shared LUN, do not trust the page cache. This is best effort
+	_ = unix.Fadvise(int(file.Fd()), 0, HeaderSize, unix.FADV_DONTNEED)
+	header := make([]byte, HeaderSize)
+	n, err := io.ReadFull(file, header)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, fmt.Errorf("failed to read the header of %s: %w", path, err)
+	}
+	return header[:n], nil
+}
+
+// ClassifyHeader tells whether the header region of a device holds a Storage Scale NSD, a foreign
+// signature, or nothing known
+func ClassifyHeader(header []byte) DeviceHeader {
+	// The GPT header is in the second logical block, of 512 or 4096 bytes
+	for _, blockSize := range []int{sectorSize, 4096} {
+		if hasMagic(header, blockSize, []byte(gptSignature)) {
+			if name, found := gpfsPartition(header, blockSize); found {
+				return DeviceHeader{GPFS: true, NSDName: name}
+			}
+			return DeviceHeader{Signature: "gpt"}
+		}
+	}
+	if bytes.Contains(header[:min(len(header), nsdDescriptorArea)], []byte(nsdDescriptorMarker)) {
+		return DeviceHeader{GPFS: true}
+	}
+	for _, sig := range signatures {
+		if hasMagic(header, sig.offset, sig.magic) {
+			return DeviceHeader{Signature: sig.name}
+		}
+	}
+	if hasMBRPartitions(header) {
+		return DeviceHeader{Signature: "dos"}
+	}
+	return DeviceHeader{}
+}
+
+// gpfsPartition looks for a GPFS partition in the GPT and returns its name
+func gpfsPartition(header []byte, blockSize int) (string, bool) {
+	gpt := header[blockSize:]
+	if len(gpt) < 88 {
+		return "", false
+	}
+	entriesOffset := binary.LittleEndian.Uint64(gpt[72:80]) * uint64(blockSize)
+	entryCount := binary.LittleEndian.Uint32(gpt[80:84])
+	entrySize := binary.LittleEndian.Uint32(gpt[84:88])
+	if entrySize < 56+gptEntryNameSize {
+		return "", false
+	}
+	for idx := uint64(0); idx < uint64(entryCount); idx++ {
+		start := entriesOffset + idx*uint64(entrySize)
+		// Only the entries within the header region can be checked
+		if start+uint64(entrySize) > uint64(len(header)) {
+			break
+		}
+		entry := header[start : start+uint64(entrySize)]
+		if bytes.Equal(entry[:16], gpfsPartitionType) {
+			return decodeUTF16(entry[56 : 56+gptEntryNameSize]), true
+		}
+	}
+	return "", false
+}
+
+// hasMBRPartitions returns true when the device has an MBR with at least one partition
+func hasMBRPartitions(header []byte) bool {
+	if !hasMagic(header, 510, []byte{0x55, 0xaa}) {
+		return false
+	}
+	for entry := 446; entry < 510; entry += 16 {
+		// The partition type is the fifth byte of the entry
+		if header[entry+4] != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func hasMagic(header []byte, offset int, magic []byte) bool {
+	return len(header) >= offset+len(magic) && bytes.Equal(header[offset:offset+len(magic)], magic)
+}
+
+// decodeUTF16 decodes a NUL padded UTF-16LE string
+func decodeUTF16(b []byte) string {
+	units := make([]uint16, 0, len(b)/2)
+	for idx := 0; idx+1 < len(b); idx += 2 {
+		unit := binary.LittleEndian.Uint16(b[idx:])
+		if unit == 0 {
+			break
+		}
+		units = append(units, unit)
+	}
+	return strings.TrimSpace(string(utf16.Decode(units)))
+}
+
+// guidBytes returns a GUID as stored in a GPT, with its first three fields little endian
+func guidBytes(a uint32, b, c uint16, d [8]byte) []byte {
+	guid := make([]byte, 16)
+	binary.LittleEndian.PutUint32(guid[0:4], a)
+	binary.LittleEndian.PutUint16(guid[4:6], b)
+	binary.LittleEndian.PutUint16(guid[6:8], c)
+	copy(guid[8:], d[:])
+	return guid
+}
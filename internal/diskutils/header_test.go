@@ -0,0 +1,69 @@
+package diskutils
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"unicode/utf16"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Device Header", func() {
+	// withMagic returns an empty header with the magic at the offset
+	withMagic := func(offset int, magic string) []byte {
+		header := make([]byte, HeaderSize)
+		copy(header[offset:], magic)
+		return header
+	}
+
+	// gptHeader returns a GPT with a single partition of the type and name, for the logical block size
+	gptHeader := func(blockSize int, partitionType []byte, name string) []byte {
+		header := withMagic(blockSize, gptSignature)
+		binary.LittleEndian.PutUint64(header[blockSize+72:], 2)
+		binary.LittleEndian.PutUint32(header[blockSize+80:], 128)
+		binary.LittleEndian.PutUint32(header[blockSize+84:], 128)
+		entry := header[2*blockSize:]
+		copy(entry, partitionType)
+		for idx, unit := range utf16.Encode([]rune(name)) {
+			binary.LittleEndian.PutUint16(entry[56+2*idx:], unit)
+		}
+		return header
+	}
+
+	DescribeTable("classifies the header",
+		func(header []byte, expected DeviceHeader) {
+			Expect(ClassifyHeader(header)).To(Equal(expected))
+		},
+		Entry("empty", make([]byte, HeaderSize), DeviceHeader{}),
+		Entry("short read", []byte{0, 1, 2}, DeviceHeader{}),
+		Entry("NSD v2 GPT partition", gptHeader(512, gpfsPartitionType, "nsd_fs1_01"), DeviceHeader{GPFS: true, NSDName: "nsd_fs1_01"}),
+		Entry("NSD v2 on a 4Kn disk", gptHeader(4096, gpfsPartitionType, ""), DeviceHeader{GPFS: true}),
+		Entry("NSD v1 descriptor", withMagic(2*512, "NSD descriptor for /dev/dm-2 created by GPFS"), DeviceHeader{GPFS: true}),
+		Entry("other GPT partition", gptHeader(512, make([]byte, 16), "data"), DeviceHeader{Signature: "gpt"}),
+		Entry("xfs", withMagic(0, "XFSB"), DeviceHeader{Signature: "xfs"}),
+		Entry("LVM", withMagic(512+24, "LVM2 001"), DeviceHeader{Signature: "LVM2_member"}),
+		Entry("ext4", withMagic(1080, "\x53\xef"), DeviceHeader{Signature: "ext4"}),
+		Entry("swap", withMagic(4086, "SWAPSPACE2"), DeviceHeader{Signature: "swap"}),
+		Entry("btrfs", withMagic(65600, "_BHRfS_M"), DeviceHeader{Signature: "btrfs"}),
+		Entry("MBR", func() []byte {
+			header := withMagic(510, "\x55\xaa")
+			header[446+4] = 0x83
+			return header
+		}(), DeviceHeader{Signature: "dos"}),
+		Entry("MBR without partitions", withMagic(510, "\x55\xaa"), DeviceHeader{}),
+	)
+
+	It("reads the header of a device", func() {
+		path := filepath.Join(GinkgoT().TempDir(), "disk")
+		Expect(os.WriteFile(path, withMagic(0, "XFSB")[:4096], 0o600)).To(Succeed())
+		header, err := ReadDeviceHeader(path)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(header).To(HaveLen(4096))
+		Expect(ClassifyHeader(header)).To(Equal(DeviceHeader{Signature: "xfs"}))
+
+		_, err = ReadDeviceHeader(filepath.Join(GinkgoT().TempDir(), "missing"))
+		Expect(err).To(MatchError(ContainSubstring("failed to open")))
+	})
+})
@@ -0,0 +1,186 @@
+package diskutils
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	kernelReleasePath = "proc/sys/kernel/osrelease"
+	modulesPath       = "proc/modules"
+	memInfoPath       = "proc/meminfo"
+	procPath          = "proc"
+	cpuOnlinePath     = "sys/devices/system/cpu/online"
+	hugePagesPath     = "sys/kernel/mm/hugepages"
+	efiPath           = "sys/firmware/efi"
+	// secureBootVariable is the SecureBoot EFI variable of the global variable GUID. efivarfs prefixes
+	// the value with its 4 bytes of attributes
+	secureBootVariable = "efivars/SecureBoot-8be4df61-93ca-11d2-aa0d-00e098032b8c"
+	// hostRootPath is the root of the host, seen through its init process when the daemon runs in the
+	// PID namespace of the host. efivarfs is not always mounted in the sysfs of a container
+	hostRootPath   = "proc/1/root"
+	multipathdComm = "multipathd"
+)
+
+// HostFactsReader reads the facts of the host that matter to Storage Scale
+type HostFactsReader interface {
+	ReadHostFacts() HostFacts
+}
+
+// HostFacts are the kernel, firmware and resources of the node. The zero values are left for facts that
+// could not be read
+type HostFacts struct {
+	KernelVersion string
+	// EFI is true when the node booted with EFI
+	EFI bool
+	// SecureBoot is the SecureBoot EFI variable, nil when the node did not boot with EFI or the
+	// variable could not be read
+	SecureBoot       *bool
+	MultipathdActive bool
+	HugePages        []HugePageSize
+	// MemoryTotal is in bytes
+	MemoryTotal   int64
+	CPUCount      int32
+	LoadedModules []string
+}
+
+// HugePageSize is the configuration of the hugepages of a size, as found in /sys/kernel/mm/hugepages
+type HugePageSize struct {
+	// SizeKB is the size of a page in KiB
+	SizeKB int64
+	Total  int64
+	Free   int64
+}
+
+// ReadHostFacts reads the facts of the host from /proc and /sys. The processes of /proc are the ones of
+// the host only when the daemon runs with hostPID, as set in the devicefinder-discovery DaemonSet. Without
+// it, only the processes of the container are seen and multipathd is reported as inactive
+func (r SysfsReader) ReadHostFacts() HostFacts {
+	facts := HostFacts{
+		KernelVersion:    r.readAttr(r.Root, kernelReleasePath),
+		MultipathdActive: r.processRunning(multipathdComm),
+		HugePages:        r.readHugePages(),
+		MemoryTotal:      r.readMemoryTotal(),
+		CPUCount:         countCPUs(r.readAttr(r.Root, cpuOnlinePath)),
+		LoadedModules:    r.readModules(),
+	}
+	facts.EFI = r.exists(filepath.Join(r.Root, efiPath))
+	if facts.EFI {
+		facts.SecureBoot = r.readSecureBoot()
+	}
+	return facts
+}
+
+// readSecureBoot returns the value of the SecureBoot EFI variable, from the sysfs of the container or,
+// without efivarfs there, from the one of the host
+func (r SysfsReader) readSecureBoot() *bool {
+	for _, dir := range []string{efiPath, filepath.Join(hostRootPath, efiPath)} {
+		content, err := os.ReadFile(filepath.Join(r.Root, dir, secureBootVariable))
+		if err != nil || len(content) < 5 {
+			continue
+		}
+		enabled := content[4] == 1
+		return &enabled
+	}
+	return nil
+}
+
+// processRunning returns true when a process of the given command name runs
+func (r SysfsReader) processRunning(comm string) bool {
+	procDir := filepath.Join(r.Root, procPath)
+	for _, name := range r.listDir(procDir) {
+		if _, err := strconv.Atoi(name); err != nil {
+			continue
+		}
+		if r.readAttr(filepath.Join(procDir, name), "comm") == comm {
+			return true
+		}
+	}
+	return false
+}
+
+// readHugePages returns the hugepages of each size, like hugepages-2048kB, sorted by size
+func (r SysfsReader) readHugePages() []HugePageSize {
+	hugePagesDir := filepath.Join(r.Root, hugePagesPath)
+	hugePages := []HugePageSize{}
+	for _, name := range r.listDir(hugePagesDir) {
+		size, found := strings.CutPrefix(name, "hugepages-")
+		if !found {
+			continue
+		}
+		sizeKB, err := strconv.ParseInt(strings.TrimSuffix(size, "kB"), 10, 64)
+		if err != nil {
+			continue
+		}
+		sizePath := filepath.Join(hugePagesDir, name)
+		total, _ := strconv.ParseInt(r.readAttr(sizePath, "nr_hugepages"), 10, 64)
+		free, _ := strconv.ParseInt(r.readAttr(sizePath, "free_hugepages"), 10, 64)
+		hugePages = append(hugePages, HugePageSize{SizeKB: sizeKB, Total: total, Free: free})
+	}
+	sort.Slice(hugePages, func(i, j int) bool { return hugePages[i].SizeKB < hugePages[j].SizeKB })
+	return hugePages
+}
+
+// readMemoryTotal returns the MemTotal of /proc/meminfo in bytes
+func (r SysfsReader) readMemoryTotal() int64 {
+	file, err := os.Open(filepath.Join(r.Root, memInfoPath))
+	if err != nil {
+		return 0
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || fields[0] != "MemTotal:" {
+			continue
+		}
+		kb, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			return 0
+		}
+		return kb * 1024
+	}
+	return 0
+}
+
+// readModules returns the sorted names of the loaded kernel modules
+func (r SysfsReader) readModules() []string {
+	file, err := os.Open(filepath.Join(r.Root, modulesPath))
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+	modules := []string{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if fields := strings.Fields(scanner.Text()); len(fields) > 0 {
+			modules = append(modules, fields[0])
+		}
+	}
+	sort.Strings(modules)
+	return modules
+}
+
+// countCPUs counts the CPUs of a kernel CPU list, like 0-3,8,10-11
+func countCPUs(cpuList string) int32 {
+	var count int32
+	for _, cpuRange := range strings.Split(cpuList, ",") {
+		first, last, isRange := strings.Cut(strings.TrimSpace(cpuRange), "-")
+		start, err := strconv.Atoi(first)
+		if err != nil {
+			continue
+		}
+		end := start
+		if isRange {
+			if end, err = strconv.Atoi(last); err != nil || end < start {
+				continue
+			}
+		}
+		count += int32(end - start + 1)
+	}
+	return count
+}
@@ -0,0 +1,101 @@
+package diskutils
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("HostFacts", func() {
+	var root string
+
+	write := func(path, content string) {
+		path = filepath.Join(root, path)
+		Expect(os.MkdirAll(filepath.Dir(path), 0o755)).To(Succeed())
+		Expect(os.WriteFile(path, []byte(content), 0o600)).To(Succeed())
+	}
+
+	secureBoot := func(dir string, value byte) {
+		write(filepath.Join(dir, "efivars/SecureBoot-8be4df61-93ca-11d2-aa0d-00e098032b8c"), string([]byte{0x06, 0, 0, 0, value}))
+	}
+
+	enabled := func(value bool) *bool {
+		return &value
+	}
+
+	BeforeEach(func() {
+		root = GinkgoT().TempDir()
+	})
+
+	It("reads the facts of the host", func() {
+		write("proc/sys/kernel/osrelease", "5.14.0-427.50.1.el9_4.x86_64\n")
+		write("proc/1/comm", "systemd\n")
+		write("proc/1234/comm", "multipathd\n")
+		write("proc/self/comm", "devicefinder\n")
+		write("proc/meminfo", "MemTotal:       32761800 kB\nMemFree:        12345678 kB\n")
+		write("proc/modules", "nvme 61440 0 - Live 0x0000000000000000\n"+
+			"mmfs26 2244608 1 mmfslinux, Live 0x0000000000000000 (OE)\n"+
+			"dm_multipath 45056 3 dm_round_robin, Live 0x0000000000000000\n")
+		write("sys/devices/system/cpu/online", "0-3,8,10-11\n")
+		write("sys/kernel/mm/hugepages/hugepages-2048kB/nr_hugepages", "512\n")
+		write("sys/kernel/mm/hugepages/hugepages-2048kB/free_hugepages", "500\n")
+		write("sys/kernel/mm/hugepages/hugepages-1048576kB/nr_hugepages", "0\n")
+		write("sys/kernel/mm/hugepages/hugepages-1048576kB/free_hugepages", "0\n")
+		secureBoot("sys/firmware/efi", 1)
+
+		Expect(SysfsReader{Root: root}.ReadHostFacts()).To(Equal(HostFacts{
+			KernelVersion:    "5.14.0-427.50.1.el9_4.x86_64",
+			EFI:              true,
+			SecureBoot:       enabled(true),
+			MultipathdActive: true,
+			HugePages: []HugePageSize{
+				{SizeKB: 2048, Total: 512, Free: 500},
+				{SizeKB: 1048576},
+			},
+			MemoryTotal:   32761800 * 1024,
+			CPUCount:      7,
+			LoadedModules: []string{"dm_multipath", "mmfs26", "nvme"},
+		}))
+	})
+
+	It("reads the SecureBoot variable of the host when efivarfs is not mounted in the container", func() {
+		write("sys/firmware/efi/runtime", "")
+		secureBoot("proc/1/root/sys/firmware/efi", 0)
+
+		facts := SysfsReader{Root: root}.ReadHostFacts()
+		Expect(facts.EFI).To(BeTrue())
+		Expect(facts.SecureBoot).To(Equal(enabled(false)))
+		Expect(facts.MultipathdActive).To(BeFalse())
+	})
+
+	It("only sees the processes of the container without the PID namespace of the host", func() {
+		// PID 1 is the daemon itself and /proc/1/root is the root of the container
+		write("proc/1/comm", "devicefinder\n")
+		write("proc/1/root/etc/hostname", "devicefinder-discovery-x7k2p\n")
+		write("sys/firmware/efi/runtime", "")
+
+		facts := SysfsReader{Root: root}.ReadHostFacts()
+		Expect(facts.MultipathdActive).To(BeFalse())
+		Expect(facts.EFI).To(BeTrue())
+		Expect(facts.SecureBoot).To(BeNil())
+	})
+
+	It("reports a node that did not boot with EFI", func() {
+		facts := SysfsReader{Root: root}.ReadHostFacts()
+		Expect(facts.EFI).To(BeFalse())
+		Expect(facts.SecureBoot).To(BeNil())
+		Expect(facts.HugePages).To(BeEmpty())
+	})
+
+	DescribeTable("counts the CPUs of a CPU list",
+		func(cpuList string, expected int32) {
+			Expect(countCPUs(cpuList)).To(Equal(expected))
+		},
+		Entry("single CPU", "0", int32(1)),
+		Entry("range", "0-15", int32(16)),
+		Entry("ranges and CPUs", "0-3,8,10-11", int32(7)),
+		Entry("empty", "", int32(0)),
+	)
+})
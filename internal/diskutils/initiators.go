@@ -0,0 +1,102 @@
+package diskutils
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// iscsiInitiatorNamePath is the configuration of the iSCSI initiator of the host, seen through the
+	// root of its init process
+	iscsiInitiatorNamePath = "proc/1/root/etc/iscsi/initiatorname.iscsi"
+	// unknownFCName is reported by the kernel for the names of a port that is not logged in a fabric
+	unknownFCName = "0xffffffffffffffff"
+)
+
+// InitiatorReader lists the SAN initiators of the node
+type InitiatorReader interface {
+	ReadInitiators() SANInitiators
+}
+
+// SANInitiators are the Fibre Channel ports and the iSCSI initiator of the node
+type SANInitiators struct {
+	FCHosts            []FCHost
+	ISCSIInitiatorName string
+}
+
+// FCHost is a Fibre Channel HBA port, as found in /sys/class/fc_host. The names are formatted like the
+// fabric switches display them, for eg. 10:00:00:90:fa:8c:1f:52
+type FCHost struct {
+	Name       string
+	PortName   string
+	NodeName   string
+	PortState  string
+	Speed      string
+	FabricName string
+}
+
+// ReadInitiators reads the Fibre Channel ports from /sys/class/fc_host, and the iSCSI initiator name from
+// the configuration of the host or, without it, from /sys/class/iscsi_host
+func (r SysfsReader) ReadInitiators() SANInitiators {
+	initiators := SANInitiators{FCHosts: []FCHost{}}
+	fcHostsPath := filepath.Join(r.Root, sysClassPath, "fc_host")
+	for _, name := range r.listDir(fcHostsPath) {
+		hostPath := filepath.Join(fcHostsPath, name)
+		initiators.FCHosts = append(initiators.FCHosts, FCHost{
+			Name:       name,
+			PortName:   fcName(r.readAttr(hostPath, "port_name")),
+			NodeName:   fcName(r.readAttr(hostPath, "node_name")),
+			PortState:  r.readAttr(hostPath, "port_state"),
+			Speed:      r.readAttr(hostPath, "speed"),
+			FabricName: fcName(r.readAttr(hostPath, "fabric_name")),
+		})
+	}
+
+	initiators.ISCSIInitiatorName = r.readISCSIInitiatorName()
+	if initiators.ISCSIInitiatorName == "" {
+		iscsiHostsPath := filepath.Join(r.Root, sysClassPath, "iscsi_host")
+		for _, name := range r.listDir(iscsiHostsPath) {
+			if initiatorName := r.readAttr(filepath.Join(iscsiHostsPath, name), "initiatorname"); initiatorName != "" {
+				initiators.ISCSIInitiatorName = initiatorName
+				break
+			}
+		}
+	}
+	return initiators
+}
+
+// readISCSIInitiatorName returns the InitiatorName of the iSCSI configuration of the host
+func (r SysfsReader) readISCSIInitiatorName() string {
+	file, err := os.Open(filepath.Join(r.Root, iscsiInitiatorNamePath))
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if name, found := strings.CutPrefix(line, "InitiatorName="); found {
+			return strings.TrimSpace(name)
+		}
+	}
+	return ""
+}
+
+// fcName formats a name of the Fibre Channel sysfs class, like 0x10000090fa8c1f52, as colon separated
+// bytes. The names of a port that is not logged in a fabric are left empty
+func fcName(value string) string {
+	hex := strings.ToLower(strings.TrimPrefix(value, "0x"))
+	if value == "" || value == unknownFCName || strings.Trim(hex, "0") == "" {
+		return ""
+	}
+	if len(hex)%2 == 1 {
+		hex = "0" + hex
+	}
+	bytes := make([]string, 0, len(hex)/2)
+	for idx := 0; idx < len(hex); idx += 2 {
+		bytes = append(bytes, hex[idx:idx+2])
+	}
+	return strings.Join(bytes, ":")
+}
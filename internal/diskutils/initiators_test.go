@@ -0,0 +1,64 @@
+package diskutils
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Initiators", func() {
+	var root string
+
+	write := func(path, content string) {
+		path = filepath.Join(root, path)
+		Expect(os.MkdirAll(filepath.Dir(path), 0o755)).To(Succeed())
+		Expect(os.WriteFile(path, []byte(content), 0o600)).To(Succeed())
+	}
+
+	fcHost := func(name, portName, portState, speed, fabricName string) {
+		write("sys/class/fc_host/"+name+"/port_name", portName+"\n")
+		write("sys/class/fc_host/"+name+"/node_name", "0x20000090fa8c1f52\n")
+		write("sys/class/fc_host/"+name+"/port_state", portState+"\n")
+		write("sys/class/fc_host/"+name+"/speed", speed+"\n")
+		write("sys/class/fc_host/"+name+"/fabric_name", fabricName+"\n")
+	}
+
+	BeforeEach(func() {
+		root = GinkgoT().TempDir()
+	})
+
+	It("reads the Fibre Channel ports and the iSCSI initiator name", func() {
+		fcHost("host1", "0x10000090fa8c1f52", "Online", "16 Gbit", "0x100000051e90e0b4")
+		fcHost("host2", "0x10000090fa8c1f53", "Linkdown", "unknown", "0xffffffffffffffff")
+		write("proc/1/root/etc/iscsi/initiatorname.iscsi", "# Generated\nInitiatorName=iqn.1994-05.com.redhat:worker-0\n")
+		write("sys/class/iscsi_host/host3/initiatorname", "iqn.1994-05.com.redhat:other\n")
+
+		Expect(SysfsReader{Root: root}.ReadInitiators()).To(Equal(SANInitiators{
+			FCHosts: []FCHost{
+				{
+					Name: "host1", PortName: "10:00:00:90:fa:8c:1f:52", NodeName: "20:00:00:90:fa:8c:1f:52",
+					PortState: "Online", Speed: "16 Gbit", FabricName: "10:00:00:05:1e:90:e0:b4",
+				},
+				{
+					Name: "host2", PortName: "10:00:00:90:fa:8c:1f:53", NodeName: "20:00:00:90:fa:8c:1f:52",
+					PortState: "Linkdown", Speed: "unknown",
+				},
+			},
+			ISCSIInitiatorName: "iqn.1994-05.com.redhat:worker-0",
+		}))
+	})
+
+	It("falls back to the initiator name of the iSCSI hosts", func() {
+		write("sys/class/iscsi_host/host3/initiatorname", "iqn.1994-05.com.redhat:worker-1\n")
+		Expect(SysfsReader{Root: root}.ReadInitiators()).To(Equal(SANInitiators{
+			FCHosts:            []FCHost{},
+			ISCSIInitiatorName: "iqn.1994-05.com.redhat:worker-1",
+		}))
+	})
+
+	It("reports a node without SAN initiators", func() {
+		Expect(SysfsReader{Root: root}.ReadInitiators()).To(Equal(SANInitiators{FCHosts: []FCHost{}}))
+	})
+})
@@ -0,0 +1,175 @@
+package diskutils
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// sgIO is the ioctl sending a SCSI command to a device, SG_IO of <scsi/sg.h>
+	sgIO = 0x2285
+	// sgDxferFromDev is the SG_DXFER_FROM_DEV direction of a command reading data from the device
+	sgDxferFromDev = -3
+	// sgDriverSense is the driver status telling that the sense buffer was filled, which is not an error
+	sgDriverSense = 0x08
+	// sgTimeout bounds the time a LUN takes to answer, in milliseconds
+	sgTimeout = 5000
+
+	persistentReserveIn = 0x5e
+	reportCapabilities  = 0x02
+	capabilitiesLength  = 8
+	senseLength         = 32
+
+	scsiStatusGood           = 0x00
+	scsiStatusCheckCondition = 0x02
+	senseKeyIllegalRequest   = 0x05
+)
+
+// ErrPersistentReservationUnsupported is returned by ProbePersistentReservation for a LUN that rejects
+// the PERSISTENT RESERVE IN command
+var ErrPersistentReservationUnsupported = errors.New("persistent reservations are not supported")
+
+// persistentReservationTypes are the bits of the type mask of the REPORT CAPABILITIES, from the most to
+// the least significant bit of its two bytes
+var persistentReservationTypes = []struct {
+	mask uint16
+	name string
+}{
+	{mask: 0x8000, name: "WriteExclusiveAllRegistrants"},
+	{mask: 0x4000, name: "ExclusiveAccessRegistrantsOnly"},
+	{mask: 0x2000, name: "WriteExclusiveRegistrantsOnly"},
+	{mask: 0x0800, name: "ExclusiveAccess"},
+	{mask: 0x0200, name: "WriteExclusive"},
+	{mask: 0x0001, name: "ExclusiveAccessAllRegistrants"},
+}
+
+// IoctlExecutor issues an ioctl on an open device
+type IoctlExecutor interface {
+	Ioctl(fd uintptr, request uintptr, arg unsafe.Pointer) error
+}
+
+// SysIoctl issues the ioctls with the ioctl system call
+type SysIoctl struct{}
+
+func (SysIoctl) Ioctl(fd uintptr, request uintptr, arg unsafe.Pointer) error {
+	if _, _, errno := unix.Syscall(unix.SYS_IOCTL, fd, request, uintptr(arg)); errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// sgIOHdr is the sg_io_hdr of <scsi/sg.h>
+type sgIOHdr struct {
+	interfaceID    int32
+	dxferDirection int32
+	cmdLen         uint8
+	mxSbLen        uint8
+	iovecCount     uint16
+	dxferLen       uint32
+	dxferp         unsafe.Pointer
+	cmdp           unsafe.Pointer
+	sbp            unsafe.Pointer
+	timeout        uint32
+	flags          uint32
+	packID         int32
+	usrPtr         unsafe.Pointer
+	status         uint8
+	maskedStatus   uint8
+	msgStatus      uint8
+	sbLenWr        uint8
+	hostStatus     uint16
+	driverStatus   uint16
+	resid          int32
+	duration       uint32
+	info           uint32
+}
+
+// ProbePersistentReservation sends the PERSISTENT RESERVE IN command with the REPORT CAPABILITIES
+// service action to a device, and returns the reservation types it supports. The command only reads
+// the capabilities, it neither registers a key nor reserves the device
+func ProbePersistentReservation(path string) ([]string, error) {
+	file, err := os.OpenFile(path, os.O_RDONLY|unix.O_NONBLOCK, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer file.Close()
+
+	cdb := make([]byte, 10)
+	cdb[0] = persistentReserveIn
+	cdb[1] = reportCapabilities
+	binary.BigEndian.PutUint16(cdb[7:9], capabilitiesLength)
+	data := make([]byte, capabilitiesLength)
+	sense := make([]byte, senseLength)
+
+	// The kernel reads the buffers referenced by the header, they must not move during the call
+	var pinner runtime.Pinner
+	defer pinner.Unpin()
+	pinner.Pin(&cdb[0])
+	pinner.Pin(&data[0])
+	pinner.Pin(&sense[0])
+	hdr := sgIOHdr{
+		interfaceID:    'S',
+		dxferDirection: sgDxferFromDev,
+		cmdLen:         uint8(len(cdb)),
+		mxSbLen:        uint8(len(sense)),
+		dxferLen:       uint32(len(data)),
+		dxferp:         unsafe.Pointer(&data[0]),
+		cmdp:           unsafe.Pointer(&cdb[0]),
+		sbp:            unsafe.Pointer(&sense[0]),
+		timeout:        sgTimeout,
+	}
+	pinner.Pin(&hdr)
+	if err := Ioctl.Ioctl(file.Fd(), sgIO, unsafe.Pointer(&hdr)); err != nil {
+		return nil, fmt.Errorf("failed to send PERSISTENT RESERVE IN to %s: %w", path, err)
+	}
+	if hdr.hostStatus != 0 || hdr.driverStatus&0x0f&^sgDriverSense != 0 {
+		return nil, fmt.Errorf("PERSISTENT RESERVE IN to %s failed with host status %#x and driver status %#x",
+			path, hdr.hostStatus, hdr.driverStatus)
+	}
+	switch hdr.status {
+	case scsiStatusGood:
+	case scsiStatusCheckCondition:
+		if senseKey(sense[:hdr.sbLenWr]) == senseKeyIllegalRequest {
+			return nil, ErrPersistentReservationUnsupported
+		}
+		return nil, fmt.Errorf("PERSISTENT RESERVE IN to %s failed with sense key %#x", path, senseKey(sense[:hdr.sbLenWr]))
+	default:
+		return nil, fmt.Errorf("PERSISTENT RESERVE IN to %s failed with status %#x", path, hdr.status)
+	}
+	received := min(max(len(data)-int(hdr.resid), 0), len(data))
+	return parseCapabilities(data[:received]), nil
+}
+
+// parseCapabilities returns the reservation types of the REPORT CAPABILITIES parameter data. The type
+// mask is only valid when the TMV bit is set
+func parseCapabilities(data []byte) []string {
+	types := []string{}
+	if len(data) < capabilitiesLength || data[3]&0x80 == 0 {
+		return types
+	}
+	mask := binary.BigEndian.Uint16(data[4:6])
+	for _, reservationType := range persistentReservationTypes {
+		if mask&reservationType.mask != 0 {
+			types = append(types, reservationType.name)
+		}
+	}
+	return types
+}
+
+// senseKey returns the sense key of fixed or descriptor format sense data
+func senseKey(sense []byte) byte {
+	switch {
+	case len(sense) > 2 && (sense[0]&0x7f == 0x70 || sense[0]&0x7f == 0x71):
+		return sense[2] & 0x0f
+	case len(sense) > 1 && (sense[0]&0x7f == 0x72 || sense[0]&0x7f == 0x73):
+		return sense[1] & 0x0f
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,112 @@
+package diskutils
+
+import (
+	"os"
+	"path/filepath"
+	"unsafe"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"golang.org/x/sys/unix"
+)
+
+// fakeIoctl answers the SG_IO ioctl like a LUN would
+type fakeIoctl struct {
+	err    error
+	status uint8
+	sense  []byte
+	data   []byte
+	cdb    []byte
+}
+
+func (f *fakeIoctl) Ioctl(_ uintptr, request uintptr, arg unsafe.Pointer) error {
+	Expect(request).To(Equal(uintptr(sgIO)))
+	if f.err != nil {
+		return f.err
+	}
+	hdr := (*sgIOHdr)(arg)
+	Expect(hdr.interfaceID).To(Equal(int32('S')))
+	Expect(hdr.dxferDirection).To(Equal(int32(sgDxferFromDev)))
+	f.cdb = append([]byte{}, unsafe.Slice((*byte)(hdr.cmdp), hdr.cmdLen)...)
+	hdr.status = f.status
+	if f.sense != nil {
+		hdr.driverStatus = sgDriverSense
+		hdr.sbLenWr = uint8(copy(unsafe.Slice((*byte)(hdr.sbp), hdr.mxSbLen), f.sense))
+	}
+	copied := copy(unsafe.Slice((*byte)(hdr.dxferp), hdr.dxferLen), f.data)
+	hdr.resid = int32(hdr.dxferLen) - int32(copied)
+	return nil
+}
+
+var _ = Describe("Persistent Reservation", func() {
+	var (
+		path  string
+		ioctl *fakeIoctl
+	)
+
+	BeforeEach(func() {
+		path = filepath.Join(GinkgoT().TempDir(), "sdb")
+		Expect(os.WriteFile(path, nil, 0o600)).To(Succeed())
+		ioctl = &fakeIoctl{}
+		previous := Ioctl
+		DeferCleanup(func() { Ioctl = previous })
+		Ioctl = ioctl
+	})
+
+	It("reports the reservation types of a LUN", func() {
+		// TMV set, Write Exclusive, Exclusive Access, their Registrants Only variants and All Registrants
+		ioctl.data = []byte{0x00, 0x08, 0x1d, 0x81, 0x6a, 0x01, 0x00, 0x00}
+		types, err := ProbePersistentReservation(path)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(types).To(Equal([]string{
+			"ExclusiveAccessRegistrantsOnly", "WriteExclusiveRegistrantsOnly", "ExclusiveAccess", "WriteExclusive",
+			"ExclusiveAccessAllRegistrants",
+		}))
+		Expect(ioctl.cdb).To(Equal([]byte{0x5e, 0x02, 0, 0, 0, 0, 0, 0x00, 0x08, 0}))
+	})
+
+	It("ignores the type mask when it is not valid", func() {
+		ioctl.data = []byte{0x00, 0x08, 0x1d, 0x01, 0x6a, 0x01, 0x00, 0x00}
+		types, err := ProbePersistentReservation(path)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(types).To(BeEmpty())
+	})
+
+	It("ignores a short answer", func() {
+		ioctl.data = []byte{0x00, 0x08, 0x1d, 0x81}
+		types, err := ProbePersistentReservation(path)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(types).To(BeEmpty())
+	})
+
+	DescribeTable("tells when a LUN rejects the command",
+		func(sense []byte) {
+			ioctl.status = scsiStatusCheckCondition
+			ioctl.sense = sense
+			_, err := ProbePersistentReservation(path)
+			Expect(err).To(MatchError(ErrPersistentReservationUnsupported))
+		},
+		Entry("fixed format sense", []byte{0x70, 0x00, 0x05, 0, 0, 0, 0, 0x0a, 0, 0, 0, 0, 0x20, 0x00}),
+		Entry("descriptor format sense", []byte{0x72, 0x05, 0x20, 0x00, 0, 0, 0, 0}),
+	)
+
+	It("fails on other check conditions", func() {
+		ioctl.status = scsiStatusCheckCondition
+		// NOT READY
+		ioctl.sense = []byte{0x70, 0x00, 0x02, 0, 0, 0, 0, 0x0a}
+		_, err := ProbePersistentReservation(path)
+		Expect(err).To(MatchError(ContainSubstring("failed with sense key 0x2")))
+		Expect(err).ToNot(MatchError(ErrPersistentReservationUnsupported))
+	})
+
+	It("fails when the device does not support SG_IO", func() {
+		ioctl.err = unix.ENOTTY
+		_, err := ProbePersistentReservation(path)
+		Expect(err).To(MatchError(unix.ENOTTY))
+	})
+
+	It("fails on a missing device", func() {
+		_, err := ProbePersistentReservation(filepath.Join(GinkgoT().TempDir(), "missing"))
+		Expect(err).To(MatchError(ContainSubstring("failed to open")))
+	})
+})
@@ -0,0 +1,404 @@
+package diskutils
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	sysBlockPath       = "sys/block"
+	udevDataPath       = "run/udev/data"
+	mountInfoPath      = "proc/1/mountinfo"
+	swapsPath          = "proc/swaps"
+	sectorSize         = 512
+	ramDiskMajor       = "1"
+	scsiTypeROM        = "5"
+	swapMountpoint     = "[SWAP]"
+	diskByIDLinkPath   = "disk/by-id/"
+	diskByPathLinkPath = "disk/by-path/"
+	sysDevicesPath     = "sys/devices"
+	sysClassPath       = "sys/class"
+)
+
+// scsiHostTransports are the transports identified by the class of the SCSI host of a device
+var scsiHostTransports = []string{"fc", "iscsi", "sas"}
+
+// BlockDeviceReader lists the block devices of the node as a tree: the partitions and the
+// devices built on top of a device, like multipath devices, are its children
+type BlockDeviceReader interface {
+	ReadBlockDevices() ([]BlockDevice, error)
+}
+
+// LsblkReader lists the block devices with lsblk
+type LsblkReader struct{}
+
+// ReadBlockDevices runs lsblk and unmarshals its output
+func (LsblkReader) ReadBlockDevices() ([]BlockDevice, error) {
+	output, err := GetBlockDevices()
+	if err != nil {
+		return nil, err
+	}
+	list := BlockDeviceList{}
+	if err := json.Unmarshal(output, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse lsblk output: %w", err)
+	}
+	return list.BlockDevices, nil
+}
+
+// SysfsReader lists the block devices from /sys/block and the udev database in /run/udev/data.
+// Root is prepended to those paths, so the reader can be pointed at a fake tree
+type SysfsReader struct {
+	Root string
+}
+
+// ReadBlockDevices returns the devices that are not built on top of another device, with their
+// partitions and holders as children. RAM disks are skipped, like lsblk does by default
+func (r SysfsReader) ReadBlockDevices() ([]BlockDevice, error) {
+	entries, err := os.ReadDir(filepath.Join(r.Root, sysBlockPath))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list block devices: %w", err)
+	}
+	mountpoints, err := r.readMountpoints()
+	if err != nil {
+		return nil, err
+	}
+
+	devices := []BlockDevice{}
+	for _, entry := range entries {
+		kname := entry.Name()
+		sysPath := filepath.Join(r.Root, sysBlockPath, kname)
+		if len(r.listDir(filepath.Join(sysPath, "slaves"))) > 0 {
+			// Shown as a child of the devices it is built on
+			continue
+		}
+		if major, _, _ := strings.Cut(r.readAttr(sysPath, "dev"), ":"); major == ramDiskMajor {
+			continue
+		}
+		device, err := r.readDevice(kname, sysPath, mountpoints)
+		if err != nil {
+			return nil, err
+		}
+		devices = append(devices, device)
+	}
+	return devices, nil
+}
+
+// readDevice builds the device found in sysPath and its children
+func (r SysfsReader) readDevice(kname, sysPath string, mountpoints map[string]string) (BlockDevice, error) {
+	devNumber := r.readAttr(sysPath, "dev")
+	if devNumber == "" {
+		return BlockDevice{}, fmt.Errorf("failed to read the device number of %s", kname)
+	}
+	udev, err := r.readUdevData(devNumber)
+	if err != nil {
+		return BlockDevice{}, err
+	}
+	size, _ := strconv.ParseInt(r.readAttr(sysPath, "size"), 10, 64)
+	logicalSectorSize, _ := strconv.ParseInt(r.readAttr(sysPath, "queue/logical_block_size"), 10, 64)
+	physicalSectorSize, _ := strconv.ParseInt(r.readAttr(sysPath, "queue/physical_block_size"), 10, 64)
+
+	device := BlockDevice{
+		Name:       kname,
+		KName:      kname,
+		Path:       "/dev/" + kname,
+		Size:       size * sectorSize,
+		ReadOnly:   r.readAttr(sysPath, "ro") == "1",
+		Removable:  r.readAttr(sysPath, "removable") == "1",
+		Rotational: r.readAttr(sysPath, "queue/rotational") == "1",
+		Vendor:     strings.TrimRight(r.readRawAttr(sysPath, "device/vendor"), "\n"),
+		Model:      udevModel(udev.properties, r.readAttr(sysPath, "device/model")),
+		State:      r.readAttr(sysPath, "device/state"),
+		FSType:     udev.properties["ID_FS_TYPE"],
+		PartLabel:  udev.properties["ID_PART_ENTRY_NAME"],
+		WWN:        udevWWN(udev.properties),
+		IDLinks:    udev.idLinks,
+		Mountpoint: mountpoints[devNumber],
+		Holders:    r.listDir(filepath.Join(sysPath, "holders")),
+		Serial:     udevSerial(udev.properties, r.readAttr(sysPath, "device/serial")),
+
+		LogicalSectorSize:  logicalSectorSize,
+		PhysicalSectorSize: physicalSectorSize,
+		ByPathLinks:        udev.byPathLinks,
+	}
+	if len(udev.idLinks) > 0 {
+		device.PathByID = udev.idLinks[0]
+	}
+
+	switch {
+	case r.exists(filepath.Join(sysPath, "partition")):
+		device.Type = "part"
+	case strings.HasPrefix(kname, "dm-"):
+		device.Name = r.readAttr(sysPath, "dm/name")
+		device.Path = "/dev/mapper/" + device.Name
+		device.Type = dmType(r.readAttr(sysPath, "dm/uuid"))
+		device.State = "running"
+		if r.readAttr(sysPath, "dm/suspended") == "1" {
+			device.State = StateSuspended
+		}
+	case strings.HasPrefix(kname, "loop"):
+		device.Type = "loop"
+	case strings.HasPrefix(kname, "md"):
+		device.Type = r.readAttr(sysPath, "md/level")
+	case r.readAttr(sysPath, "device/type") == scsiTypeROM:
+		device.Type = "rom"
+	default:
+		device.Type = "disk"
+	}
+	if device.Type == "disk" {
+		devicePath, err := filepath.EvalSymlinks(sysPath)
+		if err != nil {
+			devicePath = sysPath
+		}
+		device.Transport = r.transport(kname, devicePath)
+		device.NUMANode = r.numaNode(devicePath)
+	}
+
+	// The partitions are sub-directories of the disk, the holders live in /sys/block
+	for _, name := range r.listDir(sysPath) {
+		if !strings.HasPrefix(name, kname) || !r.exists(filepath.Join(sysPath, name, "partition")) {
+			continue
+		}
+		partition, err := r.readDevice(name, filepath.Join(sysPath, name), mountpoints)
+		if err != nil {
+			return BlockDevice{}, err
+		}
+		device.Children = append(device.Children, partition)
+	}
+	for _, holder := range device.Holders {
+		child, err := r.readDevice(holder, filepath.Join(r.Root, sysBlockPath, holder), mountpoints)
+		if err != nil {
+			return BlockDevice{}, err
+		}
+		device.Children = append(device.Children, child)
+	}
+	return device, nil
+}
+
+// udevData is the content of a udev database entry
+type udevData struct {
+	properties map[string]string
+	// idLinks are the /dev/disk/by-id link names, shortest first
+	idLinks []string
+	// byPathLinks are the /dev/disk/by-path link names
+	byPathLinks []string
+}
+
+// readUdevData reads the udev database entry of a device. A device unknown to udev has no entry
+func (r SysfsReader) readUdevData(devNumber string) (udevData, error) {
+	data := udevData{properties: map[string]string{}}
+	file, err := os.Open(filepath.Join(r.Root, udevDataPath, "b"+devNumber))
+	if errors.Is(err, os.ErrNotExist) {
+		return data, nil
+	}
+	if err != nil {
+		return data, fmt.Errorf("failed to read udev data of %s: %w", devNumber, err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "E:"):
+			key, value, _ := strings.Cut(strings.TrimPrefix(line, "E:"), "=")
+			data.properties[key] = value
+		case strings.HasPrefix(line, "S:"+diskByIDLinkPath):
+			data.idLinks = append(data.idLinks, strings.TrimPrefix(line, "S:"+diskByIDLinkPath))
+		case strings.HasPrefix(line, "S:"+diskByPathLinkPath):
+			data.byPathLinks = append(data.byPathLinks, strings.TrimPrefix(line, "S:"+diskByPathLinkPath))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return data, fmt.Errorf("failed to read udev data of %s: %w", devNumber, err)
+	}
+	// lsblk reports the shortest link as the id-link
+	sort.Slice(data.idLinks, func(i, j int) bool {
+		if len(data.idLinks[i]) != len(data.idLinks[j]) {
+			return len(data.idLinks[i]) < len(data.idLinks[j])
+		}
+		return data.idLinks[i] < data.idLinks[j]
+	})
+	return data, nil
+}
+
+// readMountpoints maps the major:minor numbers of the mounted devices, including the swap devices,
+// to their first mountpoint. The mount table is the one of the init process, which is the one of the
+// host since the daemon runs with hostPID
+func (r SysfsReader) readMountpoints() (map[string]string, error) {
+	mountpoints := map[string]string{}
+	file, err := os.Open(filepath.Join(r.Root, mountInfoPath))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read mountinfo: %w", err)
+	}
+	if err == nil {
+		defer file.Close()
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			// 36 35 98:0 /mnt1 /mnt2 rw,noatime master:1 - ext3 /dev/root rw,errors=continue
+			fields := strings.Fields(scanner.Text())
+			if len(fields) < 5 {
+				continue
+			}
+			if _, found := mountpoints[fields[2]]; !found {
+				mountpoints[fields[2]] = fields[4]
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read mountinfo: %w", err)
+		}
+	}
+
+	swaps, err := os.ReadFile(filepath.Join(r.Root, swapsPath))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read swaps: %w", err)
+	}
+	for _, line := range strings.Split(string(swaps), "\n")[1:] {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		// Swap devices are listed by path, resolve them to their device number
+		if devNumber := r.readAttr(filepath.Join(r.Root, sysBlockPath, filepath.Base(fields[0])), "dev"); devNumber != "" {
+			mountpoints[devNumber] = swapMountpoint
+		}
+	}
+	return mountpoints, nil
+}
+
+// readRawAttr returns the content of a sysfs attribute, or an empty string if it does not exist
+func (r SysfsReader) readRawAttr(sysPath, attr string) string {
+	content, err := os.ReadFile(filepath.Join(sysPath, attr))
+	if err != nil {
+		return ""
+	}
+	return string(content)
+}
+
+// readAttr returns the content of a sysfs attribute without its surrounding spaces
+func (r SysfsReader) readAttr(sysPath, attr string) string {
+	return strings.TrimSpace(r.readRawAttr(sysPath, attr))
+}
+
+// listDir returns the sorted names in a directory, or nothing if it does not exist
+func (r SysfsReader) listDir(dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	return names
+}
+
+// exists returns true if the path exists
+func (r SysfsReader) exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// transport identifies how a disk is attached from the path of its device in sysfs, like lsblk does
+func (r SysfsReader) transport(kname, devicePath string) string {
+	if strings.HasPrefix(kname, "nvme") {
+		return "nvme"
+	}
+	for _, component := range strings.Split(devicePath, "/") {
+		hostNumber, found := strings.CutPrefix(component, "host")
+		if _, err := strconv.Atoi(hostNumber); !found || err != nil {
+			continue
+		}
+		for _, transport := range scsiHostTransports {
+			if r.exists(filepath.Join(r.Root, sysClassPath, transport+"_host", component)) {
+				return transport
+			}
+		}
+	}
+	switch {
+	case strings.Contains(devicePath, "/usb"):
+		return "usb"
+	case strings.Contains(devicePath, "/ata"):
+		return "sata"
+	case strings.Contains(devicePath, "/virtio"):
+		return "virtio"
+	}
+	return ""
+}
+
+// numaNode returns the NUMA node of the closest parent of the device that has one, usually its
+// PCI controller. The kernel reports -1 when the platform has no NUMA information
+func (r SysfsReader) numaNode(devicePath string) *int32 {
+	devicesPath := filepath.Join(r.Root, sysDevicesPath)
+	for dir := filepath.Dir(devicePath); strings.HasPrefix(dir, devicesPath); dir = filepath.Dir(dir) {
+		value := r.readAttr(dir, "numa_node")
+		if value == "" {
+			continue
+		}
+		node, err := strconv.ParseInt(value, 10, 32)
+		if err != nil || node < 0 {
+			return nil
+		}
+		numaNode := int32(node)
+		return &numaNode
+	}
+	return nil
+}
+
+// dmType derives the type of a device mapper device from its uuid, like lsblk does
+func dmType(uuid string) string {
+	prefix, _, found := strings.Cut(uuid, "-")
+	if !found {
+		return "dm"
+	}
+	prefix = strings.ToLower(prefix)
+	// kpartx names the partitions of a multipath device part1-mpath-...
+	if strings.HasPrefix(prefix, "part") {
+		return "part"
+	}
+	return prefix
+}
+
+// udevWWN prefers the WWN with its vendor extension, which identifies NAA 6 LUNs
+func udevWWN(properties map[string]string) string {
+	if wwn := properties["ID_WWN_WITH_EXTENSION"]; wwn != "" {
+		return wwn
+	}
+	return properties["ID_WWN"]
+}
+
+// udevSerial returns the serial number of a SCSI device, falling back to the sysfs one of NVMe devices
+func udevSerial(properties map[string]string, sysfsSerial string) string {
+	for _, key := range []string{"ID_SCSI_SERIAL", "ID_SERIAL_SHORT"} {
+		if serial := properties[key]; serial != "" {
+			return serial
+		}
+	}
+	return sysfsSerial
+}
+
+// udevModel decodes the model escaped by udev, falling back to the sysfs one
+func udevModel(properties map[string]string, sysfsModel string) string {
+	encoded, ok := properties["ID_MODEL_ENC"]
+	if !ok {
+		return sysfsModel
+	}
+	var model strings.Builder
+	for i := 0; i < len(encoded); i++ {
+		if encoded[i] == '\\' && i+3 < len(encoded) && encoded[i+1] == 'x' {
+			if b, err := strconv.ParseUint(encoded[i+2:i+4], 16, 8); err == nil {
+				model.WriteByte(byte(b))
+				i += 3
+				continue
+			}
+		}
+		model.WriteByte(encoded[i])
+	}
+	return strings.TrimSpace(model.String())
+}
@@ -0,0 +1,184 @@
+package diskutils
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SysfsReader", func() {
+	var root string
+
+	// write creates the file and its parent directories in the fake tree
+	write := func(path, content string) {
+		path = filepath.Join(root, path)
+		Expect(os.MkdirAll(filepath.Dir(path), 0o755)).To(Succeed())
+		Expect(os.WriteFile(path, []byte(content), 0o600)).To(Succeed())
+	}
+
+	// device creates a /sys/block entry with the attributes every block device has
+	device := func(sysPath, devNumber, sectors string) {
+		write(filepath.Join(sysPath, "dev"), devNumber+"\n")
+		write(filepath.Join(sysPath, "size"), sectors+"\n")
+		write(filepath.Join(sysPath, "ro"), "0\n")
+		write(filepath.Join(sysPath, "removable"), "0\n")
+	}
+
+	// link makes the /sys/block entry a link to the device in /sys/devices, like the kernel does
+	link := func(kname, devicePath string) {
+		Expect(os.MkdirAll(filepath.Join(root, devicePath), 0o755)).To(Succeed())
+		Expect(os.MkdirAll(filepath.Join(root, "sys/block"), 0o755)).To(Succeed())
+		Expect(os.Symlink(filepath.Join(root, devicePath), filepath.Join(root, "sys/block", kname))).To(Succeed())
+	}
+
+	scsiDisk := func(kname, devNumber, vendor, model string) {
+		sysPath := filepath.Join("sys/block", kname)
+		device(sysPath, devNumber, "2097152")
+		write(filepath.Join(sysPath, "queue/rotational"), "0\n")
+		write(filepath.Join(sysPath, "device/vendor"), vendor+"\n")
+		write(filepath.Join(sysPath, "device/model"), model+"\n")
+		write(filepath.Join(sysPath, "device/state"), "running\n")
+		write(filepath.Join(sysPath, "device/type"), "0\n")
+		write(filepath.Join(sysPath, "queue/logical_block_size"), "512\n")
+		write(filepath.Join(sysPath, "queue/physical_block_size"), "4096\n")
+	}
+
+	BeforeEach(func() {
+		root = GinkgoT().TempDir()
+
+		// Boot disk with a BIOS boot partition and a mounted root partition
+		link("sda", "sys/devices/pci0000:00/0000:00:1f.2/ata1/host0/target0:0:0/0:0:0:0/block/sda")
+		write("sys/devices/pci0000:00/0000:00:1f.2/numa_node", "-1\n")
+		write("run/udev/data/b8:0", "E:ID_SERIAL_SHORT=QM00001\n")
+		scsiDisk("sda", "8:0", "QEMU    ", "QEMU HARDDISK   ")
+		write("sys/block/sda/queue/rotational", "1\n")
+		device("sys/block/sda/sda1", "8:1", "2048")
+		write("sys/block/sda/sda1/partition", "1\n")
+		device("sys/block/sda/sda2", "8:2", "2000000")
+		write("sys/block/sda/sda2/partition", "2\n")
+		write("run/udev/data/b8:1", "E:ID_PART_ENTRY_NAME=BIOS-BOOT\n")
+		write("run/udev/data/b8:2", "E:ID_FS_TYPE=xfs\nE:ID_PART_ENTRY_NAME=root\n")
+		write("proc/1/mountinfo", "98 1 8:2 / /sysroot rw,relatime - xfs /dev/sda2 rw\n"+
+			"99 98 8:2 /ostree/deploy / rw,relatime - xfs /dev/sda2 rw\n")
+
+		// Two paths to the same multipathed LUN, through the FC HBA on NUMA node 1
+		write("sys/class/fc_host/host1/port_name", "0x10000090fa8c1f52\n")
+		write("sys/devices/pci0000:80/0000:80:02.0/numa_node", "1\n")
+		for _, member := range []struct{ kname, devNumber string }{{"sdb", "8:16"}, {"sdc", "8:32"}} {
+			link(member.kname, "sys/devices/pci0000:80/0000:80:02.0/0000:81:00.0/host1/rport-1:0-0/target1:0:0/1:0:0:"+
+				member.devNumber[2:]+"/block/"+member.kname)
+			scsiDisk(member.kname, member.devNumber, "NETAPP  ", "LUN C-Mode      ")
+			write(filepath.Join("sys/block", member.kname, "holders/dm-0"), "")
+			write("run/udev/data/b"+member.devNumber, "S:disk/by-path/fc-0x500a0981891b8dc5-0x500a0981991b8dc5-lun-0\n"+
+				"S:disk/by-id/wwn-0x600a098038304437415d4b6a5968624f\n"+
+				"S:disk/by-id/scsi-3600a098038304437415d4b6a5968624f\n"+
+				"E:ID_FS_TYPE=mpath_member\n"+
+				"E:ID_SCSI_SERIAL=81Dp7$Mjhb4O\n"+
+				"E:ID_MODEL_ENC=LUN\\x20C-Mode\\x20\\x20\\x20\\x20\\x20\\x20\n"+
+				"E:ID_WWN=0x600a098038304437\n"+
+				"E:ID_WWN_WITH_EXTENSION=0x600a098038304437415d4b6a5968624f\n")
+		}
+		device("sys/block/dm-0", "253:0", "2097152")
+		write("sys/block/dm-0/slaves/sdb", "")
+		write("sys/block/dm-0/slaves/sdc", "")
+		write("sys/block/dm-0/dm/name", "mpatha\n")
+		write("sys/block/dm-0/dm/uuid", "mpath-3600a098038304437415d4b6a5968624f\n")
+		write("sys/block/dm-0/dm/suspended", "0\n")
+		write("run/udev/data/b253:0", "S:disk/by-id/dm-uuid-mpath-3600a098038304437415d4b6a5968624f\n"+
+			"S:disk/by-id/dm-name-mpatha\n")
+
+		// A swap loop device, a RAM disk and a CD-ROM
+		device("sys/block/loop0", "7:0", "8192")
+		write("proc/swaps", "Filename\tType\tSize\tUsed\tPriority\n/dev/loop0\tpartition\t4096\t0\t-2\n")
+		device("sys/block/ram0", "1:0", "8192")
+		scsiDisk("sr0", "11:0", "QEMU    ", "QEMU DVD-ROM    ")
+		write("sys/block/sr0/device/type", "5\n")
+		write("sys/block/sr0/removable", "1\n")
+		write("sys/block/sr0/ro", "1\n")
+	})
+
+	It("rebuilds the block device tree", func() {
+		devices, err := SysfsReader{Root: root}.ReadBlockDevices()
+		Expect(err).ToNot(HaveOccurred())
+
+		mpath := BlockDevice{
+			Name: "mpatha", KName: "dm-0", Path: "/dev/mapper/mpatha", Type: "mpath", Size: 1073741824, State: "running",
+			PathByID: "dm-name-mpatha",
+			IDLinks:  []string{"dm-name-mpatha", "dm-uuid-mpath-3600a098038304437415d4b6a5968624f"},
+		}
+		numaNode := int32(1)
+		member := func(kname string) BlockDevice {
+			return BlockDevice{
+				Name: kname, KName: kname, Path: "/dev/" + kname, Type: "disk", Size: 1073741824,
+				Vendor: "NETAPP  ", Model: "LUN C-Mode", State: "running", FSType: "mpath_member",
+				Serial: "81Dp7$Mjhb4O", Transport: "fc", LogicalSectorSize: 512, PhysicalSectorSize: 4096,
+				ByPathLinks: []string{"fc-0x500a0981891b8dc5-0x500a0981991b8dc5-lun-0"},
+				NUMANode:    &numaNode,
+				WWN:         "0x600a098038304437415d4b6a5968624f",
+				PathByID:    "scsi-3600a098038304437415d4b6a5968624f",
+				IDLinks: []string{
+					"scsi-3600a098038304437415d4b6a5968624f",
+					"wwn-0x600a098038304437415d4b6a5968624f",
+				},
+				Holders:  []string{"dm-0"},
+				Children: []BlockDevice{mpath},
+			}
+		}
+		Expect(devices).To(Equal([]BlockDevice{
+			{Name: "loop0", KName: "loop0", Path: "/dev/loop0", Type: "loop", Size: 4194304, Mountpoint: "[SWAP]"},
+			{
+				Name: "sda", KName: "sda", Path: "/dev/sda", Type: "disk", Size: 1073741824, Rotational: true,
+				Vendor: "QEMU    ", Model: "QEMU HARDDISK", State: "running",
+				Serial: "QM00001", Transport: "sata", LogicalSectorSize: 512, PhysicalSectorSize: 4096,
+				Children: []BlockDevice{
+					{Name: "sda1", KName: "sda1", Path: "/dev/sda1", Type: "part", Size: 1048576, PartLabel: "BIOS-BOOT"},
+					{
+						Name: "sda2", KName: "sda2", Path: "/dev/sda2", Type: "part", Size: 1024000000,
+						FSType: "xfs", PartLabel: "root", Mountpoint: "/sysroot",
+					},
+				},
+			},
+			member("sdb"),
+			member("sdc"),
+			{
+				Name: "sr0", KName: "sr0", Path: "/dev/sr0", Type: "rom", Size: 1073741824, ReadOnly: true, Removable: true,
+				Vendor: "QEMU    ", Model: "QEMU DVD-ROM", State: "running",
+				LogicalSectorSize: 512, PhysicalSectorSize: 4096,
+			},
+		}))
+
+		Expect(devices[2].BiosPartition()).To(BeFalse())
+		Expect(devices[1].BiosPartition()).To(BeTrue())
+		path, err := devices[2].GetDevPath()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(path).To(Equal("/dev/dm-0"))
+		id, err := devices[2].GetPathByID()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(id).To(Equal("/dev/disk/by-id/dm-name-mpatha"))
+	})
+
+	It("reports suspended device mapper devices", func() {
+		write("sys/block/dm-0/dm/suspended", "1\n")
+		devices, err := SysfsReader{Root: root}.ReadBlockDevices()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(devices[2].Children[0].State).To(Equal(StateSuspended))
+	})
+
+	It("fails without sysfs", func() {
+		_, err := SysfsReader{Root: filepath.Join(root, "missing")}.ReadBlockDevices()
+		Expect(err).To(MatchError(ContainSubstring("failed to list block devices")))
+	})
+})
+
+var _ = Describe("LsblkReader", func() {
+	It("unmarshals the lsblk output", func() {
+		ExecCommand = &fakeExecutor{
+			cmd: &fakeCommand{output: []byte(`{"blockdevices":[{"name":"sda","kname":"sda","type":"disk","size":1024,"rota":true}]}`)},
+		}
+		devices, err := LsblkReader{}.ReadBlockDevices()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(devices).To(Equal([]BlockDevice{{Name: "sda", KName: "sda", Type: "disk", Size: 1024, Rotational: true}}))
+	})
+})
@@ -0,0 +1,54 @@
+package diskutils
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// WipeSize is how much is zeroed at the beginning and at the end of a device. It covers the
+// signatures blkid knows, the GPT backup header and the metadata kept at the end of RAID members
+const WipeSize = 1024 * 1024
+
+// WipeDevice clears the signatures of a device and returns the number of bytes zeroed, it is
+// replaced in the tests
+var WipeDevice = wipeDevice
+
+func wipeDevice(path string) (int64, error) {
+	// O_EXCL fails on a block device that is mounted or held by another device, like a multipath path
+	file, err := os.OpenFile(path, os.O_WRONLY|unix.O_EXCL, 0)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open %s exclusively: %w", path, err)
+	}
+	defer file.Close()
+
+	size, err := file.Seek(0, io.SeekEnd)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get the size of %s: %w", path, err)
+	}
+	zeros := make([]byte, WipeSize)
+	head := min(size, WipeSize)
+	if _, err := file.WriteAt(zeros[:head], 0); err != nil {
+		return 0, fmt.Errorf("failed to wipe the beginning of %s: %w", path, err)
+	}
+	wiped := head
+	if tail := max(size-WipeSize, head); tail < size {
+		if _, err := file.WriteAt(zeros[:size-tail], tail); err != nil {
+			return wiped, fmt.Errorf("failed to wipe the end of %s: %w", path, err)
+		}
+		wiped += size - tail
+	}
+	if err := file.Sync(); err != nil {
+		return wiped, fmt.Errorf("failed to sync %s: %w", path, err)
+	}
+
+	info, err := file.Stat()
+	if err == nil && info.Mode()&os.ModeDevice != 0 {
+		// Let the kernel forget the partitions that were cleared. This is best effort, a device
+		// without partitions table may refuse it
+		_ = unix.IoctlSetInt(int(file.Fd()), unix.BLKRRPART, 0)
+	}
+	return wiped, nil
+}
@@ -0,0 +1,51 @@
+package diskutils
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Device Wipe", func() {
+	// device returns a file of the size filled with ones
+	device := func(size int) string {
+		path := filepath.Join(GinkgoT().TempDir(), "disk")
+		Expect(os.WriteFile(path, bytes.Repeat([]byte{1}, size), 0o600)).To(Succeed())
+		return path
+	}
+
+	It("zeroes the beginning and the end of a device", func() {
+		size := 4 * WipeSize
+		path := device(size)
+
+		wiped, err := WipeDevice(path)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(wiped).To(Equal(int64(2 * WipeSize)))
+		content, err := os.ReadFile(path)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(content).To(HaveLen(size))
+		Expect(content[:WipeSize]).To(Equal(make([]byte, WipeSize)))
+		Expect(content[WipeSize : size-WipeSize]).To(Equal(bytes.Repeat([]byte{1}, size-2*WipeSize)))
+		Expect(content[size-WipeSize:]).To(Equal(make([]byte, WipeSize)))
+	})
+
+	It("zeroes a device smaller than the wiped regions once", func() {
+		size := WipeSize + WipeSize/2
+		path := device(size)
+
+		wiped, err := WipeDevice(path)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(wiped).To(Equal(int64(size)))
+		content, err := os.ReadFile(path)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(content).To(Equal(make([]byte, size)))
+	})
+
+	It("fails on a missing device", func() {
+		_, err := WipeDevice(filepath.Join(GinkgoT().TempDir(), "missing"))
+		Expect(err).To(MatchError(ContainSubstring("failed to open")))
+	})
+})
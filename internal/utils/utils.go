@@ -22,6 +22,8 @@ import (
 	"fmt"
 	"os"
 	"path"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
@@ -41,49 +43,48 @@ const (
 	CheckPodContainerName       = "check"
 )
 
-// Taken from https://www.ibm.com/docs/en/scalecontainernative/5.2.2?topic=planning-software-requirements
+// FusionAccessData is the support matrix of a single IBM Storage Scale Container Native version.
+// It is read from the metadata.yaml file shipped next to the install.yaml of each version under files/
+// See https://www.ibm.com/docs/en/scalecontainernative/5.2.3?topic=planning-software-requirements
 type FusionAccessData struct {
-	CSIVersion                string   `json:"csi_version"`
-	Architecture              []string `json:"architecture"`
-	RemoteStorageClusterLevel string   `json:"remote_storage_cluster_level"`
-	FileSystemVersion         string   `json:"file_system_version"`
-	OpenShiftLevels           []string `json:"openshift_levels"`
+	CSIVersion                string   `json:"csi_version" yaml:"csi_version"`
+	Architecture              []string `json:"architecture" yaml:"architecture"`
+	RemoteStorageClusterLevel string   `json:"remote_storage_cluster_level" yaml:"remote_storage_cluster_level"`
+	FileSystemVersion         string   `json:"file_system_version" yaml:"file_system_version"`
+	OpenShiftLevels           []string `json:"openshift_levels" yaml:"openshift_levels"`
+	// UpgradeFrom lists the versions that can be upgraded directly to this one
+	UpgradeFrom []string `json:"upgrade_from" yaml:"upgrade_from"`
 }
 
-// The dict key is the IBM Fusion Access Container Native version
-var storageScaleTable = map[string]FusionAccessData{
-	"5.2.2.0": {
-		"2.13.0",
-		[]string{"x86_64", "ppc64le", "s390x"},
-		"5.1.9.0+",
-		"36.00",
-		[]string{"4.15", "4.16", "4.17"},
-	},
-	"5.2.2.1": {
-		"2.13.1",
-		[]string{"x86_64", "ppc64le", "s390x"},
-		"5.1.9.0+",
-		"36.00",
-		[]string{"4.15", "4.16", "4.17", "4.18"},
-	},
-	"5.2.3.0": {
-		"2.13.1",
-		[]string{"x86_64", "ppc64le", "s390x"},
-		"5.1.9.0+",
-		"36.00",
-		[]string{"4.16", "4.17", "4.18"},
-	},
-}
+const (
+	installFileName  = "install.yaml"
+	metadataFileName = "metadata.yaml"
+)
 
-func IsOpenShiftSupported(ibmFusionAccessVersion string, openShiftVersion semver.Version) bool {
-	// Strip the leading "v" from the IBM Fusion Access version
-	ibmVer := ibmFusionAccessVersion
-	if strings.HasPrefix(ibmFusionAccessVersion, "v") {
-		ibmVer = ibmFusionAccessVersion[1:] // Remove the first character
+// filesSearchPaths are the folders containing one subfolder per supported version.
+// In order: when running tests, when running locally and when running in the container
+var filesSearchPaths = []string{"../../files/", "files/", "/files/"}
+
+// GetStorageScaleData returns the support matrix of the given IBM Storage Scale Container Native version
+func GetStorageScaleData(ibmFusionAccessVersion string) (*FusionAccessData, error) {
+	metadataPath, err := GetMetadataPath(ibmFusionAccessVersion)
+	if err != nil {
+		return nil, err
+	}
+	content, err := os.ReadFile(metadataPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read metadata file %s: %w", metadataPath, err)
+	}
+	data := &FusionAccessData{}
+	if err := yaml.Unmarshal(content, data); err != nil {
+		return nil, fmt.Errorf("failed to parse metadata file %s: %w", metadataPath, err)
 	}
+	return data, nil
+}
 
-	data, exists := storageScaleTable[ibmVer]
-	if !exists {
+func IsOpenShiftSupported(ibmFusionAccessVersion string, openShiftVersion semver.Version) bool {
+	data, err := GetStorageScaleData(ibmFusionAccessVersion)
+	if err != nil {
 		return false
 	}
 
@@ -100,6 +101,126 @@ func IsOpenShiftSupported(ibmFusionAccessVersion string, openShiftVersion semver
 	return false
 }
 
+// GetMaxOpenShiftVersion returns the newest OpenShift minor, like 4.19, supported by the given IBM Storage
+// Scale Container Native version
+func GetMaxOpenShiftVersion(ibmFusionAccessVersion string) (string, error) {
+	data, err := GetStorageScaleData(ibmFusionAccessVersion)
+	if err != nil {
+		return "", err
+	}
+	var maxVersion *semver.Version
+	for _, level := range data.OpenShiftLevels {
+		version, err := semver.NewVersion(level)
+		if err != nil {
+			return "", fmt.Errorf("invalid OpenShift level %q of %s: %w", level, ibmFusionAccessVersion, err)
+		}
+		if maxVersion == nil || version.GreaterThan(maxVersion) {
+			maxVersion = version
+		}
+	}
+	if maxVersion == nil {
+		return "", fmt.Errorf("no OpenShift level found for %s", ibmFusionAccessVersion)
+	}
+	return fmt.Sprintf("%d.%d", maxVersion.Major(), maxVersion.Minor()), nil
+}
+
+// GetStorageScaleVersions returns the IBM Storage Scale versions shipped with the operator, oldest first
+func GetStorageScaleVersions() ([]string, error) {
+	for _, searchPath := range filesSearchPaths {
+		entries, err := os.ReadDir(searchPath)
+		if err != nil {
+			continue
+		}
+		versions := []string{}
+		for _, entry := range entries {
+			if entry.IsDir() {
+				versions = append(versions, entry.Name())
+			}
+		}
+		slices.SortFunc(versions, func(a, b string) int {
+			cmp, _ := CompareStorageScaleVersions(a, b)
+			return cmp
+		})
+		return versions, nil
+	}
+	return nil, fmt.Errorf("could not find any IBM Storage Scale version in %v", filesSearchPaths)
+}
+
+// GetOldestStorageScaleVersionFor returns the oldest shipped IBM Storage Scale version that is newer than
+// fromVersion and supports the given OpenShift version. It returns an empty string if there is none
+func GetOldestStorageScaleVersionFor(fromVersion string, openShiftVersion semver.Version) (string, error) {
+	versions, err := GetStorageScaleVersions()
+	if err != nil {
+		return "", err
+	}
+	for _, version := range versions {
+		cmp, err := CompareStorageScaleVersions(version, fromVersion)
+		if err != nil || cmp <= 0 {
+			continue
+		}
+		if IsOpenShiftSupported(version, openShiftVersion) {
+			return version, nil
+		}
+	}
+	return "", nil
+}
+
+// parseStorageScaleVersion parses versions like v5.2.3.1 into their numeric components
+func parseStorageScaleVersion(version string) ([]int, error) {
+	parts := strings.Split(strings.TrimPrefix(version, "v"), ".")
+	if len(parts) != 4 {
+		return nil, fmt.Errorf("invalid IBM Storage Scale version %s", version)
+	}
+	numbers := make([]int, len(parts))
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid IBM Storage Scale version %s: %w", version, err)
+		}
+		numbers[i] = n
+	}
+	return numbers, nil
+}
+
+// CompareStorageScaleVersions returns -1, 0 or 1 if a is respectively older, equal or newer than b
+func CompareStorageScaleVersions(a, b string) (int, error) {
+	aParts, err := parseStorageScaleVersion(a)
+	if err != nil {
+		return 0, err
+	}
+	bParts, err := parseStorageScaleVersion(b)
+	if err != nil {
+		return 0, err
+	}
+	return slices.Compare(aParts, bParts), nil
+}
+
+// ValidateStorageScaleUpgrade returns an error when upgrading from one IBM Storage Scale version to another
+// is not allowed. Downgrades are never allowed and the target version needs to list the source version
+// in the upgrade_from field of its metadata
+func ValidateStorageScaleUpgrade(fromVersion, toVersion string) error {
+	cmp, err := CompareStorageScaleVersions(fromVersion, toVersion)
+	if err != nil {
+		return err
+	}
+	if cmp == 0 {
+		return nil
+	}
+	if cmp > 0 {
+		return fmt.Errorf("downgrading IBM Storage Scale from %s to %s is not supported", fromVersion, toVersion)
+	}
+	data, err := GetStorageScaleData(toVersion)
+	if err != nil {
+		return err
+	}
+	normalize := func(v string) string { return strings.TrimPrefix(v, "v") }
+	if !slices.ContainsFunc(data.UpgradeFrom, func(v string) bool { return normalize(v) == normalize(fromVersion) }) {
+		return fmt.Errorf("upgrading IBM Storage Scale from %s to %s is not supported, %s can only be reached from %v",
+			fromVersion, toVersion, toVersion, data.UpgradeFrom)
+	}
+	return nil
+}
+
 // status:
 //  history:
 //   - completionTime: null
@@ -332,24 +453,28 @@ func CanPullImage(
 }
 
 func GetInstallPath(cnsaVersion string) (string, error) {
-	// Install path when running tests
-	var err error
-	install_path := path.Join("../../files/", cnsaVersion, "install.yaml")
-	if _, err := os.Stat(install_path); err == nil {
-		return install_path, nil
-	}
-	// Install path when running locally
-	install_path = path.Join("files/", cnsaVersion, "install.yaml")
-	if _, err := os.Stat(install_path); err == nil {
-		return install_path, nil
+	return getVersionFilePath(cnsaVersion, installFileName)
+}
+
+func GetMetadataPath(cnsaVersion string) (string, error) {
+	return getVersionFilePath(cnsaVersion, metadataFileName)
+}
+
+// getVersionFilePath returns the path of fileName in the folder of the given version.
+// The version folders are always prefixed with a "v" (e.g. files/v5.2.3.1)
+func getVersionFilePath(cnsaVersion, fileName string) (string, error) {
+	if !strings.HasPrefix(cnsaVersion, "v") {
+		cnsaVersion = "v" + cnsaVersion
 	}
-	// Install path when running in container
-	install_path = path.Join("/files/", cnsaVersion, "install.yaml")
-	if _, err := os.Stat(install_path); err == nil {
-		return install_path, nil
+	var err error
+	for _, searchPath := range filesSearchPaths {
+		filePath := path.Join(searchPath, cnsaVersion, fileName)
+		if _, err = os.Stat(filePath); err == nil {
+			return filePath, nil
+		}
 	}
 
-	return "", fmt.Errorf("could not find/open install file with version %s: %w", cnsaVersion, err)
+	return "", fmt.Errorf("could not find/open %s file with version %s: %w", fileName, cnsaVersion, err)
 }
 
 func IsExternalManifestURLAllowed(url string) bool {
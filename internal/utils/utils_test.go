@@ -114,7 +114,36 @@ var _ = Describe("ParseAndReturnVersion", func() {
 })
 
 var _ = Describe("IsOpenShiftSupported", func() {
-	DescribeTable("IBM version + OCP version matrix",
+	Context("with the metadata from the testdata folder", func() {
+		var origFilesSearchPaths []string
+
+		BeforeEach(func() {
+			origFilesSearchPaths = filesSearchPaths
+			filesSearchPaths = []string{"testdata/files/"}
+		})
+
+		AfterEach(func() {
+			filesSearchPaths = origFilesSearchPaths
+		})
+
+		DescribeTable("IBM version + OCP version matrix",
+			func(ibmVersion string, ocpVersion string, expected bool) {
+				version, err := semver.NewVersion(ocpVersion)
+				Expect(err).ToNot(HaveOccurred())
+				result := IsOpenShiftSupported(ibmVersion, *version)
+				Expect(result).To(Equal(expected))
+			},
+
+			Entry("5.2.2.0 supports 4.17.3", "5.2.2.0", "4.17.3", true),
+			Entry("5.2.2.0 does not support 4.18.1", "5.2.2.0", "4.18.1", false),
+			Entry("5.2.2.0 supports 4.15.17", "5.2.2.0", "4.15.17", true),
+			Entry("5.2.2.1 supports 4.18.1", "5.2.2.1", "4.18.1", true),
+			Entry("5.2.3.0 does not support 4.15.10", "5.2.3.0", "4.15.10", false),
+			Entry("v5.2.2.1 supports 4.18.1", "v5.2.2.1", "4.18.1", true),
+		)
+	})
+
+	DescribeTable("shipped versions",
 		func(ibmVersion string, ocpVersion string, expected bool) {
 			version, err := semver.NewVersion(ocpVersion)
 			Expect(err).ToNot(HaveOccurred())
@@ -122,13 +151,26 @@ var _ = Describe("IsOpenShiftSupported", func() {
 			Expect(result).To(Equal(expected))
 		},
 
-		Entry("5.2.2.0 supports 4.17.3", "5.2.2.0", "4.17.3", true),
-		Entry("5.2.2.0 does not support 4.18.1", "5.2.2.0", "4.18.1", false),
-		Entry("5.2.2.0 supports 4.15.17", "5.2.2.0", "4.15.17", true),
-		Entry("5.2.2.1 supports 4.18.1", "5.2.2.1", "4.18.1", true),
-		Entry("5.2.3.0 does not support 4.15.10", "5.2.3.0", "4.15.10", false),
+		Entry("v5.2.3.1 supports 4.19.2", "v5.2.3.1", "4.19.2", true),
+		Entry("v5.2.3.1 supports 4.18.10", "v5.2.3.1", "4.18.10", true),
+		Entry("v5.2.3.1 does not support 4.15.10", "v5.2.3.1", "4.15.10", false),
+		Entry("v5.2.3.1 does not support 4.20.0", "v5.2.3.1", "4.20.0", false),
 	)
 
+	It("should ship metadata for every version with an install manifest", func() {
+		dirs, err := os.ReadDir("../../files/")
+		Expect(err).ToNot(HaveOccurred())
+		for _, dir := range dirs {
+			if !dir.IsDir() {
+				continue
+			}
+			data, err := GetStorageScaleData(dir.Name())
+			Expect(err).ToNot(HaveOccurred(), "missing metadata for %s", dir.Name())
+			Expect(data.OpenShiftLevels).ToNot(BeEmpty())
+			Expect(data.CSIVersion).ToNot(BeEmpty())
+		}
+	})
+
 	It("should return false for invalid IBM version", func() {
 		version, err := semver.NewVersion("4.9")
 		Expect(err).ToNot(HaveOccurred())
@@ -137,6 +179,100 @@ var _ = Describe("IsOpenShiftSupported", func() {
 	})
 })
 
+var _ = Describe("GetMaxOpenShiftVersion", func() {
+	var origFilesSearchPaths []string
+
+	BeforeEach(func() {
+		origFilesSearchPaths = filesSearchPaths
+		filesSearchPaths = []string{"testdata/files/"}
+	})
+
+	AfterEach(func() {
+		filesSearchPaths = origFilesSearchPaths
+	})
+
+	It("returns the newest OpenShift minor of the support matrix", func() {
+		Expect(GetMaxOpenShiftVersion("v5.2.2.1")).To(Equal("4.18"))
+	})
+
+	It("fails for an unknown version", func() {
+		_, err := GetMaxOpenShiftVersion("v9.9.9.9")
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("ValidateStorageScaleUpgrade", func() {
+	var origFilesSearchPaths []string
+
+	BeforeEach(func() {
+		origFilesSearchPaths = filesSearchPaths
+		filesSearchPaths = []string{"testdata/files/"}
+	})
+
+	AfterEach(func() {
+		filesSearchPaths = origFilesSearchPaths
+	})
+
+	DescribeTable("upgrade graph",
+		func(from, to string, allowed bool) {
+			err := ValidateStorageScaleUpgrade(from, to)
+			if allowed {
+				Expect(err).ToNot(HaveOccurred())
+			} else {
+				Expect(err).To(HaveOccurred())
+			}
+		},
+		Entry("same version", "v5.2.2.0", "v5.2.2.0", true),
+		Entry("declared upgrade", "v5.2.2.0", "v5.2.2.1", true),
+		Entry("declared upgrade without v prefix", "5.2.2.1", "v5.2.3.0", true),
+		Entry("undeclared jump", "v5.2.2.0", "v5.2.3.0", false),
+		Entry("downgrade", "v5.2.3.0", "v5.2.2.1", false),
+		Entry("invalid version", "v5.2", "v5.2.3.0", false),
+	)
+
+	It("explains why a downgrade is rejected", func() {
+		err := ValidateStorageScaleUpgrade("v5.2.3.0", "v5.2.2.1")
+		Expect(err).To(MatchError(ContainSubstring("downgrading")))
+	})
+})
+
+var _ = Describe("GetOldestStorageScaleVersionFor", func() {
+	var origFilesSearchPaths []string
+
+	BeforeEach(func() {
+		origFilesSearchPaths = filesSearchPaths
+		filesSearchPaths = []string{"testdata/files/"}
+	})
+
+	AfterEach(func() {
+		filesSearchPaths = origFilesSearchPaths
+	})
+
+	DescribeTable("required version",
+		func(from, ocpVersion, expected string) {
+			version, err := GetOldestStorageScaleVersionFor(from, *semver.MustParse(ocpVersion))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(version).To(Equal(expected))
+		},
+		Entry("oldest newer version", "v5.2.2.0", "4.18.0", "v5.2.2.1"),
+		Entry("never returns older versions", "v5.2.3.0", "4.17.0", ""),
+		Entry("no version available yet", "v5.2.2.1", "4.30.0", ""),
+	)
+})
+
+var _ = Describe("CompareStorageScaleVersions", func() {
+	DescribeTable("ordering",
+		func(a, b string, expected int) {
+			cmp, err := CompareStorageScaleVersions(a, b)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(cmp).To(Equal(expected))
+		},
+		Entry("older", "v5.2.2.1", "v5.2.3.0", -1),
+		Entry("equal", "v5.2.3.1", "5.2.3.1", 0),
+		Entry("newer with two digits", "v5.2.10.0", "v5.2.9.1", 1),
+	)
+})
+
 var _ = Describe("Image Pull Checker", func() {
 	var (
 		cl                client.Client